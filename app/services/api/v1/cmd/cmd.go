@@ -7,20 +7,35 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 
+	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/core/post/stores/postcache"
+	"github.com/hpetrov29/resttemplate/business/core/post/stores/postmessaging"
+	"github.com/hpetrov29/resttemplate/business/core/post/stores/postnosqldb"
+	"github.com/hpetrov29/resttemplate/business/core/post/stores/postorchestrator"
+	"github.com/hpetrov29/resttemplate/business/core/post/stores/postsqldb"
 	"github.com/hpetrov29/resttemplate/business/data/cache"
 	"github.com/hpetrov29/resttemplate/business/data/cache/redis"
 	"github.com/hpetrov29/resttemplate/business/data/dbnosql"
 	"github.com/hpetrov29/resttemplate/business/data/dbnosql/mongo"
+	"github.com/hpetrov29/resttemplate/business/data/lock"
 	mysql "github.com/hpetrov29/resttemplate/business/data/dbsql/mysql"
 	"github.com/hpetrov29/resttemplate/business/data/messaging"
 	"github.com/hpetrov29/resttemplate/business/data/messaging/nats"
+	"github.com/hpetrov29/resttemplate/business/data/ratelimit"
+	"github.com/hpetrov29/resttemplate/business/data/storage"
+	"github.com/hpetrov29/resttemplate/business/data/storage/localfs"
+	"github.com/hpetrov29/resttemplate/business/data/storage/s3"
 	v1 "github.com/hpetrov29/resttemplate/business/web/v1"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/debug"
 	"github.com/hpetrov29/resttemplate/internal/idgenerator"
 	"github.com/hpetrov29/resttemplate/internal/keystore"
 	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/oidc"
+	"github.com/hpetrov29/resttemplate/internal/tracing"
 	"github.com/hpetrov29/resttemplate/internal/web"
 	"github.com/rs/cors"
 	"github.com/sethvargo/go-envconfig"
@@ -67,6 +82,25 @@ func run(ctx context.Context, log *logger.Logger, build string, routeAdder v1.Ro
 		return fmt.Errorf("error while parsing env variables/config: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Start Tracing Support
+
+	log.Info(ctx, "Tracing startup", "status", "initializing tracing support", "exporter", config.Tracing.Exporter)
+
+	traceProvider, teardown, err := tracing.Init(ctx, log, tracing.Config{
+		ServiceName: config.Tracing.ServiceName,
+		Build:       build,
+		Exporter:    config.Tracing.Exporter,
+		Host:        config.Tracing.Host,
+		Probability: config.Tracing.Probability,
+	})
+	if err != nil {
+		return fmt.Errorf("error starting tracing: %w", err)
+	}
+	defer teardown(context.Background())
+
+	tracer := traceProvider.Tracer(config.Tracing.ServiceName)
+
 	// -------------------------------------------------------------------------
 	// Set up Cache client connection
 
@@ -84,7 +118,7 @@ func run(ctx context.Context, log *logger.Logger, build string, routeAdder v1.Ro
 		log.Info(ctx, "Cache shutdown", "status", "stopping cache support", "host", config.Cache.Host)
 		redisClient.Close()
 	}()
-	err := redisClient.StatusCheck(ctx); if err != nil {
+	err = redisClient.StatusCheck(ctx); if err != nil {
 		return fmt.Errorf("error cache status check: %w", err)
 	}
 	
@@ -159,6 +193,35 @@ func run(ctx context.Context, log *logger.Logger, build string, routeAdder v1.Ro
 		return fmt.Errorf("error messaging client status check: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Set up Blob storage
+
+	log.Info(ctx, "Storage startup", "status", "initializing blob storage support", "driver", config.Storage.Driver)
+
+	var blobStore storage.BlobStore
+	switch config.Storage.Driver {
+	case storage.DriverLocal:
+		blobStore, err = localfs.New(config.Storage.Dir)
+	case storage.DriverS3:
+		blobStore, err = s3.New(storage.Config{
+			Driver:    config.Storage.Driver,
+			Endpoint:  config.Storage.Endpoint,
+			Region:    config.Storage.Region,
+			Bucket:    config.Storage.Bucket,
+			AccessKey: config.Storage.AccessKey,
+			SecretKey: config.Storage.SecretKey,
+			PathStyle: config.Storage.PathStyle,
+		})
+	default:
+		err = fmt.Errorf("unknown driver %q", config.Storage.Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("error constructing blob storage: %w", err)
+	}
+	err = blobStore.StatusCheck(ctx); if err != nil {
+		return fmt.Errorf("error blob storage status check: %w", err)
+	}
+
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
 
@@ -174,10 +237,34 @@ func run(ctx context.Context, log *logger.Logger, build string, routeAdder v1.Ro
 		DB:        mysqlClient,
 		Issuer:    config.Auth.Issuer,
 		Vault: 	   keystore,
+		PolicyDir: config.Auth.PolicyDir,
+		PolicyReloadInterval: config.Auth.PolicyReloadInterval,
 	})
 	if err != nil {
 		return fmt.Errorf("error constructing Auth service: %w", err)
 	}
+	defer auth.Close()
+
+	// -------------------------------------------------------------------------
+	// Initialize OIDC providers
+
+	var providers []*oidc.Provider
+	if config.OIDC.ProvidersFile != "" {
+		log.Info(ctx, "OIDC startup", "status", "initializing oidc providers", "file", config.OIDC.ProvidersFile)
+
+		cfgs, err := oidc.LoadConfigs(config.OIDC.ProvidersFile)
+		if err != nil {
+			return fmt.Errorf("error loading oidc providers: %w", err)
+		}
+
+		for _, cfg := range cfgs {
+			provider, err := oidc.NewProvider(cfg, nil)
+			if err != nil {
+				return fmt.Errorf("error constructing oidc provider %q: %w", cfg.Name, err)
+			}
+			providers = append(providers, provider)
+		}
+	}
 
 	// -------------------------------------------------------------------------
 	// Initialize Id Genereator
@@ -189,6 +276,75 @@ func run(ctx context.Context, log *logger.Logger, build string, routeAdder v1.Ro
 		return fmt.Errorf("error constructing Id Generator service: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Initialize Rate Limiting
+
+	var rateLimits v1.RateLimits
+	for _, l := range []struct {
+		value string
+		limit *ratelimit.Limit
+	}{
+		{config.RateLimit.Posts, &rateLimits.Posts},
+		{config.RateLimit.Comments, &rateLimits.Comments},
+		{config.RateLimit.Likes, &rateLimits.Likes},
+		{config.RateLimit.Media, &rateLimits.Media},
+	} {
+		if *l.limit, err = ratelimit.ParseLimit(l.value); err != nil {
+			return fmt.Errorf("error parsing rate limit: %w", err)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+	// Start Post Scheduler
+
+	log.Info(ctx, "Post scheduler startup", "status", "starting post scheduler", "interval", config.Posts.SchedulerInterval)
+
+	postCore := post.NewCore(
+		postorchestrator.NewStore(log,
+			postcache.NewStore(log, redisClient),
+			postsqldb.NewStore(log, mysqlClient),
+			postnosqldb.NewStore(log, mongoClient.GetRepository("posts")),
+			postnosqldb.NewRevisionStore(log, mongoClient.GetRepository("post_revisions")),
+		),
+		postmessaging.NewStore(log, natsClient, postmessaging.SubjectPublished),
+		log,
+		snowflakeGen,
+	)
+	scheduler := post.NewScheduler(postCore, lock.NewRedis(redisClient), log, config.Posts.SchedulerInterval)
+
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		scheduler.Run(schedulerCtx)
+	}()
+	defer func() {
+		log.Info(ctx, "Post scheduler shutdown", "status", "stopping post scheduler")
+		stopScheduler()
+		<-schedulerDone
+	}()
+
+	// -------------------------------------------------------------------------
+	// Start Debug Service
+
+	log.Info(ctx, "Debug startup", "status", "debug router started", "host", config.Web.DebugHost)
+
+	debugMux := debug.Mux(debug.Config{
+		Build:     build,
+		Log:       log,
+		Cache:     redisClient,
+		SQLDB:     mysqlClient,
+		NOSQLDB:   mongoClient,
+		Messaging: natsClient,
+		Storage:   blobStore,
+	})
+
+	go func() {
+		if err := http.ListenAndServe(config.Web.DebugHost, debugMux); err != nil {
+			log.Error(ctx, "Debug shutdown", "status", "debug router closed", "host", config.Web.DebugHost, "msg", err)
+		}
+	}()
+
 	// -------------------------------------------------------------------------
 	// Start API
 
@@ -208,6 +364,15 @@ func run(ctx context.Context, log *logger.Logger, build string, routeAdder v1.Ro
 		NOSQLDB: mongoClient,
 		Messaging: natsClient,
 		IdGen: snowflakeGen,
+		OIDC: providers,
+		Tracer: tracer,
+		RequestTimeout: config.Web.RequestTimeout,
+		RateLimiter: ratelimit.New(log, redisClient),
+		RateLimits: rateLimits,
+		IdempotencyWindow: config.Web.IdempotencyWindow,
+		Storage: blobStore,
+		MediaBaseURL: strings.TrimSuffix(config.Media.BaseURL, "/"),
+		MediaMaxSize: config.Media.MaxSize,
 	}
 
 	apiMux := v1.NewAPIMux(muxConfig, routeAdder)
@@ -216,7 +381,8 @@ func run(ctx context.Context, log *logger.Logger, build string, routeAdder v1.Ro
         AllowedOrigins:   config.CORS.AllowedOrigins,
         AllowCredentials: true,
         AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowedHeaders:   []string{"Authorization", "Content-Type", "Set-Cookie"},
+        AllowedHeaders:   []string{"Authorization", "Content-Type", "Set-Cookie", "traceparent", "tracestate", "Idempotency-Key", "If-None-Match", "If-Modified-Since"},
+        ExposedHeaders:   []string{"traceparent", "tracestate", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", "Idempotent-Replayed", "ETag"},
     })
 
 	api := &http.Server{
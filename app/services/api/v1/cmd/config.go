@@ -13,7 +13,9 @@ type GlobalConfig struct {
 		WriteTimeout    time.Duration `env:"WRITE_TIMEOUT, default=10s"`
 		IdleTimeout     time.Duration `env:"IDLE_TIMEOUT, default=120s"`
 		ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT, default=20s"`
-		//DebugHost       string          `conf:"default:0.0.0.0:4000"`
+		RequestTimeout  time.Duration `env:"REQUEST_TIMEOUT, default=8s"`
+		IdempotencyWindow time.Duration `env:"IDEMPOTENCY_WINDOW, default=24h"`
+		DebugHost       string        `env:"DEBUG_HOST, default=0.0.0.0:4000"`
 	}
 	Cache struct {
 		Password 	string		`env:"CACHE_PASSWORD, required"`
@@ -44,6 +46,40 @@ type GlobalConfig struct {
 	Auth struct {
 		KeysFolder string `env:"KEY_PATH, default=./zarf/keys/"`
 		Issuer     string `env:"ISSUER_NAME, default=service"`
+		PolicyDir  string `env:"AUTH_POLICY_DIR"`
+		PolicyReloadInterval time.Duration `env:"AUTH_POLICY_RELOAD_INTERVAL, default=30s"`
+	}
+	OIDC struct {
+		ProvidersFile string `env:"OIDC_PROVIDERS_FILE"`
+	}
+	Tracing struct {
+		ServiceName string  `env:"TRACING_SERVICE_NAME, default=resttemplate-api"`
+		Exporter    string  `env:"TRACING_EXPORTER, default=none"`
+		Host        string  `env:"TRACING_HOST, default=localhost:4317"`
+		Probability float64 `env:"TRACING_PROBABILITY, default=0.05"`
+	}
+	RateLimit struct {
+		Posts    string `env:"RATE_LIMIT_POSTS, default=30/1m"`
+		Comments string `env:"RATE_LIMIT_COMMENTS, default=60/1m"`
+		Likes    string `env:"RATE_LIMIT_LIKES, default=120/1m"`
+		Media    string `env:"RATE_LIMIT_MEDIA, default=20/1m"`
+	}
+	Storage struct {
+		Driver    string `env:"STORAGE_DRIVER, default=local"`
+		Dir       string `env:"STORAGE_DIR, default=./zarf/media"`
+		Endpoint  string `env:"STORAGE_S3_ENDPOINT"`
+		Region    string `env:"STORAGE_S3_REGION, default=us-east-1"`
+		Bucket    string `env:"STORAGE_S3_BUCKET"`
+		AccessKey string `env:"STORAGE_S3_ACCESS_KEY"`
+		SecretKey string `env:"STORAGE_S3_SECRET_KEY"`
+		PathStyle bool   `env:"STORAGE_S3_PATH_STYLE, default=false"`
+	}
+	Posts struct {
+		SchedulerInterval time.Duration `env:"POST_SCHEDULER_INTERVAL, default=30s"`
+	}
+	Media struct {
+		BaseURL string `env:"MEDIA_BASE_URL, default=http://localhost:3000/v1/media"`
+		MaxSize int64  `env:"MEDIA_MAX_SIZE, default=10485760"`
 	}
 	CORS struct {
 		AllowedOrigins []string `env:"ALLOWED_ORIGINS, delimiter=;, required"`
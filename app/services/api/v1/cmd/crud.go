@@ -3,9 +3,12 @@ package cmd
 import (
 	"github.com/hpetrov29/resttemplate/app/services/api/v1/handlers/comments"
 	"github.com/hpetrov29/resttemplate/app/services/api/v1/handlers/likes"
+	"github.com/hpetrov29/resttemplate/app/services/api/v1/handlers/media"
 	"github.com/hpetrov29/resttemplate/app/services/api/v1/handlers/posts"
+	"github.com/hpetrov29/resttemplate/app/services/api/v1/handlers/roles"
 	"github.com/hpetrov29/resttemplate/app/services/api/v1/handlers/users"
 	v1 "github.com/hpetrov29/resttemplate/business/web/v1"
+	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
 	"github.com/hpetrov29/resttemplate/internal/web"
 )
 
@@ -24,6 +27,13 @@ func (add) Add(app *web.App, cfg v1.APIMuxConfig) {
 		Auth:  		cfg.Auth,
 		DB:    		cfg.SQLDB,
 		IdGen: 		cfg.IdGen,
+		Cache: 		cfg.Cache,
+		OIDC: 		cfg.OIDC,
+	})
+	roles.Routes(app, roles.Config{
+		Log:   		cfg.Log,
+		Auth:  		cfg.Auth,
+		DB:    		cfg.SQLDB,
 	})
 	posts.Routes(app, posts.Config{
 		Log:   		cfg.Log,
@@ -31,17 +41,32 @@ func (add) Add(app *web.App, cfg v1.APIMuxConfig) {
 		Cache: 		cfg.Cache,
 		SQLDB:    	cfg.SQLDB,
 		NOSQLDB: 	cfg.NOSQLDB,
+		Messaging: 	cfg.Messaging,
 		IdGen: 		cfg.IdGen,
+		RateLimit: 	middleware.RateLimit(cfg.RateLimiter, "posts", cfg.RateLimits.Posts),
+		Idempotency: middleware.Idempotency(cfg.Cache, cfg.IdempotencyWindow),
+		MediaBaseURL: cfg.MediaBaseURL,
+	})
+	media.Routes(app, media.Config{
+		Log:   		cfg.Log,
+		Auth:  		cfg.Auth,
+		Store: 		cfg.Storage,
+		RateLimit: 	middleware.RateLimit(cfg.RateLimiter, "media", cfg.RateLimits.Media),
+		BaseURL: 	cfg.MediaBaseURL,
+		MaxSize: 	cfg.MediaMaxSize,
 	})
 	likes.Routes(app, likes.Config{
 		Log:   		cfg.Log,
 		Auth:  		cfg.Auth,
 		Messaging: 	cfg.Messaging,
+		RateLimit: 	middleware.RateLimit(cfg.RateLimiter, "likes", cfg.RateLimits.Likes),
 	})
 	comments.Routes(app, comments.Config{
 		Log:   		cfg.Log,
 		Auth:  		cfg.Auth,
 		SQLDB:    	cfg.SQLDB,
 		IdGen: 		cfg.IdGen,
+		RateLimit: 	middleware.RateLimit(cfg.RateLimiter, "comments", cfg.RateLimits.Comments),
+		Idempotency: middleware.Idempotency(cfg.Cache, cfg.IdempotencyWindow),
 	})
 }
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hpetrov29/resttemplate/business/data/dbnosql"
+	v1 "github.com/hpetrov29/resttemplate/business/web/v1"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// stubNOSQLDB hands out no repositories. Building the routes only keeps
+// the stores, none of them is called.
+type stubNOSQLDB struct {
+	dbnosql.NOSQLDB
+}
+
+func (stubNOSQLDB) GetRepository(string) dbnosql.NOSQLDBrepo { return nil }
+
+// pathParam matches the parameters of a chi route pattern, the document
+// drops their regular expressions.
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// TestRoutesDocumented fails for every registered route missing from the
+// generated OpenAPI document, the clients would not know about it.
+func TestRoutesDocumented(t *testing.T) {
+	log := logger.NewWithEvents(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" }, logger.Events{})
+
+	a, err := auth.New(auth.Config{Log: log})
+	if err != nil {
+		t.Fatalf("auth: %s", err)
+	}
+	defer a.Close()
+
+	mux := v1.NewAPIMux(v1.APIMuxConfig{
+		Build:    "test",
+		Shutdown: make(chan os.Signal, 1),
+		Log:      log,
+		Auth:     a,
+		NOSQLDB:  stubNOSQLDB{},
+	}, Routes())
+
+	app, ok := mux.(*web.App)
+	if !ok {
+		t.Fatalf("mux: got %T, want *web.App", mux)
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("openapi: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+
+	routes := app.Routes()
+	if len(routes) == 0 {
+		t.Fatal("routes: none registered")
+	}
+
+	for _, r := range routes {
+		path := pathParam.ReplaceAllString(r.Path, "{$1}")
+		if _, ok := doc.Paths[path][strings.ToLower(r.Method)]; !ok {
+			t.Errorf("%s %s: missing from the OpenAPI document", r.Method, r.Path)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/hpetrov29/resttemplate/business/core/comment"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
 	"github.com/hpetrov29/resttemplate/internal/web"
 )
 
@@ -29,16 +31,16 @@ func (h *Handlers) CreateComment(ctx context.Context, w http.ResponseWriter, r *
 
 	userId, err := strconv.ParseUint(auth.GetClaims(ctx).Subject, 10, 64) // Base 10, 64-bit unsigned integer
 	if err != nil {
-		return web.Respond(ctx, w, http.StatusUnauthorized, fmt.Errorf("authentication failed: %w", err))
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
 	}
 
 	if err := web.Decode(r, &newComment); err != nil {
-		return web.Respond(ctx, w, http.StatusBadRequest, fmt.Errorf("error parsing the body of the request: %w", err))
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
 	}
 
 	postId, err := strconv.ParseUint(web.Param(r, "post_id"), 10, 64) // Base 10, 64-bit unsigned integer
 	if err != nil {
-		return web.Respond(ctx, w, http.StatusBadRequest, fmt.Errorf("error parsing post_id param: %w", err))
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidId("post_id", err))
 	}
 
 	coreNewComment := toCoreNewComment(newComment, int64(userId), int64(postId))
@@ -51,36 +53,26 @@ func (h *Handlers) CreateComment(ctx context.Context, w http.ResponseWriter, r *
 	return web.Respond(ctx, w, http.StatusOK, toAppComment(coreComement))
 }
 
+// DeleteComment removes the comment loaded by the AuthorizeComment middleware.
 func (h *Handlers) DeleteComment(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	var deleteComment DeleteComment
-
-	userId, err := strconv.ParseUint(auth.GetClaims(ctx).Subject, 10, 64) // Base 10, 64-bit unsigned integer
-	if err != nil {
-		return web.Respond(ctx, w, http.StatusUnauthorized, fmt.Errorf("authentication failed: %w", err))
-	}
-
-	if err := web.Decode(r, &deleteComment); err != nil {
-		return web.Respond(ctx, w, http.StatusBadRequest, fmt.Errorf("error parsing the body of the request: %w", err))
-	}
-
-	// prevents malicous comment deletion (a comment to be deleted by other than its author)
-	if (userId != deleteComment.UserId) {
-		return web.Respond(ctx, w, http.StatusUnauthorized, errors.New("unauthorized action"))
+	coreComment, ok := middleware.GetComment(ctx)
+	if !ok {
+		return web.Respond(ctx, w, http.StatusInternalServerError, errors.New("comment missing from context"))
 	}
 
-	if err = h.comment.Delete(ctx, deleteComment.Id); err != nil{
+	if err := h.comment.Delete(ctx, uint64(coreComment.Id)); err != nil {
 		if errors.Is(err, comment.ErrNotFound) {
-			return web.Respond(ctx, w, http.StatusBadRequest, err)
+			return web.Respond(ctx, w, http.StatusNotFound, comment.ErrNotFound)
 		}
 		return web.Respond(ctx, w, http.StatusInternalServerError, err)
 	}
 
-	return web.Respond(ctx, w, http.StatusOK, fmt.Sprintf("Deletion of comment with id: %d successful.", deleteComment.Id))
+	return web.Respond(ctx, w, http.StatusOK, fmt.Sprintf("Deletion of comment with id: %d successful.", coreComment.Id))
 }
 
 func (h *Handlers) GetComments(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	id, err := strconv.ParseInt(web.Param(r, "post_id"), 10, 64); if err != nil {
-		return web.Respond(ctx, w, http.StatusBadRequest, err)
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidId("post_id", err))
 	}
 
 	comments, err := h.comment.QueryByPostId(ctx, id)
@@ -53,10 +53,3 @@ func toAppComments(comments []comment.Comment) []AppComment {
 	}
 	return items
 }
-
-// DeleteComment represents the contents that need to be decoded from the request
-// body in the app layer for comment deletion.
-type DeleteComment struct {
-	Id     	   	uint64 		`json:"id"`
-	UserId 		uint64 		`json:"userId"`
-}
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hpetrov29/resttemplate/business/core/comment"
 	"github.com/hpetrov29/resttemplate/business/core/comment/stores/commentsqldb"
+	"github.com/hpetrov29/resttemplate/business/core/role"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
 	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
 	"github.com/hpetrov29/resttemplate/internal/idgenerator"
@@ -19,6 +20,8 @@ type Config struct {
 	Auth 		*auth.Auth
 	SQLDB   	*sqlx.DB
 	IdGen 		*idgenerator.IdGenerator
+	RateLimit 	web.Middleware
+	Idempotency web.Middleware
 }
 
 // Routes initializes the required comment specific repositories, services and handlers,
@@ -35,11 +38,18 @@ func Routes(app *web.App, cfg Config) {
 	handlers := New(userService, cfg.Auth)
 
 	authenticated := middleware.Authenticate(cfg.Auth)
+	canCreate := middleware.RequirePermission(cfg.Auth, role.PermCommentCreate)
+	canDelete := middleware.AuthorizeComment(cfg.Auth, userService, auth.RuleCommentDelete)
+
+	tags := []string{"comments"}
 
 	//UNPROTECTED ROUTES
-	app.Handle(http.MethodGet, "/comments/{post_id}", handlers.GetComments)
+	app.Handle(http.MethodGet, "/comments/{post_id}", handlers.GetComments).
+		Describe(web.Doc{Summary: "List the comments of a post", Tags: tags, Response: []AppComment{}})
 
 	// PROTECTED ROUTES
-	app.Handle(http.MethodPost, "/comment/{post_id}", handlers.CreateComment, authenticated)
-	app.Handle(http.MethodDelete, "/comment", handlers.DeleteComment, authenticated)
+	app.Handle(http.MethodPost, "/comment/{post_id}", handlers.CreateComment, authenticated, cfg.RateLimit, canCreate, cfg.Idempotency).
+		Describe(web.Doc{Summary: "Comment on a post", Tags: tags, Security: web.SecurityBearer, Request: NewAppComment{}, Response: AppComment{}})
+	app.Handle(http.MethodDelete, "/comment/{id}", handlers.DeleteComment, authenticated, canDelete).
+		Describe(web.Doc{Summary: "Delete a comment", Tags: tags, Security: web.SecurityBearer, Response: ""})
 }
@@ -9,6 +9,8 @@ import (
 
 	"github.com/hpetrov29/resttemplate/business/core/like"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/validate"
 	"github.com/hpetrov29/resttemplate/internal/web"
 )
 
@@ -30,7 +32,7 @@ func New(lc *like.Core, auth *auth.Auth) *Handlers {
 func (h *Handlers) Like(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	claims := auth.GetClaims(ctx)
 	if claims.Subject == "" {
-		return web.Respond(ctx, w, http.StatusUnauthorized, errors.New("authentication failed"))
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(errors.New("claims have no subject")))
 	}
 
 	postIdStr := web.Param(r, "post_id")
@@ -38,17 +40,17 @@ func (h *Handlers) Like(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	
 	userId, err := strconv.ParseUint(claims.Subject, 10, 64) // Base 10, 64-bit unsigned integer
 	if err != nil {
-		return web.Respond(ctx, w, http.StatusUnauthorized, fmt.Errorf("authentication failed: %w", err))
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
 	}
 
 	likeValue, err := strconv.ParseInt(likeValueStr, 10, 8)
 	if err!= nil || likeValue < -1 || likeValue > 1 {
-		return web.Respond(ctx, w, http.StatusBadRequest, fmt.Errorf("interaction type not recognized: %w", err))
+		return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError("is_like", errors.New("is_like must be -1, 0 or 1")))
 	}
 
 	postId, err := strconv.ParseUint(postIdStr, 10, 64)
 	if err != nil {
-		return web.Respond(ctx, w, http.StatusBadRequest, fmt.Errorf("action cannot be associated to a post: %w", err))
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidId("post_id", err))
 	}
 
 	newLike := like.NewLike{
@@ -5,6 +5,7 @@ import (
 
 	"github.com/hpetrov29/resttemplate/business/core/like"
 	"github.com/hpetrov29/resttemplate/business/core/like/stores/likemessaging"
+	"github.com/hpetrov29/resttemplate/business/core/role"
 	"github.com/hpetrov29/resttemplate/business/data/messaging"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
 	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
@@ -17,6 +18,7 @@ type Config struct {
 	Log   *logger.Logger
 	Auth  *auth.Auth
 	Messaging messaging.MessagingQueue
+	RateLimit web.Middleware
 }
 
 // Routes initializes the required user specific repositories, service and handler,
@@ -34,7 +36,9 @@ func Routes(app *web.App, cfg Config) {
 	handlers := New(likesCore, cfg.Auth)
 
 	authenticated := middleware.Authenticate(cfg.Auth)
+	canLike := middleware.RequirePermission(cfg.Auth, role.PermLikeCreate)
 
 	// PROTECTED ROUTES
-	app.Handle(http.MethodPost, "/like/{post_id}/{is_like}", handlers.Like, authenticated)
+	app.Handle(http.MethodPost, "/like/{post_id}/{is_like}", handlers.Like, authenticated, cfg.RateLimit, canLike).
+		Describe(web.Doc{Summary: "Like or dislike a post", Tags: []string{"likes"}, Security: web.SecurityBearer, Response: AppLike{}})
 }
\ No newline at end of file
@@ -0,0 +1,142 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/media"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// fileField is the name of the multipart field holding the uploaded file.
+const fileField = "file"
+
+// Handlers manages the set of media endpoints.
+type Handlers struct {
+	media   *media.Core
+	baseURL string
+	maxSize int64
+}
+
+// New constructs a new handlers struct for route access.
+func New(mc *media.Core, baseURL string, maxSize int64) *Handlers {
+	return &Handlers{
+		media:   mc,
+		baseURL: baseURL,
+		maxSize: maxSize,
+	}
+}
+
+// Upload stores the image sent in the file field of a multipart/form-data
+// request. The file is streamed from the request and refused as soon as it
+// grows past the size limit, other fields are skipped.
+func (h *Handlers) Upload(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, invalidMultipart(err))
+	}
+
+	var data []byte
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return web.Respond(ctx, w, http.StatusBadRequest, invalidMultipart(err))
+		}
+
+		if part.FormName() != fileField {
+			part.Close()
+			continue
+		}
+
+		data, err = io.ReadAll(io.LimitReader(part, h.maxSize+1))
+		part.Close()
+		if err != nil {
+			return web.Respond(ctx, w, http.StatusBadRequest, invalidMultipart(err))
+		}
+		if int64(len(data)) > h.maxSize {
+			return web.Respond(ctx, w, http.StatusRequestEntityTooLarge, &web.Error{
+				Code:    response.CodeFileTooLarge,
+				Message: "file exceeds the limit of " + strconv.FormatInt(h.maxSize, 10) + " bytes",
+			})
+		}
+		break
+	}
+
+	if len(data) == 0 {
+		return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError(fileField, errors.New("file is required")))
+	}
+
+	m, err := h.media.Upload(ctx, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrUnsupportedType):
+			return web.Respond(ctx, w, http.StatusUnsupportedMediaType, &web.Error{
+				Code:    response.CodeUnsupportedMediaType,
+				Message: "only JPEG, PNG, GIF and WebP images are accepted",
+				Err:     err,
+			})
+		case errors.Is(err, media.ErrInvalidImage):
+			return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError(fileField, err))
+		}
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusCreated, toAppMedia(m, h.baseURL))
+}
+
+// invalidMultipart returns the error of a body that could not be read as a
+// multipart/form-data document.
+func invalidMultipart(err error) error {
+	return &web.Error{Code: response.CodeInvalidBody, Message: "request body is not a valid multipart/form-data document", Err: err}
+}
+
+// QueryFile serves a stored file. Files are content addressed and never
+// change, successful responses are cached for a year. The policy is set
+// here rather than by the route, errors must not be cached that long.
+func (h *Handlers) QueryFile(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	id := web.Param(r, "id")
+	file := web.Param(r, "file")
+
+	// The key identifies the content, a client holding the file is answered
+	// without reading it from the store.
+	etag := web.ETag(id, file)
+	if web.NotModified(w, r, etag, time.Time{}) {
+		web.SetCacheControl(w, web.CacheImmutable)
+		return web.Respond(ctx, w, http.StatusNotModified, nil)
+	}
+
+	obj, err := h.media.Open(ctx, id, file)
+	if err != nil {
+		if errors.Is(err, media.ErrNotFound) {
+			return web.Respond(ctx, w, http.StatusNotFound, media.ErrNotFound)
+		}
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+	defer obj.Body.Close()
+
+	web.SetCacheControl(w, web.CacheImmutable)
+	w.Header().Set("Content-Type", obj.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if obj.Size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+	}
+
+	web.SetStatusCode(ctx, http.StatusOK)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, obj.Body); err != nil {
+		return fmt.Errorf("streaming file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package media
+
+import (
+	"github.com/hpetrov29/resttemplate/business/core/media"
+)
+
+// AppMedia represents an uploaded image in the app layer. URL is the value
+// posts reference as their frontImage.
+type AppMedia struct {
+	Id          string         `json:"id"`
+	ContentType string         `json:"contentType"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	Size        int            `json:"size"`
+	URL         string         `json:"url"`
+	Thumbnails  []AppThumbnail `json:"thumbnails"`
+}
+
+// AppThumbnail represents a scaled down copy of an uploaded image.
+type AppThumbnail struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Size   int    `json:"size"`
+	URL    string `json:"url"`
+}
+
+// toAppMedia converts media.Media (core layer) to AppMedia (app layer), the
+// storage keys becoming URLs under baseURL.
+func toAppMedia(m media.Media, baseURL string) AppMedia {
+	thumbnails := make([]AppThumbnail, len(m.Thumbnails))
+	for i, t := range m.Thumbnails {
+		thumbnails[i] = AppThumbnail{
+			Width:  t.Width,
+			Height: t.Height,
+			Size:   t.Size,
+			URL:    baseURL + "/" + t.Key,
+		}
+	}
+
+	return AppMedia{
+		Id:          m.Id,
+		ContentType: m.ContentType,
+		Width:       m.Width,
+		Height:      m.Height,
+		Size:        m.Size,
+		URL:         baseURL + "/" + m.Key,
+		Thumbnails:  thumbnails,
+	}
+}
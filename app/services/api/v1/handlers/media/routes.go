@@ -0,0 +1,55 @@
+package media
+
+import (
+	"net/http"
+
+	"github.com/hpetrov29/resttemplate/business/core/media"
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/data/storage"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log       *logger.Logger
+	Auth      *auth.Auth
+	Store     storage.BlobStore
+	RateLimit web.Middleware
+
+	// BaseURL is the URL the stored files are served under, the files of
+	// the GET route below unless a CDN or the bucket serves them directly.
+	BaseURL string
+
+	// MaxSize is the largest file accepted, in bytes.
+	MaxSize int64
+}
+
+// Routes initializes the media core and handlers, and sets up the API routes
+// for the application with their respective handlers and middlewares.
+//
+// Parameters:
+// 	- app: the web.App instance used to register the routes.
+// 	- cfg: configuration including pointers to the logging, storage, and authentication systems.
+func Routes(app *web.App, cfg Config) {
+	mediaCore := media.NewCore(cfg.Store, cfg.Log)
+
+	handlers := New(mediaCore, cfg.BaseURL, cfg.MaxSize)
+
+	// Images are uploaded for posts, the same keys and users creating posts
+	// may upload them.
+	authenticated := middleware.AuthenticateKeyOrToken(cfg.Auth)
+	canUpload := middleware.RequirePermission(cfg.Auth, role.PermPostCreate)
+
+	tags := []string{"media"}
+
+	// UNPROTECTED ROUTES
+	app.Handle(http.MethodGet, "/media/{id}/{file}", handlers.QueryFile).
+		Describe(web.Doc{Summary: "Get an uploaded file", Tags: tags})
+
+	// PROTECTED ROUTES
+	app.Handle(http.MethodPost, "/media", handlers.Upload, authenticated, cfg.RateLimit, canUpload).
+		Describe(web.Doc{Summary: "Upload an image", Tags: tags, Security: web.SecurityBearer, Upload: fileField, Response: AppMedia{}, Status: http.StatusCreated})
+}
@@ -1,51 +1,182 @@
 package posts
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hpetrov29/resttemplate/business/core/post"
 	"github.com/hpetrov29/resttemplate/internal/validate"
 )
 
-func parseFilter(r *http.Request) (post.QueryFilter, error) {
-	const (
-		filterByUserId   	= "user_id"
-		filterByCreatedAt = "created_at"
-		filterByUpdatedAt = "updated_at"
-	)
+// Set of filter parameters of the post listings.
+const (
+	filterByUserId         = "user_id"
+	filterByExcludeUserId  = "exclude_user_id"
+	filterByExcludeId      = "exclude_id"
+	filterByTitle          = "title"
+	filterByDescription    = "description"
+	filterByStartCreatedAt = "start_created_at"
+	filterByEndCreatedAt   = "end_created_at"
+	filterByStartUpdatedAt = "start_updated_at"
+	filterByEndUpdatedAt   = "end_updated_at"
+	filterByMinReadingTime = "min_reading_time"
+	filterByMaxReadingTime = "max_reading_time"
+
+	// created_at and updated_at predate the ranges, they set their start.
+	filterByCreatedAt = "created_at"
+	filterByUpdatedAt = "updated_at"
+)
 
+// filterParams lists the filter parameters for the documentation of the
+// routes.
+var filterParams = []string{
+	filterByUserId, filterByExcludeUserId, filterByExcludeId, filterByTitle, filterByDescription,
+	filterByStartCreatedAt, filterByEndCreatedAt, filterByStartUpdatedAt, filterByEndUpdatedAt,
+	filterByMinReadingTime, filterByMaxReadingTime,
+}
+
+func parseFilter(r *http.Request) (post.QueryFilter, error) {
 	values := r.URL.Query()
 
 	var filter post.QueryFilter
 
-	if userId := values.Get(filterByUserId); userId != "" {
-		uid, err := strconv.ParseUint(userId, 10, 64)
-		if err != nil {
-			return post.QueryFilter{}, validate.NewFieldsError(filterByUserId, err)
+	if userIds, err := parseIds(values, filterByUserId); err != nil {
+		return post.QueryFilter{}, err
+	} else if len(userIds) > 0 {
+		filter.WithUserIds(userIds)
+	}
+
+	if userIds, err := parseIds(values, filterByExcludeUserId); err != nil {
+		return post.QueryFilter{}, err
+	} else if len(userIds) > 0 {
+		filter.WithExcludeUserIds(userIds)
+	}
+
+	if ids, err := parseIds(values, filterByExcludeId); err != nil {
+		return post.QueryFilter{}, err
+	} else if len(ids) > 0 {
+		postIds := make([]int64, len(ids))
+		for i, id := range ids {
+			postIds[i] = int64(id)
+		}
+		filter.WithExcludeIds(postIds)
+	}
+
+	if title := strings.TrimSpace(values.Get(filterByTitle)); title != "" {
+		filter.WithTitle(title)
+	}
+
+	if description := strings.TrimSpace(values.Get(filterByDescription)); description != "" {
+		filter.WithDescription(description)
+	}
+
+	for _, f := range []struct {
+		names []string
+		end   bool
+		set   func(time.Time)
+	}{
+		{[]string{filterByStartCreatedAt, filterByCreatedAt}, false, filter.WithStartCreatedAt},
+		{[]string{filterByEndCreatedAt}, true, filter.WithEndCreatedAt},
+		{[]string{filterByStartUpdatedAt, filterByUpdatedAt}, false, filter.WithStartUpdatedAt},
+		{[]string{filterByEndUpdatedAt}, true, filter.WithEndUpdatedAt},
+	} {
+		for _, name := range f.names {
+			v := values.Get(name)
+			if v == "" {
+				continue
+			}
+
+			t, err := parseTime(v, f.end)
+			if err != nil {
+				return post.QueryFilter{}, validate.NewFieldsError(name, err)
+			}
+			f.set(t)
+			break
 		}
-		filter.WithUserId(uid)
 	}
 
-	if createdAt := values.Get(filterByCreatedAt); createdAt != "" {
-		dc, err := time.Parse("2006-01-02T15:04:05Z", createdAt)
+	if minReadingTime := values.Get(filterByMinReadingTime); minReadingTime != "" {
+		minutes, err := strconv.Atoi(minReadingTime)
 		if err != nil {
-			return post.QueryFilter{}, validate.NewFieldsError(filterByCreatedAt, err)
+			return post.QueryFilter{}, validate.NewFieldsError(filterByMinReadingTime, err)
 		}
-		filter.WithCreatedAt(dc)
+		filter.WithMinReadingTime(minutes)
 	}
 
-	if updatedAt := values.Get(filterByUpdatedAt); updatedAt != "" {
-		du, err := time.Parse("2006-01-02T15:04:05Z", updatedAt)
+	if maxReadingTime := values.Get(filterByMaxReadingTime); maxReadingTime != "" {
+		minutes, err := strconv.Atoi(maxReadingTime)
 		if err != nil {
-			return post.QueryFilter{}, validate.NewFieldsError(filterByUpdatedAt, err)
+			return post.QueryFilter{}, validate.NewFieldsError(filterByMaxReadingTime, err)
 		}
-		filter.WithUpdatedAt(du)
+		filter.WithMaxReadingTime(minutes)
 	}
+
 	if err := filter.Validate(); err != nil {
 		return post.QueryFilter{}, err
 	}
 
 	return filter, nil
-}
\ No newline at end of file
+}
+
+// parseIds parses the ids of a list parameter, given either as repeated
+// parameters or as a comma separated list, e.g. user_id=1&user_id=2 or
+// user_id=1,2.
+func parseIds(values url.Values, name string) ([]uint64, error) {
+	var ids []uint64
+	for _, v := range values[name] {
+		for _, s := range strings.Split(v, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+
+			id, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return nil, validate.NewFieldsError(name, err)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// timeLayouts are the layouts of the timestamps accepted by the filters,
+// tried in order. Timestamps without a time zone are in UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	time.DateOnly,
+}
+
+// parseTime parses the timestamp of a filter: RFC 3339 with or without
+// fractional seconds and time zone, a space instead of the T, a date alone
+// or seconds since the Unix epoch. A date alone ending a range stands for
+// the end of that day, the range includes it.
+func parseTime(v string, end bool) (time.Time, error) {
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+
+	for _, layout := range timeLayouts {
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			continue
+		}
+
+		if layout == time.DateOnly && end {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+
+		return t, nil
+	}
+
+	return time.Time{}, errors.New("must be a RFC 3339 timestamp, a date or a Unix time")
+}
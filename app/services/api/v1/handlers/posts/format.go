@@ -0,0 +1,89 @@
+package posts
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hpetrov29/resttemplate/business/core/post/render"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+)
+
+// formatJSON selects the default JSON representation of a post.
+const formatJSON = "json"
+
+// mediaFormats maps the media types a post can be requested in to the format
+// its content is rendered to. An empty format is the JSON representation.
+var mediaFormats = map[string]render.Format{
+	"application/json": "",
+	"text/html":        render.FormatHTML,
+	"text/markdown":    render.FormatMarkdown,
+	"text/plain":       render.FormatText,
+}
+
+// parseFormat returns the format the content of a post is sent in, or an
+// empty format for the JSON representation. The format query parameter takes
+// precedence over the Accept header.
+func parseFormat(r *http.Request) (render.Format, error) {
+	const formatParam = "format"
+
+	if name := r.URL.Query().Get(formatParam); name != "" {
+		if name == formatJSON {
+			return "", nil
+		}
+
+		format, err := render.ParseFormat(name)
+		if err != nil {
+			return "", validate.NewFieldsError(formatParam, errors.New("must be one of json, html, markdown, text"))
+		}
+		return format, nil
+	}
+
+	return acceptedFormat(r.Header.Get("Accept")), nil
+}
+
+// acceptedFormat picks the preferred format of an Accept header. Media types
+// are ranked by their quality value, ties keep the order of the header, and
+// wildcards or unknown types fall back to JSON.
+func acceptedFormat(accept string) render.Format {
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, mr := range ranges {
+		if format, ok := mediaFormats[mr.mediaType]; ok {
+			return format
+		}
+		if mr.mediaType == "*/*" {
+			return ""
+		}
+	}
+
+	return ""
+}
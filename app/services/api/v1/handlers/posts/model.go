@@ -12,26 +12,67 @@ type AppPost struct {
 	Id           	int64   		`json:"id"`
 	UserId       	int64	 		`json:"userId"`
 	Title        	string   		`json:"title"`
+	Slug        	string   		`json:"slug,omitempty"`
 	Description 	string 			`json:"description"`
 	FrontImage  	string 			`json:"frontImage"`
 	ContentId   	int64   		`json:"contentId"`
 	Content   		*AppContent   	`json:"content,omitempty"`
+	WordCount   	int      		`json:"wordCount"`
+	ReadingTime 	int      		`json:"readingTime"`
+	ImageCount  	int      		`json:"imageCount"`
+	Language    	string   		`json:"language,omitempty"`
+	Status      	string   		`json:"status"`
+	PublishAt   	string   		`json:"publishAt,omitempty"`
 	CreatedAt   	string   		`json:"createdAt"`
 	UpdatedAt  		string   		`json:"updatedAt"`
 }
 
 func toAppPost(post post.Post) AppPost {
-	return AppPost{
+	app := AppPost{
 	Id:  			post.Id,
 	UserId: 		post.UserId,
 	Title: 			post.Title,
+	Slug: 			post.Slug,
 	Description:	post.Description,
 	FrontImage:		post.FrontImage,
 	ContentId: 		post.ContentId,
 	Content: 		toAppContent(post.Content),
+	WordCount: 		post.Stats.WordCount,
+	ReadingTime: 	post.Stats.ReadingTime,
+	ImageCount: 	post.Stats.ImageCount,
+	Language: 		post.Stats.Language,
+	Status: 		post.Status,
 	CreatedAt: 		post.CreatedAt.Format(time.RFC3339),
 	UpdatedAt:  	post.UpdatedAt.Format(time.RFC3339),
 	}
+
+	if !post.PublishAt.IsZero() {
+		app.PublishAt = post.PublishAt.Format(time.RFC3339)
+	}
+
+	return app
+}
+
+// AppBatchQuery contains the ids of the posts fetched in a batch, at most
+// 100 of them.
+type AppBatchQuery struct {
+	Ids []int64 `json:"ids" validate:"required,min=1,max=100,dive,gt=0"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppBatchQuery) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AppBatchPosts contains the posts of a batch in the order their ids were
+// given, followed by the ids without a post.
+type AppBatchPosts struct {
+	Posts   []AppPost `json:"posts"`
+	Missing []int64   `json:"missing"`
 }
 
 // Converts a slice of post.Post (core layer) to a slice of AppPost (app layer)
@@ -50,18 +91,44 @@ func toAppPosts(posts []post.Post) []AppPost {
 type AppNewPost struct {
 	Title            string   	  	`json:"title" validate:"required"`
 	Description      string   	  	`json:"description" validate:"required"`
+	FrontImage       string         `json:"frontImage" validate:"omitempty,url,max=512"`
 	Content 		 AppContent 	`json:"content" validate:"required"`
+	Status           string         `json:"status" validate:"omitempty,oneof=draft scheduled published"`
+	PublishAt        string         `json:"publishAt" validate:"omitempty"`
 }
 
-func toCoreNewPost(app AppNewPost, userId int64) post.NewPost {
+func toCoreNewPost(app AppNewPost, userId int64) (post.NewPost, error) {
+	publishAt, err := parsePublishAt(app.PublishAt)
+	if err != nil {
+		return post.NewPost{}, err
+	}
+
 	post := post.NewPost{
 		UserId: userId,
 		Title: app.Title,
 		Description: app.Description,
+		FrontImage:  app.FrontImage,
 		Content:     toCoreContent(app.Content),
+		Status:      app.Status,
+		PublishAt:   publishAt,
 	}
 
-	return post
+	return post, nil
+}
+
+// parsePublishAt parses the publication time of a scheduled post, empty
+// meaning none.
+func parsePublishAt(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, validate.NewFieldsError("publishAt", err)
+	}
+
+	return t, nil
 }
 
 // Validate checks the data in the model is considered clean.
@@ -70,6 +137,33 @@ func (app AppNewPost) Validate() error {
 		return err
 	}
 
+	return post.ValidateContent(toCoreContent(app.Content))
+}
+
+// =============================================================================
+
+// Set of document formats a post can be imported from.
+const (
+	importMarkdown = "markdown"
+	importHTML     = "html"
+)
+
+// AppImportPost contains information needed to create a post from an existing
+// Markdown or HTML document. Without a title the leading heading of the
+// document is used.
+type AppImportPost struct {
+	Title       string `json:"title"`
+	Description string `json:"description" validate:"required"`
+	Format      string `json:"format" validate:"required,oneof=markdown html"`
+	Source      string `json:"source" validate:"required,max=1048576"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppImportPost) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -77,8 +171,36 @@ func (app AppNewPost) Validate() error {
 
 // AppUpdatePost contains information needed to update a post.
 type AppUpdatePost struct {
-	Title        	string   	`json:"title" validate:"required"`
-	Content      	string   	`json:"content" validate:"required"`
+	Title        	*string   		`json:"title" validate:"omitempty,min=1"`
+	Description  	*string   		`json:"description" validate:"omitempty,min=1"`
+	FrontImage   	*string   		`json:"frontImage" validate:"omitempty,max=512"`
+	Content      	*AppContent   	`json:"content" validate:"omitempty"`
+	Status       	*string   		`json:"status" validate:"omitempty,oneof=draft scheduled published archived"`
+	PublishAt    	*string   		`json:"publishAt" validate:"omitempty"`
+}
+
+func toCoreUpdatePost(app AppUpdatePost) (post.UpdatePost, error) {
+	up := post.UpdatePost{
+		Title:       app.Title,
+		Description: app.Description,
+		FrontImage:  app.FrontImage,
+		Status:      app.Status,
+	}
+
+	if app.Content != nil {
+		content := toCoreContent(*app.Content)
+		up.Content = &content
+	}
+
+	if app.PublishAt != nil {
+		publishAt, err := parsePublishAt(*app.PublishAt)
+		if err != nil {
+			return post.UpdatePost{}, err
+		}
+		up.PublishAt = &publishAt
+	}
+
+	return up, nil
 }
 
 // Validate checks the data in the model is considered clean.
@@ -87,6 +209,10 @@ func (up AppUpdatePost) Validate() error {
 		return err
 	}
 
+	if up.Content != nil {
+		return post.ValidateContent(toCoreContent(*up.Content))
+	}
+
 	return nil
 }
 
@@ -107,11 +233,13 @@ type AppBlock struct {
 	Caption string    	`json:"caption,omitempty"`
 }
 
-// Style contains text styling information (e.g., bold, italic), offset and length.
+// Style contains text styling information (e.g., bold, italic, link), offset and length.
+// URL is the target of link styles.
 type AppStyle struct {
-	Offset int    `json:"offset" validate:"required"`
-	Length int    `json:"length" validate:"required"`
+	Offset int    `json:"offset" validate:"min=0"`
+	Length int    `json:"length" validate:"min=1"`
 	Style  string `json:"style" validate:"required"`
+	URL    string `json:"url,omitempty"`
 }
 
 // Converts AppContent (app layer) to post.Content (core layer)
@@ -144,6 +272,7 @@ func toCoreStyles(styles []AppStyle) []post.Style {
 			Offset: s.Offset,
 			Length: s.Length,
 			Style:  s.Style,
+			URL:    s.URL,
 		}
 	}
 	return converted
@@ -182,7 +311,106 @@ func toAppStyles(styles []post.Style) []AppStyle {
 			Offset: s.Offset,
 			Length: s.Length,
 			Style:  s.Style,
+			URL:    s.URL,
 		}
 	}
 	return converted
-}
\ No newline at end of file
+}
+// =============================================================================
+// Revision related models and functions
+
+// AppRevision represents a revision of a post in the app layer. Content is
+// left out of revision listings.
+type AppRevision struct {
+	Version     int         `json:"version"`
+	PostId      int64       `json:"postId"`
+	ContentId   int64       `json:"contentId"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	FrontImage  string      `json:"frontImage"`
+	Content     *AppContent `json:"content,omitempty"`
+	CreatedAt   string      `json:"createdAt"`
+}
+
+// Converts post.Revision (core layer) to AppRevision (app layer)
+func toAppRevision(rev post.Revision) AppRevision {
+	app := toAppRevisionSummary(rev)
+	app.Content = toAppContent(rev.Content)
+	return app
+}
+
+// Converts post.Revision (core layer) to AppRevision (app layer) without its content
+func toAppRevisionSummary(rev post.Revision) AppRevision {
+	return AppRevision{
+		Version:     rev.Version,
+		PostId:      rev.PostId,
+		ContentId:   rev.ContentId,
+		Title:       rev.Title,
+		Description: rev.Description,
+		FrontImage:  rev.FrontImage,
+		CreatedAt:   rev.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// Converts a slice of post.Revision (core layer) to a slice of AppRevision (app layer) without their content
+func toAppRevisionSummaries(revs []post.Revision) []AppRevision {
+	items := make([]AppRevision, len(revs))
+	for i, rev := range revs {
+		items[i] = toAppRevisionSummary(rev)
+	}
+	return items
+}
+
+// AppDiff represents the changes between two revisions of a post. Fields
+// lists the metadata fields that changed.
+type AppDiff struct {
+	From   int              `json:"from"`
+	To     int              `json:"to"`
+	Fields []string         `json:"fields"`
+	Blocks []AppBlockChange `json:"blocks"`
+}
+
+// AppBlockChange represents a step of a block level diff. Indexes are the
+// positions of the block in each revision, unchanged blocks only carry them.
+type AppBlockChange struct {
+	Op       string    `json:"op"`
+	OldIndex *int      `json:"oldIndex,omitempty"`
+	NewIndex *int      `json:"newIndex,omitempty"`
+	Old      *AppBlock `json:"old,omitempty"`
+	New      *AppBlock `json:"new,omitempty"`
+}
+
+// Converts post.Diff (core layer) to AppDiff (app layer)
+func toAppDiff(diff post.Diff) AppDiff {
+	fields := diff.Fields
+	if fields == nil {
+		fields = []string{}
+	}
+
+	blocks := make([]AppBlockChange, len(diff.Blocks))
+	for i, c := range diff.Blocks {
+		change := AppBlockChange{Op: c.Op}
+		if c.OldIndex >= 0 {
+			change.OldIndex = &c.OldIndex
+			if c.Op != post.DiffEqual {
+				block := toAppBlocks([]post.Block{c.Old})[0]
+				change.Old = &block
+			}
+		}
+		if c.NewIndex >= 0 {
+			change.NewIndex = &c.NewIndex
+			if c.Op != post.DiffEqual {
+				block := toAppBlocks([]post.Block{c.New})[0]
+				change.New = &block
+			}
+		}
+		blocks[i] = change
+	}
+
+	return AppDiff{
+		From:   diff.From,
+		To:     diff.To,
+		Fields: fields,
+		Blocks: blocks,
+	}
+}
@@ -12,15 +12,17 @@ import (
 const (
 	orderByCreatedAt = "created_at"
 	orderByUpdatedAt = "updated_at"
+	orderByReadingTime = "reading_time"
 )
 
 var orderByFields = map[string]string{
 	orderByCreatedAt:   post.OrderByCreatedAt,
 	orderByUpdatedAt:   post.OrderByUpdatedAt,
+	orderByReadingTime: post.OrderByReadingTime,
 }
 
 func parseOrder(r *http.Request) (order.OrderBy, error) {
-	orderBy, err := order.Parse(r, order.NewBy(orderByCreatedAt, order.ASC))
+	orderBy, err := order.Parse(r, post.DefaultOrderBy)
 	if err != nil {
 		return order.OrderBy{}, err
 	}
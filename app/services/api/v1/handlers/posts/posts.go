@@ -5,11 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/core/post/parse"
+	"github.com/hpetrov29/resttemplate/business/core/post/render"
 	"github.com/hpetrov29/resttemplate/business/data/page"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/validate"
 	"github.com/hpetrov29/resttemplate/internal/web"
 )
 
@@ -17,57 +24,381 @@ import (
 type Handlers struct {
 	post *post.Core
 	auth *auth.Auth
+	mediaBaseURL string
 }
 
 // New constructs a new handlers struct for route access.
-func New(pc *post.Core, auth *auth.Auth) *Handlers {
+func New(pc *post.Core, auth *auth.Auth, mediaBaseURL string) *Handlers {
 	return &Handlers{
 		post: pc,
 		auth: auth,
+		mediaBaseURL: mediaBaseURL,
 	}
 }
 
+// checkFrontImage verifies a front image is one of the uploaded media, posts
+// do not hotlink images from other hosts.
+func (h *Handlers) checkFrontImage(url string) error {
+	if url == "" || h.isMedia(url) {
+		return nil
+	}
+
+	return validate.NewFieldsError("frontImage", errors.New("frontImage must be the url of an uploaded media"))
+}
+
+// checkContentImages applies the rule of checkFrontImage to the image blocks
+// of a post content.
+func (h *Handlers) checkContentImages(c post.Content) error {
+	var fields validate.FieldErrors
+	for i, b := range c.Blocks {
+		if b.Type != post.BlockImage || h.isMedia(b.URL) {
+			continue
+		}
+		fields = append(fields, validate.FieldError{
+			Field: fmt.Sprintf("content.blocks[%d].url", i),
+			Err:   "url must be the url of an uploaded media",
+		})
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	return nil
+}
+
+// isMedia reports whether url is served under the media base URL. Any url is
+// accepted when no base URL is configured.
+func (h *Handlers) isMedia(url string) bool {
+	return h.mediaBaseURL == "" || strings.HasPrefix(url, h.mediaBaseURL+"/")
+}
+
 func (h *Handlers) CreatePost(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var appNewPost AppNewPost
 	claims := auth.GetClaims(ctx)
 	if claims.Subject == "" {
-		return web.Respond(ctx, w, http.StatusUnauthorized, errors.New("authentication failed"))
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(errors.New("claims have no subject")))
 	}
 
 	userId, err := strconv.ParseInt(claims.Subject, 10, 64) // Base 10, 64-bit unsigned integer
 	if err != nil {
-		return web.Respond(ctx, w, http.StatusUnauthorized, fmt.Errorf("authentication failed: %w", err))
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
 	}
 
 	if err := web.Decode(r, &appNewPost); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	if err := h.checkFrontImage(appNewPost.FrontImage); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+	if err := h.checkContentImages(toCoreContent(appNewPost.Content)); err != nil {
 		return web.Respond(ctx, w, http.StatusBadRequest, err)
 	}
 
-	coreNewPost := toCoreNewPost(appNewPost, userId)
+	coreNewPost, err := toCoreNewPost(appNewPost, userId)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
 
-	post, err := h.post.Create(ctx, coreNewPost)
+	corePost, err := h.post.Create(ctx, coreNewPost)
 	if err != nil {
+		return respondChangeError(ctx, w, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppPost(corePost))
+}
+
+// respondChangeError responds with the error of creating or updating a post.
+// The lifecycle rules enforced by the core are client errors.
+func respondChangeError(ctx context.Context, w http.ResponseWriter, err error) error {
+	switch {
+	case validate.IsFieldErrors(err):
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	case errors.Is(err, post.ErrInvalidTransition):
+		return web.Respond(ctx, w, http.StatusConflict, web.NewError(response.CodeInvalidTransition, err))
+	case errors.Is(err, post.ErrConcurrentEdit):
+		return web.Respond(ctx, w, http.StatusConflict, web.NewError(response.CodeConcurrentEdit, err))
+	}
+
+	return web.Respond(ctx, w, http.StatusInternalServerError, err)
+}
+
+// ImportPost creates a post from a Markdown or HTML document. Documents using
+// constructs post content cannot represent are refused with the list of
+// those constructs.
+func (h *Handlers) ImportPost(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims := auth.GetClaims(ctx)
+	userId, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
+	}
+
+	var app AppImportPost
+	if err := web.Decode(r, &app); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	var content post.Content
+	switch app.Format {
+	case importMarkdown:
+		content, err = parse.Markdown(app.Source)
+	case importHTML:
+		content, err = parse.HTML(app.Source)
+	}
+	if err != nil {
+		var unsupported *parse.UnsupportedError
+		if errors.As(err, &unsupported) {
+			return web.Respond(ctx, w, http.StatusUnprocessableEntity, &web.Error{
+				Code:    response.CodeUnsupportedContent,
+				Message: "the document uses constructs posts cannot represent",
+				Details: unsupported.Issues,
+				Err:     err,
+			})
+		}
+		return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError("source", err))
+	}
+
+	if app.Title == "" && len(content.Blocks) > 0 && content.Blocks[0].Type == post.BlockHeading {
+		app.Title = content.Blocks[0].Content
+		content.Blocks = content.Blocks[1:]
+	}
+	if app.Title == "" {
+		return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError("title", errors.New("title is required when the document does not start with a heading")))
+	}
+
+	if err := post.ValidateContent(content); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+	if err := h.checkContentImages(content); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	corePost, err := h.post.Create(ctx, post.NewPost{
+		UserId:      userId,
+		Title:       app.Title,
+		Description: app.Description,
+		Content:     content,
+	})
+	if err != nil {
+		return respondChangeError(ctx, w, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppPost(corePost))
+}
+
+// UpdatePost updates the post loaded by the AuthorizePost middleware.
+func (h *Handlers) UpdatePost(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var appUpdatePost AppUpdatePost
+	if err := web.Decode(r, &appUpdatePost); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	if appUpdatePost.FrontImage != nil {
+		if err := h.checkFrontImage(*appUpdatePost.FrontImage); err != nil {
+			return web.Respond(ctx, w, http.StatusBadRequest, err)
+		}
+	}
+	if appUpdatePost.Content != nil {
+		if err := h.checkContentImages(toCoreContent(*appUpdatePost.Content)); err != nil {
+			return web.Respond(ctx, w, http.StatusBadRequest, err)
+		}
+	}
+
+	corePost, ok := middleware.GetPost(ctx)
+	if !ok {
+		return web.Respond(ctx, w, http.StatusInternalServerError, errors.New("post missing from context"))
+	}
+
+	up, err := toCoreUpdatePost(appUpdatePost)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	corePost, err = h.post.Update(ctx, corePost, up)
+	if err != nil {
+		return respondChangeError(ctx, w, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppPost(corePost))
+}
+
+// DeletePost removes the post loaded by the AuthorizePost middleware.
+func (h *Handlers) DeletePost(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	corePost, ok := middleware.GetPost(ctx)
+	if !ok {
+		return web.Respond(ctx, w, http.StatusInternalServerError, errors.New("post missing from context"))
+	}
+
+	if err := h.post.Delete(ctx, corePost); err != nil {
 		return web.Respond(ctx, w, http.StatusInternalServerError, err)
 	}
 
-	return web.Respond(ctx, w, http.StatusOK, toAppPost(post))
+	return web.Respond(ctx, w, http.StatusNoContent, nil)
 }
 
 func (h *Handlers) QueryById(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	id, err := strconv.ParseInt(web.Param(r, "id"), 10, 64); if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidId("id", err))
+	}
+
+	format, err := parseFormat(r)
+	if err != nil {
 		return web.Respond(ctx, w, http.StatusBadRequest, err)
 	}
 
 	corePost, err := h.post.QueryById(ctx, id)
 	if err != nil {
 		if errors.Is(err, post.ErrNotFound) {	
-			return web.Respond(ctx, w, http.StatusNotFound, err)
+			return web.Respond(ctx, w, http.StatusNotFound, post.ErrNotFound)
 		}
 		
 		return web.Respond(ctx, w, http.StatusInternalServerError, err)
 	}
-	
-	return web.Respond(ctx, w, http.StatusOK, toAppPost(corePost))
+
+	// Posts that are not published do not exist for the public, their
+	// authors find them through QueryMine.
+	if !corePost.Public() {
+		return web.Respond(ctx, w, http.StatusNotFound, post.ErrNotFound)
+	}
+
+	var canonical string
+	if corePost.Slug != "" {
+		canonical = path.Dir(r.URL.Path) + "/by-slug/" + corePost.Slug
+	}
+
+	return h.respondPost(ctx, w, r, corePost, format, canonical)
+}
+
+// QueryBySlug returns the post a slug leads to. Previous slugs of a post,
+// from before its title changed, redirect to its current slug.
+func (h *Handlers) QueryBySlug(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	slug := web.Param(r, "slug")
+
+	format, err := parseFormat(r)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	corePost, err := h.post.QueryBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, post.ErrNotFound) {
+			return web.Respond(ctx, w, http.StatusNotFound, post.ErrNotFound)
+		}
+
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	if !corePost.Public() {
+		return web.Respond(ctx, w, http.StatusNotFound, post.ErrNotFound)
+	}
+
+	canonical := path.Dir(r.URL.Path) + "/" + corePost.Slug
+
+	if corePost.Slug != slug {
+		location := canonical
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
+
+		web.SetStatusCode(ctx, http.StatusMovedPermanently)
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+		return nil
+	}
+
+	return h.respondPost(ctx, w, r, corePost, format, canonical)
+}
+
+// respondPost sends a post in the requested format. The canonical path of
+// the post, its slug URL, is sent in a Link header when the post has one.
+func (h *Handlers) respondPost(ctx context.Context, w http.ResponseWriter, r *http.Request, corePost post.Post, format render.Format, canonical string) error {
+	if canonical != "" {
+		w.Header().Set("Link", "<"+canonical+`>; rel="canonical"`)
+	}
+
+	// The representation depends on the Accept header, caches must keep
+	// one entry per format.
+	w.Header().Add("Vary", "Accept")
+
+	if format != "" {
+		return h.respondRendered(ctx, w, r, corePost, format)
+	}
+
+	// The etag hashes the representation itself, the update time alone
+	// loses precision between the cache and the database.
+	appPost := toAppPost(corePost)
+	etag, err := web.ContentETag(appPost)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	if web.NotModified(w, r, etag, corePost.UpdatedAt) {
+		return web.Respond(ctx, w, http.StatusNotModified, nil)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, appPost)
+}
+
+// respondRendered sends the content of a post rendered to format.
+func (h *Handlers) respondRendered(ctx context.Context, w http.ResponseWriter, r *http.Request, corePost post.Post, format render.Format) error {
+	doc, err := render.Render(format, corePost.Content)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	etag, err := web.ContentETag(doc)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	if web.NotModified(w, r, etag, corePost.UpdatedAt) {
+		return web.Respond(ctx, w, http.StatusNotModified, nil)
+	}
+
+	return web.RespondContent(ctx, w, http.StatusOK, format.ContentType(), []byte(doc))
+}
+
+// QueryByIds returns a batch of posts in the order of their ids. Ids without
+// a public post are listed as missing rather than failing the batch.
+func (h *Handlers) QueryByIds(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var query AppBatchQuery
+	if err := web.Decode(r, &query); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	posts, missing, err := h.post.QueryByIds(ctx, query.Ids)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	absent := make(map[int64]bool, len(missing))
+	for _, id := range missing {
+		absent[id] = true
+	}
+
+	// Posts that are not published do not exist for the public, they are
+	// missing like the ids without a post.
+	batch := AppBatchPosts{
+		Posts:   make([]AppPost, 0, len(posts)),
+		Missing: make([]int64, 0, len(missing)),
+	}
+	for _, p := range posts {
+		if !p.Public() {
+			absent[p.Id] = true
+			continue
+		}
+		batch.Posts = append(batch.Posts, toAppPost(p))
+	}
+
+	// The missing ids keep the order they were given in.
+	for _, id := range query.Ids {
+		if absent[id] {
+			batch.Missing = append(batch.Missing, id)
+			delete(absent, id)
+		}
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, batch)
 }
 
 func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -80,6 +411,46 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 	if err != nil {
 		return web.Respond(ctx, w, http.StatusBadRequest, err)
 	}
+	filter.WithStatus(post.StatusPublished)
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	posts, err := h.post.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppPosts(posts))
+}
+// QueryMine lists the posts of the authenticated user in any status, the
+// status query parameter narrowing them down to one, e.g. the drafts.
+func (h *Handlers) QueryMine(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims := auth.GetClaims(ctx)
+	userId, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
+	}
+
+	page, err := page.Parse(r)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+	filter.WithUserId(userId)
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		if !post.ValidStatus(status) {
+			return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError("status", fmt.Errorf("status must be one of %s", strings.Join(post.Statuses(), ", "))))
+		}
+		filter.WithStatus(status)
+	}
 
 	orderBy, err := parseOrder(r)
 	if err != nil {
@@ -92,4 +463,4 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 	}
 
 	return web.Respond(ctx, w, http.StatusOK, toAppPosts(posts))
-}
\ No newline at end of file
+}
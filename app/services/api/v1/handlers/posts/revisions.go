@@ -0,0 +1,125 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// QueryRevisions lists the revisions of the post loaded by the AuthorizePost
+// middleware, oldest first. Content blocks are left out, they are fetched
+// one revision at a time.
+func (h *Handlers) QueryRevisions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	corePost, ok := middleware.GetPost(ctx)
+	if !ok {
+		return web.Respond(ctx, w, http.StatusInternalServerError, errors.New("post missing from context"))
+	}
+
+	revs, err := h.post.QueryRevisions(ctx, corePost)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppRevisionSummaries(revs))
+}
+
+// QueryRevision returns a revision of the post loaded by the AuthorizePost
+// middleware with its content.
+func (h *Handlers) QueryRevision(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	corePost, ok := middleware.GetPost(ctx)
+	if !ok {
+		return web.Respond(ctx, w, http.StatusInternalServerError, errors.New("post missing from context"))
+	}
+
+	version, err := parseVersion(web.Param(r, "rev"))
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	rev, err := h.post.QueryRevision(ctx, corePost, version)
+	if err != nil {
+		return respondRevisionError(ctx, w, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppRevision(rev))
+}
+
+// DiffRevision returns the block level changes leading to a revision. The
+// from query parameter selects the revision it is compared with, the one
+// before it by default.
+func (h *Handlers) DiffRevision(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	corePost, ok := middleware.GetPost(ctx)
+	if !ok {
+		return web.Respond(ctx, w, http.StatusInternalServerError, errors.New("post missing from context"))
+	}
+
+	to, err := parseVersion(web.Param(r, "rev"))
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	from := to - 1
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = strconv.Atoi(v); err != nil {
+			return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError("from", err))
+		}
+	}
+	if from < 1 {
+		return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError("from", errors.New("from must be a revision, the first one has no previous revision")))
+	}
+
+	diff, err := h.post.Diff(ctx, corePost, from, to)
+	if err != nil {
+		return respondRevisionError(ctx, w, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppDiff(diff))
+}
+
+// RestoreRevision makes a revision the current state of the post loaded by
+// the AuthorizePost middleware. The restored state becomes a new revision.
+func (h *Handlers) RestoreRevision(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	corePost, ok := middleware.GetPost(ctx)
+	if !ok {
+		return web.Respond(ctx, w, http.StatusInternalServerError, errors.New("post missing from context"))
+	}
+
+	version, err := parseVersion(web.Param(r, "rev"))
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	corePost, err = h.post.Restore(ctx, corePost, version)
+	if err != nil {
+		return respondRevisionError(ctx, w, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppPost(corePost))
+}
+
+// respondRevisionError responds with the error of reading or restoring a
+// revision.
+func respondRevisionError(ctx context.Context, w http.ResponseWriter, err error) error {
+	if errors.Is(err, post.ErrRevisionNotFound) {
+		return web.Respond(ctx, w, http.StatusNotFound, post.ErrRevisionNotFound)
+	}
+
+	return respondChangeError(ctx, w, err)
+}
+
+// parseVersion parses the version number of a revision.
+func parseVersion(s string) (int, error) {
+	version, err := strconv.Atoi(s)
+	if err != nil || version < 1 {
+		return 0, web.NewError(response.CodeInvalidId, errors.New("revision must be a positive integer"))
+	}
+
+	return version, nil
+}
@@ -2,14 +2,18 @@ package posts
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/core/role"
 	"github.com/hpetrov29/resttemplate/business/core/post/stores/postcache"
+	"github.com/hpetrov29/resttemplate/business/core/post/stores/postmessaging"
 	"github.com/hpetrov29/resttemplate/business/core/post/stores/postnosqldb"
 	"github.com/hpetrov29/resttemplate/business/core/post/stores/postorchestrator"
 	"github.com/hpetrov29/resttemplate/business/core/post/stores/postsqldb"
 	"github.com/hpetrov29/resttemplate/business/data/cache"
 	"github.com/hpetrov29/resttemplate/business/data/dbnosql"
+	"github.com/hpetrov29/resttemplate/business/data/messaging"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
 	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
 	"github.com/hpetrov29/resttemplate/internal/idgenerator"
@@ -18,6 +22,14 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// queryTimeout bounds the public read routes, which only hit the cache and
+// indexed lookups and should never come close to the application deadline.
+const queryTimeout = 3 * time.Second
+
+// listMaxAge is how long caches may serve a page of posts without asking
+// again. New posts taking that long to show up in listings is acceptable.
+const listMaxAge = 30 * time.Second
+
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
 	Log  		*logger.Logger
@@ -25,7 +37,14 @@ type Config struct {
 	Cache 		cache.Cache
 	SQLDB   	*sqlx.DB
 	NOSQLDB 	dbnosql.NOSQLDB
+	Messaging 	messaging.MessagingQueue
 	IdGen 		*idgenerator.IdGenerator
+	RateLimit 	web.Middleware
+	Idempotency web.Middleware
+
+	// MediaBaseURL is the URL uploaded media are served under, front images
+	// and image blocks must point there.
+	MediaBaseURL string
 }
 
 // Routes initializes the required post specific repositories, services and handlers,
@@ -36,24 +55,61 @@ type Config struct {
 // 	- cfg: configuration including pointers to the logging, database, and authentication systems.
 func Routes(app *web.App, cfg Config) {
 	nosqlRepo := cfg.NOSQLDB.GetRepository("posts")
+	revisionRepo := cfg.NOSQLDB.GetRepository("post_revisions")
 
 	sqlStore := postsqldb.NewStore(cfg.Log, cfg.SQLDB)
 	nosqlStore := postnosqldb.NewStore(cfg.Log, nosqlRepo)
+	revisionStore := postnosqldb.NewRevisionStore(cfg.Log, revisionRepo)
 	cacheStore := postcache.NewStore(cfg.Log, cfg.Cache)
-	hybridStore := postorchestrator.NewStore(cfg.Log, cacheStore, sqlStore, nosqlStore)
+	hybridStore := postorchestrator.NewStore(cfg.Log, cacheStore, sqlStore, nosqlStore, revisionStore)
+	
+	publisher := postmessaging.NewStore(cfg.Log, cfg.Messaging, postmessaging.SubjectPublished)
 	
-	userService := post.NewCore(hybridStore, cfg.Log, cfg.IdGen)
+	userService := post.NewCore(hybridStore, publisher, cfg.Log, cfg.IdGen)
 
-	handlers := New(userService, cfg.Auth)
+	handlers := New(userService, cfg.Auth, cfg.MediaBaseURL)
 
-	authenticated := middleware.Authenticate(cfg.Auth)
-	_ = middleware.Authorize(cfg.Auth, auth.RuleAdminOnly)
-	_ = middleware.Authorize(cfg.Auth, auth.RuleAdminOrSubject)
+	// Posts are bulk created by ingestion scripts, so API keys are accepted
+	// next to JWTs. Key scopes are enforced through the permission checks.
+	authenticated := middleware.AuthenticateKeyOrToken(cfg.Auth)
+	canCreate := middleware.RequirePermission(cfg.Auth, role.PermPostCreate)
+	canEdit := middleware.AuthorizePost(cfg.Auth, userService, auth.RulePostEdit)
+	canDelete := middleware.AuthorizePost(cfg.Auth, userService, auth.RulePostDelete)
+
+	queryDeadline := middleware.Timeout(queryTimeout)
+
+	tags := []string{"posts"}
+	queryParams := append([]string{"page", "rows", "orderBy"}, filterParams...)
 
 	// UNPROTECTED ROUTES
-	app.Handle(http.MethodGet, "/post/{id}", handlers.QueryById)
-	app.Handle(http.MethodGet, "/posts", handlers.Query)
+	app.Handle(http.MethodGet, "/post/{id}", handlers.QueryById, queryDeadline, middleware.CacheControl(web.CacheRevalidate)).
+		Describe(web.Doc{Summary: "Get a post", Tags: tags, Query: []string{"format"}, Response: AppPost{}})
+	app.Handle(http.MethodGet, "/post/by-slug/{slug}", handlers.QueryBySlug, queryDeadline, middleware.CacheControl(web.CacheRevalidate)).
+		Describe(web.Doc{Summary: "Get a post by its slug", Tags: tags, Query: []string{"format"}, Response: AppPost{}})
+	app.Handle(http.MethodPost, "/posts/batch", handlers.QueryByIds, queryDeadline, middleware.CacheControl(web.CacheNoStore)).
+		Describe(web.Doc{Summary: "Get a batch of posts", Tags: tags, Request: AppBatchQuery{}, Response: AppBatchPosts{}})
+	app.Handle(http.MethodGet, "/posts", handlers.Query, queryDeadline, middleware.CacheControl(web.CachePublicFor(listMaxAge))).
+		Describe(web.Doc{Summary: "List posts", Tags: tags, Query: queryParams, Response: []AppPost{}})
 
 	// PROTECTED ROUTES
-	app.Handle(http.MethodPost, "/post", handlers.CreatePost, authenticated)
+	app.Handle(http.MethodGet, "/users/me/posts", handlers.QueryMine, authenticated, middleware.CacheControl(web.CacheNoStore)).
+		Describe(web.Doc{Summary: "List the posts of the authenticated user", Tags: tags, Security: web.SecurityBearer, Query: append([]string{"status"}, queryParams...), Response: []AppPost{}})
+	app.Handle(http.MethodPost, "/post", handlers.CreatePost, authenticated, cfg.RateLimit, canCreate, cfg.Idempotency).
+		Describe(web.Doc{Summary: "Create a post", Tags: tags, Security: web.SecurityBearer, Request: AppNewPost{}, Response: AppPost{}})
+	app.Handle(http.MethodPost, "/post/import", handlers.ImportPost, authenticated, cfg.RateLimit, canCreate, cfg.Idempotency).
+		Describe(web.Doc{Summary: "Create a post from a Markdown or HTML document", Tags: tags, Security: web.SecurityBearer, Request: AppImportPost{}, Response: AppPost{}})
+	app.Handle(http.MethodPut, "/post/{id}", handlers.UpdatePost, authenticated, cfg.RateLimit, canEdit).
+		Describe(web.Doc{Summary: "Update a post", Tags: tags, Security: web.SecurityBearer, Request: AppUpdatePost{}, Response: AppPost{}})
+	app.Handle(http.MethodDelete, "/post/{id}", handlers.DeletePost, authenticated, canDelete).
+		Describe(web.Doc{Summary: "Delete a post", Tags: tags, Security: web.SecurityBearer, Status: http.StatusNoContent})
+
+	// The history of a post is only shown to those who can edit it.
+	app.Handle(http.MethodGet, "/post/{id}/revisions", handlers.QueryRevisions, authenticated, canEdit, middleware.CacheControl(web.CacheNoStore)).
+		Describe(web.Doc{Summary: "List the revisions of a post", Tags: tags, Security: web.SecurityBearer, Response: []AppRevision{}})
+	app.Handle(http.MethodGet, "/post/{id}/revisions/{rev}", handlers.QueryRevision, authenticated, canEdit, middleware.CacheControl(web.CacheNoStore)).
+		Describe(web.Doc{Summary: "Get a revision of a post", Tags: tags, Security: web.SecurityBearer, Response: AppRevision{}})
+	app.Handle(http.MethodGet, "/post/{id}/revisions/{rev}/diff", handlers.DiffRevision, authenticated, canEdit, middleware.CacheControl(web.CacheNoStore)).
+		Describe(web.Doc{Summary: "Compare a revision of a post with an earlier one", Tags: tags, Security: web.SecurityBearer, Query: []string{"from"}, Response: AppDiff{}})
+	app.Handle(http.MethodPost, "/post/{id}/revisions/{rev}/restore", handlers.RestoreRevision, authenticated, cfg.RateLimit, canEdit).
+		Describe(web.Doc{Summary: "Restore a revision of a post", Tags: tags, Security: web.SecurityBearer, Response: AppPost{}})
 }
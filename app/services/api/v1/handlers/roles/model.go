@@ -0,0 +1,132 @@
+package roles
+
+import (
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+)
+
+// AppRole represents the definition of a role in the app layer.
+type AppRole struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+func toAppRole(r role.Role) AppRole {
+	return AppRole{
+		Name:        r.Name.Name(),
+		Description: r.Description,
+		Permissions: toAppPermissions(r.Permissions),
+		CreatedAt:   r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// Converts a slice of role.Role (core layer) to a slice of AppRole (app layer)
+func toAppRoles(roles []role.Role) []AppRole {
+	items := make([]AppRole, len(roles))
+	for i, r := range roles {
+		items[i] = toAppRole(r)
+	}
+	return items
+}
+
+// Converts a slice of role.Permission (core layer) to their names (app layer)
+func toAppPermissions(perms []role.Permission) []string {
+	names := make([]string, len(perms))
+	for i, perm := range perms {
+		names[i] = perm.Name()
+	}
+	return names
+}
+
+// =============================================================================
+
+// AppNewRole contains information needed to define a new role.
+type AppNewRole struct {
+	Name        string   `json:"name" validate:"required"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+func toCoreNewRole(app AppNewRole) (role.NewRole, error) {
+	name, err := user.ParseRole(app.Name)
+	if err != nil {
+		return role.NewRole{}, validate.NewFieldsError("name", err)
+	}
+
+	perms, err := toCorePermissions(app.Permissions)
+	if err != nil {
+		return role.NewRole{}, err
+	}
+
+	nr := role.NewRole{
+		Name:        name,
+		Description: app.Description,
+		Permissions: perms,
+	}
+
+	return nr, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewRole) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// AppUpdateRole contains information needed to update a role.
+type AppUpdateRole struct {
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+func toCoreUpdateRole(app AppUpdateRole) (role.UpdateRole, error) {
+	var perms []role.Permission
+	if app.Permissions != nil {
+		var err error
+		perms, err = toCorePermissions(app.Permissions)
+		if err != nil {
+			return role.UpdateRole{}, err
+		}
+	}
+
+	ur := role.UpdateRole{
+		Description: app.Description,
+		Permissions: perms,
+	}
+
+	return ur, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateRole) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Converts permission names (app layer) to a slice of role.Permission (core layer)
+func toCorePermissions(names []string) ([]role.Permission, error) {
+	perms := make([]role.Permission, len(names))
+	for i, name := range names {
+		perm, err := role.ParsePermission(name)
+		if err != nil {
+			return nil, validate.NewFieldsError("permissions", err)
+		}
+		perms[i] = perm
+	}
+	return perms, nil
+}
@@ -0,0 +1,145 @@
+package roles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// Handlers manages the set of role endpoints.
+type Handlers struct {
+	role *role.Core
+}
+
+// New constructs a new handlers struct for route access.
+func New(rc *role.Core) *Handlers {
+	return &Handlers{
+		role: rc,
+	}
+}
+
+// Create defines a new role.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppNewRole
+	if err := web.Decode(r, &app); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	nr, err := toCoreNewRole(app)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	rl, err := h.role.Create(ctx, nr)
+	if err != nil {
+		if errors.Is(err, role.ErrUniqueName) {
+			return web.Respond(ctx, w, http.StatusConflict, web.NewError(response.CodeRoleNameTaken, role.ErrUniqueName))
+		}
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusCreated, toAppRole(rl))
+}
+
+// Update changes the description and/or the permissions of a role.
+func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppUpdateRole
+	if err := web.Decode(r, &app); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	ur, err := toCoreUpdateRole(app)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	rl, err := h.queryRole(ctx, r)
+	if err != nil {
+		return respondRoleError(ctx, w, err)
+	}
+
+	rl, err = h.role.Update(ctx, rl, ur)
+	if err != nil {
+		if errors.Is(err, role.ErrBuiltInPermission) {
+			return web.Respond(ctx, w, http.StatusBadRequest, web.NewError(response.CodeBuiltInRole, role.ErrBuiltInPermission))
+		}
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppRole(rl))
+}
+
+// Delete removes a role definition.
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	rl, err := h.queryRole(ctx, r)
+	if err != nil {
+		return respondRoleError(ctx, w, err)
+	}
+
+	if err := h.role.Delete(ctx, rl); err != nil {
+		switch {
+		case errors.Is(err, role.ErrBuiltInRole):
+			return web.Respond(ctx, w, http.StatusBadRequest, web.NewError(response.CodeBuiltInRole, role.ErrBuiltInRole))
+		case errors.Is(err, role.ErrRoleInUse):
+			return web.Respond(ctx, w, http.StatusConflict, web.NewError(response.CodeRoleInUse, role.ErrRoleInUse))
+		}
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusNoContent, nil)
+}
+
+// Query returns every role definition.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	roles, err := h.role.Query(ctx)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppRoles(roles))
+}
+
+// QueryByName returns a single role definition.
+func (h *Handlers) QueryByName(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	rl, err := h.queryRole(ctx, r)
+	if err != nil {
+		return respondRoleError(ctx, w, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppRole(rl))
+}
+
+// QueryPermissions returns the catalogue of permissions roles can grant.
+func (h *Handlers) QueryPermissions(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return web.Respond(ctx, w, http.StatusOK, toAppPermissions(role.Permissions()))
+}
+
+// =============================================================================
+
+// queryRole loads the role referenced by the name route parameter.
+func (h *Handlers) queryRole(ctx context.Context, r *http.Request) (role.Role, error) {
+	name, err := user.ParseRole(web.Param(r, "name"))
+	if err != nil {
+		return role.Role{}, validate.NewFieldsError("name", err)
+	}
+
+	return h.role.QueryByName(ctx, name)
+}
+
+// respondRoleError sends the response matching an error returned by queryRole.
+func respondRoleError(ctx context.Context, w http.ResponseWriter, err error) error {
+	switch {
+	case validate.IsFieldErrors(err):
+		return web.Respond(ctx, w, http.StatusBadRequest, validate.GetFieldErrors(err))
+	case errors.Is(err, role.ErrNotFound):
+		return web.Respond(ctx, w, http.StatusNotFound, role.ErrNotFound)
+	}
+
+	return web.Respond(ctx, w, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,51 @@
+package roles
+
+import (
+	"net/http"
+
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/role/stores/rolesqldb"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/web"
+	"github.com/jmoiron/sqlx"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log  *logger.Logger
+	Auth *auth.Auth
+	DB   *sqlx.DB
+}
+
+// Routes initializes the required role specific repositories, service and handler,
+// and sets up the API routes for the application with their respective handlers and middlewares.
+//
+// Parameters:
+// 	- app: the web.App instance used to register the routes.
+// 	- cfg: configuration including pointers to the logging, database, and authentication systems.
+func Routes(app *web.App, cfg Config) {
+	roleRepository := rolesqldb.NewStore(cfg.Log, cfg.DB)
+	roleService := role.NewCore(roleRepository, cfg.Log)
+	handlers := New(roleService)
+
+	authenticated := middleware.Authenticate(cfg.Auth)
+	manageRoles := middleware.RequirePermission(cfg.Auth, role.PermRoleManage)
+
+	tags := []string{"roles"}
+
+	// ADMIN ROUTES
+	app.Handle(http.MethodGet, "/admin/permissions", handlers.QueryPermissions, authenticated, manageRoles).
+		Describe(web.Doc{Summary: "List the known permissions", Tags: tags, Security: web.SecurityBearer, Response: []string{}})
+	app.Handle(http.MethodGet, "/admin/roles", handlers.Query, authenticated, manageRoles).
+		Describe(web.Doc{Summary: "List roles", Tags: tags, Security: web.SecurityBearer, Response: []AppRole{}})
+	app.Handle(http.MethodGet, "/admin/roles/{name}", handlers.QueryByName, authenticated, manageRoles).
+		Describe(web.Doc{Summary: "Get a role", Tags: tags, Security: web.SecurityBearer, Response: AppRole{}})
+	app.Handle(http.MethodPost, "/admin/roles", handlers.Create, authenticated, manageRoles).
+		Describe(web.Doc{Summary: "Create a role", Tags: tags, Security: web.SecurityBearer, Request: AppNewRole{}, Response: AppRole{}, Status: http.StatusCreated})
+	app.Handle(http.MethodPut, "/admin/roles/{name}", handlers.Update, authenticated, manageRoles).
+		Describe(web.Doc{Summary: "Update a role", Tags: tags, Security: web.SecurityBearer, Request: AppUpdateRole{}, Response: AppRole{}})
+	app.Handle(http.MethodDelete, "/admin/roles/{name}", handlers.Delete, authenticated, manageRoles).
+		Describe(web.Doc{Summary: "Delete a role", Tags: tags, Security: web.SecurityBearer, Status: http.StatusNoContent})
+}
@@ -0,0 +1,164 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/data/page"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// ErrSelfModification is returned when an administrator tries to disable or
+// delete their own account.
+var ErrSelfModification = errors.New("administrators cannot disable or delete their own account")
+
+// QueryUsers returns a filtered, ordered and paginated list of users.
+func (h *Handlers) QueryUsers(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := page.Parse(r)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	users, err := h.user.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	total, err := h.user.Count(ctx, filter)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, response.NewPageDocument(toAppUsers(users), total, page.Number, page.RowsPerPage))
+}
+
+// QueryUserById returns a single user.
+func (h *Handlers) QueryUserById(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.queryUser(ctx, r)
+	if err != nil {
+		return h.respondUserError(ctx, w, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppUser(usr, ""))
+}
+
+// UpdateRoles replaces the set of roles assigned to a user.
+func (h *Handlers) UpdateRoles(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppUpdateRoles
+	if err := web.Decode(r, &app); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	uu, err := toCoreUpdateRoles(app)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	if err := h.role.ValidateRoles(ctx, uu.Roles); err != nil {
+		if errors.Is(err, role.ErrNotFound) {
+			return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError("roles", err))
+		}
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	usr, err := h.queryUser(ctx, r)
+	if err != nil {
+		return h.respondUserError(ctx, w, err)
+	}
+
+	usr, err = h.user.Update(ctx, usr, uu)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppUser(usr, ""))
+}
+
+// UpdateEnabled enables or disables a user. Disabled users cannot log in and
+// their previously issued tokens are refused.
+func (h *Handlers) UpdateEnabled(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var app AppUpdateEnabled
+	if err := web.Decode(r, &app); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	usr, err := h.queryUser(ctx, r)
+	if err != nil {
+		return h.respondUserError(ctx, w, err)
+	}
+
+	if !*app.Enabled && isSubject(ctx, usr) {
+		return web.Respond(ctx, w, http.StatusBadRequest, web.NewError(response.CodeSelfModification, ErrSelfModification))
+	}
+
+	usr, err = h.user.Update(ctx, usr, toCoreUpdateEnabled(app))
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppUser(usr, ""))
+}
+
+// DeleteUser removes a user from the system.
+func (h *Handlers) DeleteUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	usr, err := h.queryUser(ctx, r)
+	if err != nil {
+		return h.respondUserError(ctx, w, err)
+	}
+
+	if isSubject(ctx, usr) {
+		return web.Respond(ctx, w, http.StatusBadRequest, web.NewError(response.CodeSelfModification, ErrSelfModification))
+	}
+
+	if err := h.user.Delete(ctx, usr); err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusNoContent, nil)
+}
+
+// =============================================================================
+
+// queryUser loads the user referenced by the id route parameter.
+func (h *Handlers) queryUser(ctx context.Context, r *http.Request) (user.User, error) {
+	id, err := strconv.ParseInt(web.Param(r, "id"), 10, 64)
+	if err != nil {
+		return user.User{}, response.InvalidId("id", err)
+	}
+
+	return h.user.QueryById(ctx, id)
+}
+
+// respondUserError sends the response matching an error returned by queryUser.
+func (h *Handlers) respondUserError(ctx context.Context, w http.ResponseWriter, err error) error {
+	switch {
+	case web.GetError(err) != nil:
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	case errors.Is(err, user.ErrNotFound):
+		return web.Respond(ctx, w, http.StatusNotFound, user.ErrNotFound)
+	}
+
+	return web.Respond(ctx, w, http.StatusInternalServerError, err)
+}
+
+// isSubject reports whether usr is the user making the request.
+func isSubject(ctx context.Context, usr user.User) bool {
+	return auth.GetClaims(ctx).Subject == strconv.FormatInt(usr.Id, 10)
+}
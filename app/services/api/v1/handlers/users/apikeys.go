@@ -0,0 +1,107 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// QueryAPIKeys returns the API keys owned by the authenticated user.
+func (h *Handlers) QueryAPIKeys(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userId, err := strconv.ParseInt(auth.GetClaims(ctx).Subject, 10, 64)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
+	}
+
+	keys, err := h.apiKey.QueryByUserId(ctx, userId)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppAPIKeys(keys))
+}
+
+// CreateAPIKey issues a new API key for the authenticated user. The key is
+// only returned in this response. A key can only be scoped to permissions
+// the user's roles grant.
+func (h *Handlers) CreateAPIKey(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims := auth.GetClaims(ctx)
+
+	userId, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
+	}
+
+	var app AppNewAPIKey
+	if err := web.Decode(r, &app); err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
+	}
+
+	nk, err := toCoreNewAPIKey(app, userId)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, err)
+	}
+
+	perms, err := h.role.Permissions(ctx, claims.Roles)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	held := make(map[string]bool, len(perms))
+	for _, perm := range perms {
+		held[perm.Name()] = true
+	}
+
+	for _, scope := range nk.Scopes {
+		if !held[scope.Name()] {
+			return web.Respond(ctx, w, http.StatusBadRequest, validate.NewFieldsError("scopes", fmt.Errorf("permission %q is not granted to you", scope.Name())))
+		}
+	}
+
+	key, secret, err := h.apiKey.Create(ctx, nk)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusCreated, toAppAPIKey(key, secret))
+}
+
+// DeleteAPIKey revokes one of the authenticated user's API keys.
+func (h *Handlers) DeleteAPIKey(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userId, err := strconv.ParseInt(auth.GetClaims(ctx).Subject, 10, 64)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
+	}
+
+	keyId, err := strconv.ParseInt(web.Param(r, "key_id"), 10, 64)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidId("key_id", err))
+	}
+
+	key, err := h.apiKey.QueryById(ctx, keyId)
+	if err != nil {
+		if errors.Is(err, apikey.ErrNotFound) {
+			return web.Respond(ctx, w, http.StatusNotFound, apikey.ErrNotFound)
+		}
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	// Keys of other users are reported as missing so their ids are not leaked.
+	if key.UserId != userId {
+		return web.Respond(ctx, w, http.StatusNotFound, apikey.ErrNotFound)
+	}
+
+	if err := h.apiKey.Delete(ctx, key); err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	return web.Respond(ctx, w, http.StatusNoContent, nil)
+}
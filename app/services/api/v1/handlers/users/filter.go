@@ -0,0 +1,81 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+)
+
+func parseFilter(r *http.Request) (user.QueryFilter, error) {
+	const (
+		filterByUserId           = "user_id"
+		filterByUsername         = "username"
+		filterByEmail            = "email"
+		filterByEnabled          = "enabled"
+		filterByStartCreatedDate = "start_created_date"
+		filterByEndCreatedDate   = "end_created_date"
+	)
+
+	values := r.URL.Query()
+
+	var filter user.QueryFilter
+
+	if userId := values.Get(filterByUserId); userId != "" {
+		id, err := strconv.ParseInt(userId, 10, 64)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError(filterByUserId, err)
+		}
+		filter.WithUserID(id)
+	}
+
+	if username := values.Get(filterByUsername); username != "" {
+		filter.WithName(username)
+	}
+
+	if email := values.Get(filterByEmail); email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError(filterByEmail, err)
+		}
+		filter.WithEmail(*addr)
+	}
+
+	if enabled := values.Get(filterByEnabled); enabled != "" {
+		e, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError(filterByEnabled, err)
+		}
+		filter.WithEnabled(e)
+	}
+
+	if createdDate := values.Get(filterByStartCreatedDate); createdDate != "" {
+		t, err := time.Parse(time.RFC3339, createdDate)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError(filterByStartCreatedDate, err)
+		}
+		filter.WithStartDateCreated(t)
+	}
+
+	if createdDate := values.Get(filterByEndCreatedDate); createdDate != "" {
+		t, err := time.Parse(time.RFC3339, createdDate)
+		if err != nil {
+			return user.QueryFilter{}, validate.NewFieldsError(filterByEndCreatedDate, err)
+		}
+		filter.WithEndCreatedDate(t)
+	}
+
+	if filter.StartCreatedDate != nil && filter.EndCreatedDate != nil && filter.EndCreatedDate.Before(*filter.StartCreatedDate) {
+		return user.QueryFilter{}, validate.NewFieldsError(filterByEndCreatedDate, errors.New("must not be before start_created_date"))
+	}
+
+	if err := filter.Validate(); err != nil {
+		return user.QueryFilter{}, err
+	}
+
+	return filter, nil
+}
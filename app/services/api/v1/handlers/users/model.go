@@ -5,6 +5,8 @@ import (
 	"net/mail"
 	"time"
 
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	"github.com/hpetrov29/resttemplate/business/core/role"
 	"github.com/hpetrov29/resttemplate/business/core/user"
 	"github.com/hpetrov29/resttemplate/internal/validate"
 )
@@ -16,8 +18,9 @@ type AppUser struct {
 	Email        string   `json:"email"`
 	Roles        []string `json:"roles"`
 	PasswordHash []byte   `json:"-"`
+	Enabled      bool     `json:"enabled"`
 	CreatedAt  	 string   `json:"createdAt"`
-	Token 		 string   `json:"token"`
+	Token 		 string   `json:"token,omitempty"`
 }
 
 func toAppUser(usr user.User, token string) AppUser {
@@ -32,6 +35,7 @@ func toAppUser(usr user.User, token string) AppUser {
 		Email:        	usr.Email.Address,
 		Roles:        	roles,
 		PasswordHash: 	usr.PasswordHash,
+		Enabled:      	usr.Enabled,
 		CreatedAt:  	usr.CreatedAt.Format(time.RFC3339),
 		Token: 			token,
 	}
@@ -65,6 +69,7 @@ func toCoreNewUser(app AppNewUser) (user.NewUser, error) {
 	usr := user.NewUser{
 		Username:        app.Username,
 		Email:           *addr,
+		Roles:           []user.Role{user.RoleUser},
 		Password:        app.Password,
 		PasswordConfirm: app.PasswordConfirm,
 	}
@@ -140,6 +145,150 @@ func (app AppUpdateUser) Validate() error {
 
 // =============================================================================
 
+// AppUpdateRoles contains the roles an administrator assigns to a user.
+type AppUpdateRoles struct {
+	Roles []string `json:"roles" validate:"required,min=1"`
+}
+
+func toCoreUpdateRoles(app AppUpdateRoles) (user.UpdateUser, error) {
+	roles := make([]user.Role, len(app.Roles))
+	for i, roleStr := range app.Roles {
+		role, err := user.ParseRole(roleStr)
+		if err != nil {
+			return user.UpdateUser{}, validate.NewFieldsError("roles", err)
+		}
+		roles[i] = role
+	}
+
+	return user.UpdateUser{Roles: roles}, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateRoles) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AppUpdateEnabled contains the state an administrator sets on a user.
+type AppUpdateEnabled struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+func toCoreUpdateEnabled(app AppUpdateEnabled) user.UpdateUser {
+	return user.UpdateUser{Enabled: app.Enabled}
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateEnabled) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// AppAPIKey represents an API key owned by the user. Key is only set in the
+// response that creates the key.
+type AppAPIKey struct {
+	Id         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expiresAt,omitempty"`
+	LastUsedAt string   `json:"lastUsedAt,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+	Key        string   `json:"key,omitempty"`
+}
+
+func toAppAPIKey(key apikey.APIKey, secret string) AppAPIKey {
+	scopes := make([]string, len(key.Scopes))
+	for i, perm := range key.Scopes {
+		scopes[i] = perm.Name()
+	}
+
+	app := AppAPIKey{
+		Id:        key.Id,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    scopes,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+		Key:       secret,
+	}
+
+	if !key.ExpiresAt.IsZero() {
+		app.ExpiresAt = key.ExpiresAt.Format(time.RFC3339)
+	}
+	if !key.LastUsedAt.IsZero() {
+		app.LastUsedAt = key.LastUsedAt.Format(time.RFC3339)
+	}
+
+	return app
+}
+
+func toAppAPIKeys(keys []apikey.APIKey) []AppAPIKey {
+	items := make([]AppAPIKey, len(keys))
+	for i, key := range keys {
+		items[i] = toAppAPIKey(key, "")
+	}
+
+	return items
+}
+
+// AppNewAPIKey contains information needed to create a new API key.
+type AppNewAPIKey struct {
+	Name      string   `json:"name" validate:"required,max=100"`
+	Scopes    []string `json:"scopes" validate:"required,min=1"`
+	ExpiresAt string   `json:"expiresAt" validate:"omitempty"`
+}
+
+func toCoreNewAPIKey(app AppNewAPIKey, userId int64) (apikey.NewAPIKey, error) {
+	scopes := make([]role.Permission, len(app.Scopes))
+	for i, scope := range app.Scopes {
+		perm, err := role.ParsePermission(scope)
+		if err != nil {
+			return apikey.NewAPIKey{}, validate.NewFieldsError("scopes", err)
+		}
+		scopes[i] = perm
+	}
+
+	var expiresAt time.Time
+	if app.ExpiresAt != "" {
+		var err error
+		expiresAt, err = time.Parse(time.RFC3339, app.ExpiresAt)
+		if err != nil {
+			return apikey.NewAPIKey{}, validate.NewFieldsError("expiresAt", err)
+		}
+		if !expiresAt.After(time.Now()) {
+			return apikey.NewAPIKey{}, validate.NewFieldsError("expiresAt", fmt.Errorf("must be in the future"))
+		}
+	}
+
+	nk := apikey.NewAPIKey{
+		UserId:    userId,
+		Name:      app.Name,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	return nk, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppNewAPIKey) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// =============================================================================
+
 type token struct {
 	Token string `json:"token"`
 }
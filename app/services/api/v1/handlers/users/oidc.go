@@ -0,0 +1,200 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/data/cache"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/oidc"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// oidcStateTTL bounds how long a user has to complete the login at the
+// identity provider.
+const oidcStateTTL = 10 * time.Minute
+
+// oidcLogin holds the configured identity providers and the cache used to
+// keep the state of logins in progress.
+type oidcLogin struct {
+	cache     cache.Cache
+	providers map[string]*oidc.Provider
+}
+
+func newOIDCLogin(c cache.Cache, providers []*oidc.Provider) *oidcLogin {
+	m := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		m[p.Name()] = p
+	}
+
+	return &oidcLogin{
+		cache:     c,
+		providers: m,
+	}
+}
+
+// oidcState is what the service remembers between redirecting a user to the
+// identity provider and the provider redirecting them back.
+type oidcState struct {
+	Provider string `json:"provider"`
+	Kid      string `json:"kid"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+func oidcStateKey(state string) string {
+	return "oidc:state:" + state
+}
+
+// OIDCLogin redirects the user to the login page of an identity provider. The
+// kid selects the key the service token is signed with after the callback.
+func (h *Handlers) OIDCLogin(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	provider, exists := h.oidc.providers[web.Param(r, "provider")]
+	if !exists {
+		return web.Respond(ctx, w, http.StatusNotFound, web.NewError(response.CodeUnknownProvider, errors.New("unknown identity provider")))
+	}
+
+	kid := web.Param(r, "kid")
+	if kid == "" {
+		return web.Respond(ctx, w, http.StatusBadRequest, errors.New("key id not provided"))
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	data, err := json.Marshal(oidcState{
+		Provider: provider.Name(),
+		Kid:      kid,
+		Nonce:    nonce,
+		Verifier: verifier,
+	})
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	if err := h.oidc.cache.SetWithTTL(ctx, oidcStateKey(state), data, oidcStateTTL); err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	redirect, err := provider.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadGateway, &web.Error{Code: web.CodeUpstream, Message: "identity provider unavailable", Err: err})
+	}
+
+	http.Redirect(w, r, redirect, http.StatusFound)
+
+	return nil
+}
+
+// OIDCCallback completes a login at an identity provider. The external
+// account is linked to an existing user or a new user is created, and the
+// service's own token is returned.
+func (h *Handlers) OIDCCallback(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	provider, exists := h.oidc.providers[web.Param(r, "provider")]
+	if !exists {
+		return web.Respond(ctx, w, http.StatusNotFound, web.NewError(response.CodeUnknownProvider, errors.New("unknown identity provider")))
+	}
+
+	query := r.URL.Query()
+
+	if e := query.Get("error"); e != "" {
+		return web.Respond(ctx, w, http.StatusUnauthorized, web.NewError(response.CodeLoginRefused, fmt.Errorf("identity provider refused the login: %s", e)))
+	}
+
+	code, state := query.Get("code"), query.Get("state")
+	if code == "" || state == "" {
+		return web.Respond(ctx, w, http.StatusBadRequest, errors.New("code and state are required"))
+	}
+
+	// The state is removed right away so a callback cannot be replayed.
+	data, ok, err := h.oidc.cache.GetNonFatal(ctx, oidcStateKey(state))
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+	if !ok {
+		return web.Respond(ctx, w, http.StatusBadRequest, web.NewError(response.CodeLoginExpired, errors.New("login expired or unknown state")))
+	}
+	if err := h.oidc.cache.Delete(ctx, oidcStateKey(state)); err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	var st oidcState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	if st.Provider != provider.Name() {
+		return web.Respond(ctx, w, http.StatusBadRequest, errors.New("state was issued for another identity provider"))
+	}
+
+	tkn, err := provider.Exchange(ctx, code, st.Verifier)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusUnauthorized, &web.Error{Code: response.CodeLoginRefused, Message: "identity provider refused the code", Err: err})
+	}
+
+	idClaims, err := provider.VerifyIDToken(ctx, tkn.IDToken, st.Nonce)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusUnauthorized, &web.Error{Code: response.CodeLoginRefused, Message: "invalid id token", Err: err})
+	}
+
+	addr, err := mail.ParseAddress(idClaims.Email)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusBadRequest, errors.New("identity provider did not return a valid email"))
+	}
+
+	usr, err := h.user.LoginExternal(ctx, user.ExternalIdentity{
+		Provider:      provider.Name(),
+		Subject:       idClaims.Subject,
+		Email:         *addr,
+		EmailVerified: idClaims.EmailVerified,
+		Name:          idClaims.Name,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrUnverifiedEmail):
+			return web.Respond(ctx, w, http.StatusConflict, web.NewError(response.CodeUnverifiedEmail, user.ErrUnverifiedEmail))
+		case errors.Is(err, user.ErrUserDisabled):
+			return web.Respond(ctx, w, http.StatusForbidden, web.NewError(response.CodeAccountDisabled, errors.New("account is disabled")))
+		}
+		return web.Respond(ctx, w, http.StatusInternalServerError, err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(usr.Id, 10),
+			Issuer:    "service",
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Email: usr.Email.Address,
+		Roles: usr.Roles,
+	}
+
+	token, err := h.auth.GenerateToken(st.Kid, claims)
+	if err != nil {
+		return web.Respond(ctx, w, http.StatusInternalServerError, errors.New("failed to generate a token"))
+	}
+
+	return web.Respond(ctx, w, http.StatusOK, toAppUser(usr, token))
+}
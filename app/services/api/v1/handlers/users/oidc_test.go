@@ -0,0 +1,344 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/data/cache"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/oidc"
+	"github.com/hpetrov29/resttemplate/internal/oidc/oidctest"
+)
+
+// memCache keeps the state of logins in progress in memory.
+type memCache struct {
+	cache.Cache
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (c *memCache) SetWithTTL(_ context.Context, key string, value []byte, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+	return nil
+}
+
+func (c *memCache) GetNonFatal(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.values[key]
+	return v, ok, nil
+}
+
+func (c *memCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, key)
+	return nil
+}
+
+// memUsers stores users and their external identities in memory.
+type memUsers struct {
+	user.Storer
+	users      map[int64]user.User
+	identities map[string]user.Identity
+}
+
+func (s *memUsers) Create(_ context.Context, usr user.User) (sql.Result, error) {
+	s.users[usr.Id] = usr
+	return nil, nil
+}
+
+func (s *memUsers) QueryById(_ context.Context, id int64) (user.User, error) {
+	usr, ok := s.users[id]
+	if !ok {
+		return user.User{}, user.ErrNotFound
+	}
+	return usr, nil
+}
+
+func (s *memUsers) QueryByEmail(_ context.Context, email mail.Address) (user.User, error) {
+	for _, usr := range s.users {
+		if usr.Email.Address == email.Address {
+			return usr, nil
+		}
+	}
+	return user.User{}, user.ErrNotFound
+}
+
+func (s *memUsers) CreateIdentity(_ context.Context, identity user.Identity) error {
+	s.identities[identity.Provider+"/"+identity.Subject] = identity
+	return nil
+}
+
+func (s *memUsers) QueryIdentity(_ context.Context, provider string, subject string) (user.Identity, error) {
+	identity, ok := s.identities[provider+"/"+subject]
+	if !ok {
+		return user.Identity{}, user.ErrNotFound
+	}
+	return identity, nil
+}
+
+type counter struct{ n uint64 }
+
+func (c *counter) GenerateId() (uint64, error) {
+	c.n++
+	return c.n, nil
+}
+
+// keyVault holds the key the service signs its tokens with.
+type keyVault struct{ pem string }
+
+func (v keyVault) PrivateKey(string) (string, error) { return v.pem, nil }
+func (v keyVault) PublicKey(string) (string, error)  { return "", nil }
+
+// oidcTest wires the handlers to a stub identity provider.
+type oidcTest struct {
+	idp      *oidctest.Server
+	users    *memUsers
+	handlers *Handlers
+}
+
+func newOIDCTest(t *testing.T) *oidcTest {
+	t.Helper()
+
+	log := logger.NewWithEvents(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" }, logger.Events{})
+
+	idp, err := oidctest.NewServer("article-recommender")
+	if err != nil {
+		t.Fatalf("stub provider: %s", err)
+	}
+	t.Cleanup(idp.Close)
+
+	provider, err := oidc.NewProvider(oidc.Config{
+		Name:        "stub",
+		Issuer:      idp.URL,
+		ClientID:    idp.ClientID,
+		RedirectURL: "http://localhost/v1/users/oidc/stub/callback",
+	}, idp.Client())
+	if err != nil {
+		t.Fatalf("provider: %s", err)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("key: %s", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	a, err := auth.New(auth.Config{Log: log, Vault: keyVault{pem: string(keyPEM)}})
+	if err != nil {
+		t.Fatalf("auth: %s", err)
+	}
+	t.Cleanup(a.Close)
+
+	users := &memUsers{users: make(map[int64]user.User), identities: make(map[string]user.Identity)}
+
+	h := New(user.NewCore(users, log, &counter{}), nil, nil, a)
+	h.oidc = newOIDCLogin(&memCache{values: make(map[string][]byte)}, []*oidc.Provider{provider})
+
+	return &oidcTest{idp: idp, users: users, handlers: h}
+}
+
+// request returns a request to target carrying the route parameters.
+func request(target string, params map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+// startLogin calls the login endpoint and lets the user log in at the
+// provider. It returns the code and state the provider redirects back with.
+func (ot *oidcTest) startLogin(t *testing.T) (string, string) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r := request("/v1/users/oidc/stub/login/kid", map[string]string{"provider": "stub", "kid": "kid"})
+	if err := ot.handlers.OIDCLogin(r.Context(), w, r); err != nil {
+		t.Fatalf("login: %s", err)
+	}
+	if w.Code != http.StatusFound {
+		t.Fatalf("login: got status %d, want %d: %s", w.Code, http.StatusFound, w.Body)
+	}
+
+	code, state, err := ot.idp.Authorize(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: %s", err)
+	}
+
+	return code, state
+}
+
+// callback calls the callback endpoint the provider redirects back to.
+func (ot *oidcTest) callback(t *testing.T, code string, state string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r := request("/v1/users/oidc/stub/callback?code="+code+"&state="+state, map[string]string{"provider": "stub"})
+	if err := ot.handlers.OIDCCallback(r.Context(), w, r); err != nil {
+		t.Fatalf("callback: %s", err)
+	}
+
+	return w
+}
+
+// login runs a whole login and returns the response of the callback.
+func (ot *oidcTest) login(t *testing.T) *httptest.ResponseRecorder {
+	t.Helper()
+
+	code, state := ot.startLogin(t)
+	return ot.callback(t, code, state)
+}
+
+func decodeUser(t *testing.T, w *httptest.ResponseRecorder) AppUser {
+	t.Helper()
+
+	var resp struct {
+		Payload AppUser `json:"payload"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %s: %s", err, w.Body)
+	}
+
+	return resp.Payload
+}
+
+func TestOIDCFirstLoginCreatesUser(t *testing.T) {
+	ot := newOIDCTest(t)
+
+	w := ot.login(t)
+	if w.Code != http.StatusOK {
+		t.Fatalf("callback: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	if len(ot.users.users) != 1 {
+		t.Fatalf("users: got %d, want 1", len(ot.users.users))
+	}
+
+	identity, ok := ot.users.identities["stub/"+ot.idp.User.Subject]
+	if !ok {
+		t.Fatal("identity: not linked")
+	}
+
+	usr := ot.users.users[identity.UserId]
+	if usr.Email.Address != ot.idp.User.Email {
+		t.Errorf("email: got %q, want %q", usr.Email.Address, ot.idp.User.Email)
+	}
+	if app := decodeUser(t, w); app.Token == "" {
+		t.Error("token: expected a service token")
+	}
+
+	// The next login finds the user through the linked identity.
+	w = ot.login(t)
+	if w.Code != http.StatusOK {
+		t.Fatalf("second callback: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if len(ot.users.users) != 1 {
+		t.Fatalf("users after second login: got %d, want 1", len(ot.users.users))
+	}
+}
+
+func TestOIDCLinksExistingUser(t *testing.T) {
+	ot := newOIDCTest(t)
+
+	existing := user.User{Id: 42, Username: "jane", Email: mail.Address{Address: ot.idp.User.Email}, Roles: []user.Role{user.RoleUser}, Enabled: true}
+	ot.users.users[existing.Id] = existing
+
+	w := ot.login(t)
+	if w.Code != http.StatusOK {
+		t.Fatalf("callback: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	if len(ot.users.users) != 1 {
+		t.Fatalf("users: got %d, want 1", len(ot.users.users))
+	}
+
+	identity, ok := ot.users.identities["stub/"+ot.idp.User.Subject]
+	if !ok || identity.UserId != existing.Id {
+		t.Fatalf("identity: got %+v, want one linked to user %d", identity, existing.Id)
+	}
+}
+
+func TestOIDCUnverifiedEmailNotLinked(t *testing.T) {
+	ot := newOIDCTest(t)
+
+	ot.users.users[42] = user.User{Id: 42, Username: "jane", Email: mail.Address{Address: ot.idp.User.Email}, Enabled: true}
+	ot.idp.User.EmailVerified = false
+
+	w := ot.login(t)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("callback: got status %d, want %d: %s", w.Code, http.StatusConflict, w.Body)
+	}
+
+	if len(ot.users.identities) != 0 {
+		t.Fatalf("identities: got %d, want none", len(ot.users.identities))
+	}
+}
+
+func TestOIDCStateIsSingleUse(t *testing.T) {
+	ot := newOIDCTest(t)
+
+	code, state := ot.startLogin(t)
+	if w := ot.callback(t, code, state); w.Code != http.StatusOK {
+		t.Fatalf("callback: got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	if w := ot.callback(t, code, state); w.Code != http.StatusBadRequest {
+		t.Fatalf("replayed callback: got status %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
+
+func TestOIDCRejectsForgedIDToken(t *testing.T) {
+	ot := newOIDCTest(t)
+
+	ot.idp.Claims = func(c jwt.MapClaims) { c["nonce"] = "forged" }
+
+	w := ot.login(t)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("callback: got status %d, want %d: %s", w.Code, http.StatusUnauthorized, w.Body)
+	}
+
+	if len(ot.users.users) != 0 {
+		t.Fatalf("users: got %d, want none", len(ot.users.users))
+	}
+}
+
+func TestOIDCUnverifiedEmailNotCreated(t *testing.T) {
+	ot := newOIDCTest(t)
+
+	ot.idp.User.EmailVerified = false
+
+	w := ot.login(t)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("callback: got status %d, want %d: %s", w.Code, http.StatusConflict, w.Body)
+	}
+
+	if len(ot.users.users) != 0 {
+		t.Fatalf("users: got %d, want none", len(ot.users.users))
+	}
+	if len(ot.users.identities) != 0 {
+		t.Fatalf("identities: got %d, want none", len(ot.users.identities))
+	}
+}
@@ -0,0 +1,41 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/data/order"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+)
+
+const (
+	orderByUserId   = "user_id"
+	orderByUsername = "username"
+	orderByEmail    = "email"
+	orderByRoles    = "roles"
+	orderByEnabled  = "enabled"
+)
+
+var orderByFields = map[string]string{
+	orderByUserId:   user.OrderByID,
+	orderByUsername: user.OrderByName,
+	orderByEmail:    user.OrderByEmail,
+	orderByRoles:    user.OrderByRoles,
+	orderByEnabled:  user.OrderByEnabled,
+}
+
+func parseOrder(r *http.Request) (order.OrderBy, error) {
+	orderBy, err := order.Parse(r, user.DefaultOrderBy)
+	if err != nil {
+		return order.OrderBy{}, err
+	}
+
+	if _, exists := orderByFields[orderBy.Field]; !exists {
+		return order.OrderBy{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
+	}
+
+	orderBy.Field = orderByFields[orderBy.Field]
+
+	return orderBy, nil
+}
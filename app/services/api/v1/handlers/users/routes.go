@@ -3,12 +3,19 @@ package users
 import (
 	"net/http"
 
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	"github.com/hpetrov29/resttemplate/business/core/apikey/stores/apikeysqldb"
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/role/stores/rolesqldb"
 	"github.com/hpetrov29/resttemplate/business/core/user"
 	"github.com/hpetrov29/resttemplate/business/core/user/stores/usersqldb"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/data/cache"
 	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
 	"github.com/hpetrov29/resttemplate/internal/idgenerator"
 	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/oidc"
 	"github.com/hpetrov29/resttemplate/internal/web"
 	"github.com/jmoiron/sqlx"
 )
@@ -19,6 +26,8 @@ type Config struct {
 	Auth  *auth.Auth
 	DB    *sqlx.DB
 	IdGen *idgenerator.IdGenerator
+	Cache cache.Cache
+	OIDC  []*oidc.Provider
 }
 
 // Routes initializes the required user specific repositories, service and handler,
@@ -30,16 +39,58 @@ type Config struct {
 func Routes(app *web.App, cfg Config) {
 	userRepository := usersqldb.NewStore(cfg.Log, cfg.DB)
 	userService := user.NewCore(userRepository, cfg.Log, cfg.IdGen)
-	handlers := New(userService, cfg.Auth)
+	roleService := role.NewCore(rolesqldb.NewStore(cfg.Log, cfg.DB), cfg.Log)
+	apiKeyService := apikey.NewCore(apikeysqldb.NewStore(cfg.Log, cfg.DB), cfg.Log, cfg.IdGen)
+	handlers := New(userService, roleService, apiKeyService, cfg.Auth)
+	handlers.oidc = newOIDCLogin(cfg.Cache, cfg.OIDC)
 
 	authenticated := middleware.Authenticate(cfg.Auth)
-	_ = middleware.Authorize(cfg.Auth, auth.RuleAdminOnly)
-	_ = middleware.Authorize(cfg.Auth, auth.RuleAdminOrSubject)
+	adminOnly := middleware.Authorize(cfg.Auth, auth.RuleAdminOnly)
+	canReadUsers := middleware.RequirePermission(cfg.Auth, role.PermUserRead)
+
+	// Tokens and API key secrets must never be kept by a cache.
+	noStore := middleware.CacheControl(web.CacheNoStore)
+
+	tags := []string{"users"}
+	adminTags := []string{"admin"}
+	queryParams := []string{"page", "rows", "orderBy", "user_id", "username", "email", "enabled", "start_created_date", "end_created_date"}
 
 	// NATIVE AUTH
-	app.Handle(http.MethodPost, "/users/token/{kid}", handlers.Signup)
-	app.Handle(http.MethodGet, "/users/token/{kid}", handlers.Login)
-	app.Handle(http.MethodGet, "/users/me", handlers.Me)
+	app.Handle(http.MethodPost, "/users/token/{kid}", handlers.Signup).
+		Describe(web.Doc{Summary: "Sign up and get a token", Tags: tags, Request: AppNewUser{}, Response: AppUser{}, Status: http.StatusCreated})
+	app.Handle(http.MethodGet, "/users/token/{kid}", handlers.Login, noStore).
+		Describe(web.Doc{Summary: "Log in with email and password", Tags: tags, Security: web.SecurityBasic, Response: token{}})
+	app.Handle(http.MethodGet, "/users/me", handlers.Me, noStore).
+		Describe(web.Doc{Summary: "Get the claims of the caller", Tags: tags, Security: web.SecurityBearer, Response: auth.Claims{}})
+
+	// OIDC AUTH
+	app.Handle(http.MethodGet, "/users/oidc/{provider}/login/{kid}", handlers.OIDCLogin).
+		Describe(web.Doc{Summary: "Redirect to an identity provider", Tags: tags, Status: http.StatusFound})
+	app.Handle(http.MethodGet, "/users/oidc/{provider}/callback", handlers.OIDCCallback).
+		Describe(web.Doc{Summary: "Complete a login with an identity provider", Tags: tags, Query: []string{"code", "state"}, Response: AppUser{}})
+
 	// PROTECTED ROUTES
-	app.Handle(http.MethodGet, "/users", handlers.ProtectedRoute, authenticated)
+	app.Handle(http.MethodGet, "/users", handlers.ProtectedRoute, authenticated).
+		Describe(web.Doc{Summary: "Check a token", Tags: tags, Security: web.SecurityBearer})
+
+	// API KEYS
+	// Managing keys requires a JWT, an API key cannot be used to mint others.
+	app.Handle(http.MethodGet, "/users/me/api-keys", handlers.QueryAPIKeys, authenticated, noStore).
+		Describe(web.Doc{Summary: "List the API keys of the caller", Tags: tags, Security: web.SecurityBearer, Response: []AppAPIKey{}})
+	app.Handle(http.MethodPost, "/users/me/api-keys", handlers.CreateAPIKey, authenticated, noStore).
+		Describe(web.Doc{Summary: "Create an API key", Tags: tags, Security: web.SecurityBearer, Request: AppNewAPIKey{}, Response: AppAPIKey{}, Status: http.StatusCreated})
+	app.Handle(http.MethodDelete, "/users/me/api-keys/{key_id}", handlers.DeleteAPIKey, authenticated).
+		Describe(web.Doc{Summary: "Revoke an API key", Tags: tags, Security: web.SecurityBearer, Status: http.StatusNoContent})
+
+	// ADMIN ROUTES
+	app.Handle(http.MethodGet, "/admin/users", handlers.QueryUsers, authenticated, canReadUsers).
+		Describe(web.Doc{Summary: "List users", Tags: adminTags, Security: web.SecurityBearer, Query: queryParams, Response: response.PageDocument[AppUser]{}})
+	app.Handle(http.MethodGet, "/admin/users/{id}", handlers.QueryUserById, authenticated, canReadUsers).
+		Describe(web.Doc{Summary: "Get a user", Tags: adminTags, Security: web.SecurityBearer, Response: AppUser{}})
+	app.Handle(http.MethodPut, "/admin/users/{id}/roles", handlers.UpdateRoles, authenticated, adminOnly).
+		Describe(web.Doc{Summary: "Replace the roles of a user", Tags: adminTags, Security: web.SecurityBearer, Request: AppUpdateRoles{}, Response: AppUser{}})
+	app.Handle(http.MethodPut, "/admin/users/{id}/enabled", handlers.UpdateEnabled, authenticated, adminOnly).
+		Describe(web.Doc{Summary: "Enable or disable a user", Tags: adminTags, Security: web.SecurityBearer, Request: AppUpdateEnabled{}, Response: AppUser{}})
+	app.Handle(http.MethodDelete, "/admin/users/{id}", handlers.DeleteUser, authenticated, adminOnly).
+		Describe(web.Doc{Summary: "Delete a user", Tags: adminTags, Security: web.SecurityBearer, Status: http.StatusNoContent})
 }
@@ -11,22 +11,30 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	"github.com/hpetrov29/resttemplate/business/core/role"
 	"github.com/hpetrov29/resttemplate/business/core/user"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
 	"github.com/hpetrov29/resttemplate/internal/web"
 )
 
 // Handlers manages the set of user endpoints.
 type Handlers struct {
-	user *user.Core
-	auth *auth.Auth
+	user   *user.Core
+	role   *role.Core
+	apiKey *apikey.Core
+	auth   *auth.Auth
+	oidc   *oidcLogin
 }
 
 // New constructs a new handlers struct for route access.
-func New(uc *user.Core, auth *auth.Auth) *Handlers {
+func New(uc *user.Core, rc *role.Core, kc *apikey.Core, auth *auth.Auth) *Handlers {
 	return &Handlers{
-		user: uc,
-		auth: auth,
+		user:   uc,
+		role:   rc,
+		apiKey: kc,
+		auth:   auth,
 	}
 }
 
@@ -34,7 +42,7 @@ func New(uc *user.Core, auth *auth.Auth) *Handlers {
 func (h *Handlers) Signup(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	var app AppNewUser
 	if err := web.Decode(r, &app); err != nil {
-		return web.Respond(ctx, w, http.StatusBadRequest, err)
+		return web.Respond(ctx, w, http.StatusBadRequest, response.InvalidBody(err))
 	}
 
 	nc, err := toCoreNewUser(app)
@@ -45,7 +53,7 @@ func (h *Handlers) Signup(ctx context.Context, w http.ResponseWriter, r *http.Re
 	usr, err := h.user.Create(ctx, nc)
 	if err != nil {
 		if errors.Is(err, user.ErrUniqueEmail) {
-			return web.Respond(ctx, w, http.StatusConflict, errors.New("email is already in use"))
+			return web.Respond(ctx, w, http.StatusConflict, web.NewError(response.CodeEmailInUse, errors.New("email is already in use")))
 		}
 		return web.Respond(ctx, w, http.StatusInternalServerError, err)
 	}
@@ -58,6 +66,8 @@ func (h *Handlers) Signup(ctx context.Context, w http.ResponseWriter, r *http.Re
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
+		Email: usr.Email.Address,
+		Roles: usr.Roles,
 	}
 
 	token, err := h.auth.GenerateToken(kid, claims)
@@ -77,7 +87,7 @@ func (h *Handlers) ProtectedRoute(ctx context.Context, w http.ResponseWriter, r
 func (h *Handlers) Login(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	kid := web.Param(r, "kid")
 	if kid == "" {
-		return errors.New("key id not provided")
+		return response.NewError(errors.New("key id not provided"), http.StatusBadRequest)
 	}
 
 	email, pass, ok := r.BasicAuth()
@@ -92,7 +102,10 @@ func (h *Handlers) Login(ctx context.Context, w http.ResponseWriter, r *http.Req
 
 	usr, err := h.user.Authenticate(ctx, *addr, pass)
 	if err != nil {
-		return web.Respond(ctx, w, http.StatusUnauthorized,  errors.New("invalid email or password"))
+		if errors.Is(err, user.ErrUserDisabled) {
+			return web.Respond(ctx, w, http.StatusForbidden, web.NewError(response.CodeAccountDisabled, errors.New("account is disabled")))
+		}
+		return web.Respond(ctx, w, http.StatusUnauthorized, web.NewError(response.CodeInvalidCredentials, errors.New("invalid email or password")))
 	}
 
 	claims := auth.Claims{
@@ -103,6 +116,7 @@ func (h *Handlers) Login(ctx context.Context, w http.ResponseWriter, r *http.Req
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
 		Email: email,
+		Roles: usr.Roles,
 	}
 	
 	token, err := h.auth.GenerateToken(kid, claims)
@@ -126,7 +140,7 @@ func (h *Handlers) Login(ctx context.Context, w http.ResponseWriter, r *http.Req
 func (h *Handlers) Me(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	claims, err := h.auth.Authenticate(ctx, r.Header.Get("authorization"))
 	if err != nil {
-		return web.Respond(ctx, w, http.StatusUnauthorized, err)
+		return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
 	}
 
 	return web.Respond(ctx, w, http.StatusOK, claims);
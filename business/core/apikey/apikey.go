@@ -0,0 +1,190 @@
+// Package apikey provides the core business API for the keys machine clients
+// use instead of a user's password.
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound   = errors.New("api key not found")
+	ErrExpired    = errors.New("api key has expired")
+	ErrInvalidKey = errors.New("api key is malformed")
+)
+
+// keyPrefix marks a secret as an API key of this service so it can be told
+// apart from a JWT and found by secret scanners.
+const keyPrefix = "rtk_"
+
+// Storer defines the methods required for storing and retrieving data from an api key specific repository.
+//
+// Implementation is found in business\core\apikey\stores\apikeysqldb\apikeysqldb.go
+type Storer interface {
+	Create(ctx context.Context, key APIKey) error
+	Delete(ctx context.Context, key APIKey) error
+	UpdateLastUsed(ctx context.Context, key APIKey) error
+	QueryById(ctx context.Context, id int64) (APIKey, error)
+	QueryByHash(ctx context.Context, hash string) (APIKey, error)
+	QueryByUserId(ctx context.Context, userId int64) ([]APIKey, error)
+}
+
+type IdGenerator interface {
+	GenerateId() (uint64, error)
+}
+
+// Core manages the set of APIs for api key access
+type Core struct {
+	storer      Storer
+	log         *logger.Logger
+	idGenerator IdGenerator
+}
+
+// NewCore constructs and returns a new Core instance for api key access.
+//
+// Parameters:
+//   - st: struct that implements the Storer interface for repository operations.
+//   - log: pointer to the logger used for logging within the core.
+//   - idGen: generator used for the ids of new keys.
+func NewCore(st Storer, log *logger.Logger, idGen IdGenerator) *Core {
+	return &Core{
+		storer:      st,
+		log:         log,
+		idGenerator: idGen,
+	}
+}
+
+// Create issues a new API key. The returned secret is the only time the full
+// key is available, only its hash is stored.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - nk: the details of the key to be created.
+//
+// Returns:
+//   - APIKey: the stored key.
+//   - string: the secret to hand to the client.
+//   - error: an error if the key could not be created.
+func (c *Core) Create(ctx context.Context, nk NewAPIKey) (APIKey, string, error) {
+	id, err := c.idGenerator.GenerateId()
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return APIKey{}, "", fmt.Errorf("generating secret: %w", err)
+	}
+
+	prefix := hex.EncodeToString(raw[:4])
+	secret := keyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(raw[4:])
+
+	key := APIKey{
+		Id:        int64(id),
+		UserId:    nk.UserId,
+		Name:      nk.Name,
+		Prefix:    keyPrefix + prefix,
+		Hash:      hash(secret),
+		Scopes:    nk.Scopes,
+		ExpiresAt: nk.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := c.storer.Create(ctx, key); err != nil {
+		return APIKey{}, "", fmt.Errorf("create: %w", err)
+	}
+
+	return key, secret, nil
+}
+
+// Delete revokes an API key.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - key: the key to be revoked.
+func (c *Core) Delete(ctx context.Context, key APIKey) error {
+	if err := c.storer.Delete(ctx, key); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// QueryById retrieves a single API key.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - id: the id of the key.
+func (c *Core) QueryById(ctx context.Context, id int64) (APIKey, error) {
+	key, err := c.storer.QueryById(ctx, id)
+	if err != nil {
+		return APIKey{}, fmt.Errorf("query: keyID[%d]: %w", id, err)
+	}
+
+	return key, nil
+}
+
+// QueryByUserId retrieves the API keys owned by a user.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - userId: the id of the owner.
+func (c *Core) QueryByUserId(ctx context.Context, userId int64) ([]APIKey, error) {
+	keys, err := c.storer.QueryByUserId(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%d]: %w", userId, err)
+	}
+
+	return keys, nil
+}
+
+// Authenticate looks up the key matching the secret, checks it has not
+// expired and records when it was last used.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - secret: the key presented by the client.
+func (c *Core) Authenticate(ctx context.Context, secret string) (APIKey, error) {
+	if !strings.HasPrefix(secret, keyPrefix) {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	key, err := c.storer.QueryByHash(ctx, hash(secret))
+	if err != nil {
+		return APIKey{}, fmt.Errorf("query: %w", err)
+	}
+
+	now := time.Now()
+	if key.Expired(now) {
+		return APIKey{}, ErrExpired
+	}
+
+	key.LastUsedAt = now
+	if err := c.storer.UpdateLastUsed(ctx, key); err != nil {
+		return APIKey{}, fmt.Errorf("update last used: %w", err)
+	}
+
+	return key, nil
+}
+
+// IsKey reports whether the value looks like an API key rather than a JWT.
+func IsKey(value string) bool {
+	return strings.HasPrefix(value, keyPrefix)
+}
+
+// hash returns the hex encoded sha256 of the secret. The secrets are random
+// and long enough that a slow password hash is not needed.
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
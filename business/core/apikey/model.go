@@ -0,0 +1,35 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/role"
+)
+
+// APIKey represents a key a user issued for a machine client. The key itself
+// is only known when it is created, afterwards only its hash is kept.
+type APIKey struct {
+	Id         int64
+	UserId     int64
+	Name       string
+	Prefix     string
+	Hash       string
+	Scopes     []role.Permission
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+}
+
+// Expired reports whether the key is past its expiry. Keys with a zero
+// ExpiresAt never expire.
+func (k APIKey) Expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// NewAPIKey contains the information needed to create a new API key.
+type NewAPIKey struct {
+	UserId    int64
+	Name      string
+	Scopes    []role.Permission
+	ExpiresAt time.Time
+}
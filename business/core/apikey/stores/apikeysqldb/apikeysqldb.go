@@ -0,0 +1,211 @@
+package apikeysqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	db "github.com/hpetrov29/resttemplate/business/data/dbsql/mysql"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for api key database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for interacting with a relational database.
+//
+// Parameters:
+//   - log: pointer to the logger used for logging within the store.
+//   - db: pointer to the database connection used by the store.
+//
+// Returns:
+//   - *Store: a pointer to the newly created Store instance.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new api key record into the database.
+//
+// Parameters:
+//   - ctx: the context for managing timeouts and cancellations.
+//   - k: the api key data to be stored in the database.
+//
+// Returns:
+//   - error: an error if the insertion fails.
+func (s *Store) Create(ctx context.Context, k apikey.APIKey) error {
+	const q = `
+	INSERT INTO api_keys
+		(id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at)
+	VALUES
+		(:id, :user_id, :name, :prefix, :key_hash, :scopes, :expires_at, :last_used_at, :created_at);`
+
+	if _, err := db.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(k)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes an api key record from the database.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - k: api key data containing the id of the key to be deleted.
+//
+// Returns:
+//   - error: an error if the deletion fails. If successful, returns nil.
+func (s *Store) Delete(ctx context.Context, k apikey.APIKey) error {
+	data := struct {
+		Id int64 `db:"id"`
+	}{
+		Id: k.Id,
+	}
+
+	const q = `
+	DELETE FROM
+		api_keys
+	WHERE
+		id = :id`
+
+	if _, err := db.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLastUsed records the time an api key was last used.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - k: api key data containing the id and last used time.
+//
+// Returns:
+//   - error: an error if the update fails.
+func (s *Store) UpdateLastUsed(ctx context.Context, k apikey.APIKey) error {
+	const q = `
+	UPDATE
+		api_keys
+	SET
+		last_used_at = :last_used_at
+	WHERE
+		id = :id`
+
+	if _, err := db.NamedExecContext(ctx, s.log, s.db, q, toDBAPIKey(k)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryById retrieves a single api key record.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - id: the id of the key.
+//
+// Returns:
+//   - apikey.APIKey: the key with the given id.
+//   - error: apikey.ErrNotFound if no such key exists or an error if the query fails.
+func (s *Store) QueryById(ctx context.Context, id int64) (apikey.APIKey, error) {
+	data := struct {
+		Id int64 `db:"id"`
+	}{
+		Id: id,
+	}
+
+	const q = `
+	SELECT
+		id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+	FROM
+		api_keys
+	WHERE
+		id = :id`
+
+	var dbK dbAPIKey
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbK); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return apikey.APIKey{}, fmt.Errorf("namedquerystruct: %w", apikey.ErrNotFound)
+		}
+		return apikey.APIKey{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreAPIKey(dbK), nil
+}
+
+// QueryByHash retrieves the api key record matching the hash of a secret.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - hash: the sha256 hash of the secret.
+//
+// Returns:
+//   - apikey.APIKey: the key with the given hash.
+//   - error: apikey.ErrNotFound if no such key exists or an error if the query fails.
+func (s *Store) QueryByHash(ctx context.Context, hash string) (apikey.APIKey, error) {
+	data := struct {
+		Hash string `db:"key_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+	SELECT
+		id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+	FROM
+		api_keys
+	WHERE
+		key_hash = :key_hash`
+
+	var dbK dbAPIKey
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbK); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return apikey.APIKey{}, fmt.Errorf("namedquerystruct: %w", apikey.ErrNotFound)
+		}
+		return apikey.APIKey{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreAPIKey(dbK), nil
+}
+
+// QueryByUserId retrieves the api key records owned by a user, newest first.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - userId: the id of the owner.
+//
+// Returns:
+//   - []apikey.APIKey: the keys of the user.
+//   - error: an error if the query fails.
+func (s *Store) QueryByUserId(ctx context.Context, userId int64) ([]apikey.APIKey, error) {
+	data := struct {
+		UserId int64 `db:"user_id"`
+	}{
+		UserId: userId,
+	}
+
+	const q = `
+	SELECT
+		id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+	FROM
+		api_keys
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		created_at DESC`
+
+	var dbKeys []dbAPIKey
+	if err := db.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbKeys); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreAPIKeySlice(dbKeys), nil
+}
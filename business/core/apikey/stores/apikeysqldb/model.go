@@ -0,0 +1,101 @@
+package apikeysqldb
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/data/dbsql/mysql/dbjson"
+)
+
+// dbAPIKey represents the structure used to transfer api key data
+// between the application and the database.
+type dbAPIKey struct {
+	Id         int64          `db:"id"`
+	UserId     int64          `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	Hash       string         `db:"key_hash"`
+	Scopes     dbjson.Strings `db:"scopes"`
+	ExpiresAt  sql.NullTime   `db:"expires_at"`
+	LastUsedAt sql.NullTime   `db:"last_used_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+// toDBAPIKey converts an apikey.APIKey instance (found in the service layer) to a dbAPIKey struct suited for database operations.
+//
+// Parameters:
+//   - k: the api key instance to be converted.
+func toDBAPIKey(k apikey.APIKey) dbAPIKey {
+	scopes := make([]string, len(k.Scopes))
+	for i, perm := range k.Scopes {
+		scopes[i] = perm.Name()
+	}
+
+	return dbAPIKey{
+		Id:         k.Id,
+		UserId:     k.UserId,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Hash:       k.Hash,
+		Scopes:     scopes,
+		ExpiresAt:  toNullTime(k.ExpiresAt),
+		LastUsedAt: toNullTime(k.LastUsedAt),
+		CreatedAt:  k.CreatedAt.UTC(),
+	}
+}
+
+// toCoreAPIKey converts a dbAPIKey instance (found in the repository layer) to an apikey.APIKey struct.
+// Scopes that are no longer part of the permission catalogue are dropped.
+//
+// Parameters:
+//   - dbK: the dbAPIKey instance to be converted.
+func toCoreAPIKey(dbK dbAPIKey) apikey.APIKey {
+	scopes := make([]role.Permission, 0, len(dbK.Scopes))
+	for _, value := range dbK.Scopes {
+		perm, err := role.ParsePermission(value)
+		if err != nil {
+			continue
+		}
+		scopes = append(scopes, perm)
+	}
+
+	return apikey.APIKey{
+		Id:         dbK.Id,
+		UserId:     dbK.UserId,
+		Name:       dbK.Name,
+		Prefix:     dbK.Prefix,
+		Hash:       dbK.Hash,
+		Scopes:     scopes,
+		ExpiresAt:  fromNullTime(dbK.ExpiresAt),
+		LastUsedAt: fromNullTime(dbK.LastUsedAt),
+		CreatedAt:  dbK.CreatedAt.In(time.Local),
+	}
+}
+
+// toCoreAPIKeySlice converts a slice of dbAPIKey instances to a slice of apikey.APIKey.
+//
+// Parameters:
+//   - dbKeys: the dbAPIKey instances to be converted.
+func toCoreAPIKeySlice(dbKeys []dbAPIKey) []apikey.APIKey {
+	keys := make([]apikey.APIKey, len(dbKeys))
+	for i, dbK := range dbKeys {
+		keys[i] = toCoreAPIKey(dbK)
+	}
+	return keys
+}
+
+func toNullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromNullTime(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time.In(time.Local)
+}
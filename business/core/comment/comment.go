@@ -17,6 +17,7 @@ var (
 type Storer interface {
 	Create(ctx context.Context, comment Comment) (sql.Result, error)
 	Delete(ctx context.Context, id uint64) error
+	QueryById(ctx context.Context, id int64) (Comment, error)
 	QueryByPostId(ctx context.Context, id int64) ([]Comment, error)
 }
 
@@ -75,6 +76,19 @@ func (c *Core) Delete(ctx context.Context, id uint64) error {
 	return nil
 }
 
+// QueryById retrieves a single comment by its id.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - id: the id of the comment.
+func (c *Core) QueryById(ctx context.Context, id int64) (Comment, error) {
+	comment, err := c.storer.QueryById(ctx, id)
+	if err != nil {
+		return Comment{}, fmt.Errorf("query: commentID[%d]: %w", id, err)
+	}
+	return comment, nil
+}
+
 func (c *Core) QueryByPostId(ctx context.Context, id int64) ([]Comment, error) {
 	comments, err := c.storer.QueryByPostId(ctx, id)
 	if err != nil {
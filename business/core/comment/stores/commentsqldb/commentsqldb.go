@@ -3,6 +3,7 @@ package commentsqldb
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/hpetrov29/resttemplate/business/core/comment"
@@ -86,6 +87,41 @@ func (s *Store) Delete(ctx context.Context, id uint64) (error) {
 	return nil
 }
 
+// QueryById fetches a single comment from the database.
+//
+// Parameters:
+//   - ctx: the context for managing timeouts and cancellations.
+//   - id: the id of the comment to be fetched.
+//
+// Returns:
+//   - Comment: the comment with the given id.
+//   - error: comment.ErrNotFound if no such comment exists or an error if the fetch fails.
+func (s *Store) QueryById(ctx context.Context, id int64) (comment.Comment, error) {
+	data := struct {
+		Id int64 `db:"id"`
+	}{
+		Id: id,
+	}
+
+	const q = `
+	SELECT
+		id, user_id, post_id, parent_id, content, created_at
+	FROM
+		comments
+	WHERE
+		id = :id;`
+
+	var dbComment Comment
+	if err := mysql.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbComment); err != nil {
+		if errors.Is(err, mysql.ErrDBNotFound) {
+			return comment.Comment{}, fmt.Errorf("namedquerystruct: %w", comment.ErrNotFound)
+		}
+		return comment.Comment{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return ToCoreComment(dbComment), nil
+}
+
 // QueryByPostId fetches the comment tree of the corresponding post from the database.
 // It fetches up to 5 root-level comments and, for each comment, up to 5 children recursively, 
 // to a maximum depth of 5 levels.
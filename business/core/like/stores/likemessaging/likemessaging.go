@@ -29,5 +29,5 @@ func (s *Store) Publish(ctx context.Context, l like.Like) error {
 		return err
 	}
 
-	return s.MessagingQueue.Publish(s.Subject, data)
+	return s.MessagingQueue.Publish(ctx, s.Subject, data)
 }
\ No newline at end of file
@@ -0,0 +1,190 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Limits of the images accepted. The size of an upload is limited by the
+// caller, these protect against small files decoding into huge images.
+const (
+	MaxDimension = 10000
+	MaxPixels    = 40_000_000
+)
+
+// jpegQuality is the quality JPEG images are encoded at.
+const jpegQuality = 85
+
+// format describes how a processed image is encoded.
+type format struct {
+	contentType string
+	ext         string
+}
+
+var (
+	formatJPEG = format{contentType: "image/jpeg", ext: ".jpg"}
+	formatPNG  = format{contentType: "image/png", ext: ".png"}
+)
+
+// decoder decodes one of the accepted image types.
+type decoder struct {
+	decode       func(io.Reader) (image.Image, error)
+	decodeConfig func(io.Reader) (image.Config, error)
+}
+
+// decoders maps the content types accepted to their decoder. The type of an
+// upload is detected from its content, the name and type sent by the client
+// are ignored.
+var decoders = map[string]decoder{
+	"image/jpeg": {decode: jpeg.Decode, decodeConfig: jpeg.DecodeConfig},
+	"image/png":  {decode: png.Decode, decodeConfig: png.DecodeConfig},
+	"image/gif":  {decode: decodeStaticGIF, decodeConfig: gif.DecodeConfig},
+	"image/webp": {decode: webp.Decode, decodeConfig: webp.DecodeConfig},
+}
+
+// processed is a decoded image and the format it is stored in.
+type processed struct {
+	image  image.Image
+	format format
+}
+
+// process detects the type of data and decodes it. JPEG images are rotated
+// according to their EXIF orientation. JPEG images are stored as JPEG, the
+// other types as PNG except opaque WebP images, which are photos more often
+// than not and stored as JPEG.
+func process(data []byte) (processed, error) {
+	contentType := http.DetectContentType(data)
+
+	dec, ok := decoders[contentType]
+	if !ok {
+		return processed{}, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	cfg, err := dec.decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return processed{}, fmt.Errorf("%w: %s", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > MaxDimension || cfg.Height > MaxDimension || cfg.Width*cfg.Height > MaxPixels {
+		return processed{}, fmt.Errorf("%w: %dx%d exceeds the limits of %d pixels per side and %d pixels", ErrInvalidImage, cfg.Width, cfg.Height, MaxDimension, MaxPixels)
+	}
+
+	img, err := dec.decode(bytes.NewReader(data))
+	if err != nil {
+		return processed{}, fmt.Errorf("%w: %s", ErrInvalidImage, err)
+	}
+
+	p := processed{image: img, format: formatPNG}
+
+	switch contentType {
+	case "image/jpeg":
+		p.image = orient(img, exifOrientation(data))
+		p.format = formatJPEG
+	case "image/webp":
+		if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+			p.format = formatJPEG
+		}
+	}
+
+	return p, nil
+}
+
+// encode encodes img in the format of p.
+func (p processed) encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch p.format {
+	case formatJPEG:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+	default:
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := enc.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeStaticGIF decodes a GIF, refusing animations which would be reduced
+// to their first frame.
+func decodeStaticGIF(r io.Reader) (image.Image, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.Image) != 1 {
+		return nil, fmt.Errorf("animated gifs are not supported")
+	}
+
+	return g.Image[0], nil
+}
+
+// resize scales img down to width, keeping its aspect ratio.
+func resize(img image.Image, width int) image.Image {
+	b := img.Bounds()
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, xdraw.Src, nil)
+
+	return dst
+}
+
+// orient returns img transformed so it displays upright, given its EXIF
+// orientation. Orientations 5 to 8 swap the width and height.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+
+	return dst
+}
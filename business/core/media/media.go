@@ -0,0 +1,151 @@
+// Package media provides the business logic of uploaded images, which posts
+// reference as their front image or in image blocks.
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/hpetrov29/resttemplate/business/data/storage"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+)
+
+// Set of error variables for media operations.
+var (
+	ErrNotFound        = errors.New("media not found")
+	ErrUnsupportedType = errors.New("unsupported media type")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+// ThumbnailWidths are the widths thumbnails are generated at. Images narrower
+// than a width get no thumbnail of that width.
+var ThumbnailWidths = []int{320, 960}
+
+// Media is a stored image. Keys are the storage keys of the files, the
+// original and the thumbnails are stored next to each other under the id.
+type Media struct {
+	Id          string
+	ContentType string
+	Width       int
+	Height      int
+	Size        int
+	Key         string
+	Thumbnails  []Thumbnail
+}
+
+// Thumbnail is a scaled down copy of a stored image.
+type Thumbnail struct {
+	Width  int
+	Height int
+	Size   int
+	Key    string
+}
+
+// Core manages the set of APIs for media access.
+type Core struct {
+	store storage.BlobStore
+	log   *logger.Logger
+}
+
+// NewCore constructs and returns a new Core instance for media access.
+//
+// Parameters:
+//   - store: the blob store the files are kept in.
+//   - log: pointer to the logger used for logging within the core.
+func NewCore(store storage.BlobStore, log *logger.Logger) *Core {
+	return &Core{
+		store: store,
+		log:   log,
+	}
+}
+
+// Upload processes and stores an uploaded image. The image is decoded and
+// encoded again, which drops EXIF and any other metadata after the EXIF
+// orientation has been applied to the pixels. The id is derived from the
+// uploaded bytes, uploading the same file twice yields the same media and
+// URLs, so they can be cached forever.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - data: the uploaded file.
+func (c *Core) Upload(ctx context.Context, data []byte) (Media, error) {
+	img, err := process(data)
+	if err != nil {
+		return Media{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	id := hex.EncodeToString(sum[:16])
+
+	original, err := img.encode(img.image)
+	if err != nil {
+		return Media{}, fmt.Errorf("encoding image: %w", err)
+	}
+
+	bounds := img.image.Bounds()
+	m := Media{
+		Id:          id,
+		ContentType: img.format.contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Size:        len(original),
+		Key:         id + "/original" + img.format.ext,
+	}
+
+	for _, width := range ThumbnailWidths {
+		if width >= m.Width {
+			continue
+		}
+
+		thumb := resize(img.image, width)
+		data, err := img.encode(thumb)
+		if err != nil {
+			return Media{}, fmt.Errorf("encoding thumbnail: %w", err)
+		}
+
+		t := Thumbnail{
+			Width:  thumb.Bounds().Dx(),
+			Height: thumb.Bounds().Dy(),
+			Size:   len(data),
+			Key:    fmt.Sprintf("%s/w%d%s", id, width, img.format.ext),
+		}
+		if err := c.store.Put(ctx, t.Key, data, img.format.contentType); err != nil {
+			return Media{}, fmt.Errorf("storing thumbnail: %w", err)
+		}
+		m.Thumbnails = append(m.Thumbnails, t)
+	}
+
+	// The original is stored last, a media whose original exists is
+	// complete.
+	if err := c.store.Put(ctx, m.Key, original, img.format.contentType); err != nil {
+		return Media{}, fmt.Errorf("storing original: %w", err)
+	}
+
+	return m, nil
+}
+
+// validFile matches the file names of the keys created by Upload.
+var validFile = regexp.MustCompile(`^[0-9a-f]{32}/(original|w[0-9]+)\.(jpg|png)$`)
+
+// Open returns the stored file of a media, id and file being the two
+// segments of its key. The caller must close the body of the object.
+func (c *Core) Open(ctx context.Context, id string, file string) (storage.Object, error) {
+	key := id + "/" + file
+	if !validFile.MatchString(key) {
+		return storage.Object{}, ErrNotFound
+	}
+
+	obj, err := c.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return storage.Object{}, ErrNotFound
+		}
+		return storage.Object{}, fmt.Errorf("open: %w", err)
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,207 @@
+package post
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hpetrov29/resttemplate/internal/validate"
+)
+
+// Set of block types a post content can be made of.
+const (
+	BlockParagraph = "paragraph"
+	BlockHeading   = "heading"
+	BlockImage     = "image"
+	BlockQuote     = "quote"
+	BlockCode      = "code"
+	BlockList      = "list"
+)
+
+// Set of style names a text range can be given.
+const (
+	StyleBold          = "bold"
+	StyleItalic        = "italic"
+	StyleUnderline     = "underline"
+	StyleStrikethrough = "strikethrough"
+	StyleCode          = "code"
+	StyleLink          = "link"
+)
+
+// Set of limits applied to the content of a post.
+const (
+	MaxBlocks        = 500
+	MaxStyles        = 200
+	MaxCaptionLength = 300
+	MaxURLLength     = 2048
+)
+
+// Set of fields of a block, used to describe the fields each block type
+// requires and allows.
+const (
+	fieldContent = "content"
+	fieldStyles  = "styles"
+	fieldURL     = "url"
+	fieldCaption = "caption"
+)
+
+// blockType describes the fields a block of a given type must and may have.
+// maxLength is the maximum number of runes of the content of the block.
+type blockType struct {
+	required  []string
+	allowed   []string
+	maxLength int
+}
+
+// blockTypes is the registry of the block types. Blocks of any other type are
+// refused. List blocks hold one item per line of their content.
+var blockTypes = map[string]blockType{
+	BlockParagraph: {required: []string{fieldContent}, allowed: []string{fieldStyles}, maxLength: 10000},
+	BlockHeading:   {required: []string{fieldContent}, allowed: []string{fieldStyles}, maxLength: 300},
+	BlockQuote:     {required: []string{fieldContent}, allowed: []string{fieldStyles, fieldCaption}, maxLength: 5000},
+	BlockCode:      {required: []string{fieldContent}, maxLength: 20000},
+	BlockList:      {required: []string{fieldContent}, allowed: []string{fieldStyles}, maxLength: 10000},
+	BlockImage:     {required: []string{fieldURL}, allowed: []string{fieldCaption}},
+}
+
+// styleNames is the set of style names a text range can be given.
+var styleNames = map[string]bool{
+	StyleBold:          true,
+	StyleItalic:        true,
+	StyleUnderline:     true,
+	StyleStrikethrough: true,
+	StyleCode:          true,
+	StyleLink:          true,
+}
+
+// BlockTypes returns the names of the registered block types.
+func BlockTypes() []string {
+	return []string{BlockParagraph, BlockHeading, BlockImage, BlockQuote, BlockCode, BlockList}
+}
+
+// ValidateContent checks the blocks of a post content against the registry of
+// block types. Every problem found is reported as a field error with the path
+// of the offending value, e.g. content.blocks[3].styles[0].
+func ValidateContent(c Content) error {
+	var fields validate.FieldErrors
+
+	add := func(path string, err error) {
+		fields = append(fields, validate.FieldError{Field: path, Err: err.Error()})
+	}
+
+	switch {
+	case len(c.Blocks) == 0:
+		add("content.blocks", errors.New("at least one block is required"))
+	case len(c.Blocks) > MaxBlocks:
+		add("content.blocks", fmt.Errorf("at most %d blocks are allowed", MaxBlocks))
+	}
+
+	for i, b := range c.Blocks {
+		validateBlock(fmt.Sprintf("content.blocks[%d]", i), b, add)
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	return nil
+}
+
+// validateBlock checks a single block, reporting problems through add.
+func validateBlock(path string, b Block, add func(string, error)) {
+	bt, exists := blockTypes[b.Type]
+	if !exists {
+		add(path+".type", fmt.Errorf("unknown block type %q, must be one of %s", b.Type, strings.Join(BlockTypes(), ", ")))
+		return
+	}
+
+	set := map[string]bool{
+		fieldContent: b.Content != "",
+		fieldStyles:  len(b.Styles) > 0,
+		fieldURL:     b.URL != "",
+		fieldCaption: b.Caption != "",
+	}
+
+	for _, f := range bt.required {
+		if !set[f] {
+			add(path+"."+f, fmt.Errorf("%s is required for %s blocks", f, b.Type))
+		}
+		set[f] = false
+	}
+	for _, f := range bt.allowed {
+		set[f] = false
+	}
+	for _, f := range []string{fieldContent, fieldStyles, fieldURL, fieldCaption} {
+		if set[f] {
+			add(path+"."+f, fmt.Errorf("%s is not allowed for %s blocks", f, b.Type))
+		}
+	}
+
+	length := utf8.RuneCountInString(b.Content)
+	if bt.maxLength > 0 && length > bt.maxLength {
+		add(path+".content", fmt.Errorf("must be at most %d characters", bt.maxLength))
+	}
+
+	if utf8.RuneCountInString(b.Caption) > MaxCaptionLength {
+		add(path+".caption", fmt.Errorf("must be at most %d characters", MaxCaptionLength))
+	}
+
+	if b.URL != "" {
+		if err := validateURL(b.URL); err != nil {
+			add(path+".url", err)
+		}
+	}
+
+	if len(b.Styles) > MaxStyles {
+		add(path+".styles", fmt.Errorf("at most %d styles are allowed", MaxStyles))
+		return
+	}
+
+	for i, s := range b.Styles {
+		if err := validateStyle(s, length); err != nil {
+			add(fmt.Sprintf("%s.styles[%d]", path, i), err)
+		}
+	}
+}
+
+// validateStyle checks that a style has a known name and that its range lies
+// within a content of length runes. Only link styles carry a URL.
+func validateStyle(s Style, length int) error {
+	switch {
+	case !styleNames[s.Style]:
+		return fmt.Errorf("unknown style %q", s.Style)
+	case s.Style == StyleLink:
+		if err := validateURL(s.URL); err != nil {
+			return fmt.Errorf("url %s", err)
+		}
+	case s.URL != "":
+		return fmt.Errorf("url is not allowed for %s styles", s.Style)
+	}
+
+	switch {
+	case s.Offset < 0:
+		return errors.New("offset must not be negative")
+	case s.Length < 1:
+		return errors.New("length must be positive")
+	case s.Offset+s.Length > length:
+		return fmt.Errorf("range [%d, %d) exceeds the content length of %d", s.Offset, s.Offset+s.Length, length)
+	}
+
+	return nil
+}
+
+// validateURL checks that rawURL is an absolute http or https URL.
+func validateURL(rawURL string) error {
+	if len(rawURL) > MaxURLLength {
+		return fmt.Errorf("must be at most %d characters", MaxURLLength)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an absolute http or https URL")
+	}
+
+	return nil
+}
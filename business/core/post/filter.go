@@ -1,17 +1,29 @@
 package post
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/hpetrov29/resttemplate/internal/validate"
 )
 
-// QueryFilter holds the available fields a query can be filtered on.
+// QueryFilter holds the available fields a query can be filtered on. All
+// the fields set must match. Ranges include both of their ends.
 type QueryFilter struct {
-	UserId      *uint64
-	CreatedAt *time.Time
-	UpdatedAt *time.Time
+	UserIds        []uint64   `json:"user_id" validate:"omitempty,max=50"`
+	ExcludeUserIds []uint64   `json:"exclude_user_id" validate:"omitempty,max=50"`
+	ExcludeIds     []int64    `json:"exclude_id" validate:"omitempty,max=100"`
+	Title          *string    `json:"title" validate:"omitempty,min=2,max=255"`
+	Description    *string    `json:"description" validate:"omitempty,min=2,max=300"`
+	StartCreatedAt *time.Time `json:"start_created_at" validate:"omitempty"`
+	EndCreatedAt   *time.Time `json:"end_created_at" validate:"omitempty"`
+	StartUpdatedAt *time.Time `json:"start_updated_at" validate:"omitempty"`
+	EndUpdatedAt   *time.Time `json:"end_updated_at" validate:"omitempty"`
+	Status         *string    `json:"status" validate:"omitempty,oneof=draft scheduled published archived"`
+	PublishBefore  *time.Time `json:"publish_before" validate:"omitempty"`
+	MinReadingTime *int       `json:"min_reading_time" validate:"omitempty,min=0"`
+	MaxReadingTime *int       `json:"max_reading_time" validate:"omitempty,min=0"`
 }
 
 // Validate checks the data in the model is considered clean.
@@ -19,20 +31,96 @@ func (qf *QueryFilter) Validate() error {
 	if err := validate.Check(qf); err != nil {
 		return fmt.Errorf("validate: %w", err)
 	}
+
+	if qf.StartCreatedAt != nil && qf.EndCreatedAt != nil && qf.EndCreatedAt.Before(*qf.StartCreatedAt) {
+		return validate.NewFieldsError("end_created_at", errors.New("end_created_at must not be before start_created_at"))
+	}
+
+	if qf.StartUpdatedAt != nil && qf.EndUpdatedAt != nil && qf.EndUpdatedAt.Before(*qf.StartUpdatedAt) {
+		return validate.NewFieldsError("end_updated_at", errors.New("end_updated_at must not be before start_updated_at"))
+	}
+
+	if qf.MinReadingTime != nil && qf.MaxReadingTime != nil && *qf.MinReadingTime > *qf.MaxReadingTime {
+		return validate.NewFieldsError("max_reading_time", errors.New("max_reading_time must not be below min_reading_time"))
+	}
+
 	return nil
 }
 
-// WithUserId is used to filter posts of a specific userId
+// WithUserId is used to filter posts of a specific userId, replacing any
+// other author the filter had
 func (qf *QueryFilter) WithUserId(userId uint64) {
-	qf.UserId = &userId
+	qf.UserIds = []uint64{userId}
+}
+
+// WithUserIds is used to filter posts of any of a set of userIds
+func (qf *QueryFilter) WithUserIds(userIds []uint64) {
+	qf.UserIds = userIds
+}
+
+// WithExcludeUserIds is used to leave out the posts of a set of userIds
+func (qf *QueryFilter) WithExcludeUserIds(userIds []uint64) {
+	qf.ExcludeUserIds = userIds
+}
+
+// WithExcludeIds is used to leave out a set of posts
+func (qf *QueryFilter) WithExcludeIds(ids []int64) {
+	qf.ExcludeIds = ids
+}
+
+// WithTitle is used to filter posts whose title contains a text
+func (qf *QueryFilter) WithTitle(title string) {
+	qf.Title = &title
+}
+
+// WithDescription is used to filter posts whose description contains a text
+func (qf *QueryFilter) WithDescription(description string) {
+	qf.Description = &description
+}
+
+// WithStartCreatedAt is used to filter posts created at or after a time
+func (qf *QueryFilter) WithStartCreatedAt(startCreatedAt time.Time) {
+	t := startCreatedAt.UTC()
+	qf.StartCreatedAt = &t
+}
+
+// WithEndCreatedAt is used to filter posts created at or before a time
+func (qf *QueryFilter) WithEndCreatedAt(endCreatedAt time.Time) {
+	t := endCreatedAt.UTC()
+	qf.EndCreatedAt = &t
+}
+
+// WithStartUpdatedAt is used to filter posts updated at or after a time
+func (qf *QueryFilter) WithStartUpdatedAt(startUpdatedAt time.Time) {
+	t := startUpdatedAt.UTC()
+	qf.StartUpdatedAt = &t
+}
+
+// WithEndUpdatedAt is used to filter posts updated at or before a time
+func (qf *QueryFilter) WithEndUpdatedAt(endUpdatedAt time.Time) {
+	t := endUpdatedAt.UTC()
+	qf.EndUpdatedAt = &t
+}
+
+// WithStatus is used to filter posts in a specific status
+func (qf *QueryFilter) WithStatus(status string) {
+	qf.Status = &status
+}
+
+// WithPublishBefore is used to filter posts whose publication time is not
+// after a specific time
+func (qf *QueryFilter) WithPublishBefore(publishBefore time.Time) {
+	qf.PublishBefore = &publishBefore
 }
 
-// WithCreatedAt is used to filter posts on a specific creation time
-func (qf *QueryFilter) WithCreatedAt(createdAt time.Time) {
-	qf.CreatedAt = &createdAt
+// WithMinReadingTime is used to filter posts taking at least a number of
+// minutes to read
+func (qf *QueryFilter) WithMinReadingTime(minutes int) {
+	qf.MinReadingTime = &minutes
 }
 
-// WithUpdatedAt is used to filter posts on a specific update time
-func (qf *QueryFilter) WithUpdatedAt(updatedAt time.Time) {
-	qf.UpdatedAt = &updatedAt
+// WithMaxReadingTime is used to filter posts taking at most a number of
+// minutes to read
+func (qf *QueryFilter) WithMaxReadingTime(minutes int) {
+	qf.MaxReadingTime = &minutes
 }
@@ -12,27 +12,45 @@ type Post struct {
 	Id          int64
 	UserId      int64
 	Title       string
+	Slug        string
 	Description string
 	FrontImage  string
 	ContentId   int64
 	Content 	Content
+	Stats       Stats
+	Status      string
+	PublishAt   time.Time
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
+// Public reports whether everyone can read the post, other posts are only
+// visible to their author.
+func (p Post) Public() bool {
+	return p.Status == StatusPublished
+}
+
 // NewPost contains information required to create a new post
 // Meant to be used at the service/core layer
 type NewPost struct {
 	UserId      int64
 	Title       string
 	Description string
+	FrontImage  string
 	Content 	Content
+	Status      string
+	PublishAt   time.Time
 }
 
 // UpdatePost contains information required to update a post
 // Meant to be used at the service/core layer
 type UpdatePost struct {
-	
+	Title       *string
+	Description *string
+	FrontImage  *string
+	Content     *Content
+	Status      *string
+	PublishAt   *time.Time
 }
 
 // =============================================================================
@@ -52,9 +70,11 @@ type Block struct {
 	Caption string
 }
 
-// Style contains text styling information (e.g., bold, italic), offset and length
+// Style contains text styling information (e.g., bold, italic), offset and length.
+// URL is the target of link styles.
 type Style struct {
 	Offset int
 	Length int
 	Style  string
+	URL    string
 }
\ No newline at end of file
@@ -1,10 +1,15 @@
 package post
 
+import "github.com/hpetrov29/resttemplate/business/data/order"
+
 // DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByCreatedAt, order.ASC)
 
 // Set of fields that the results can be ordered by. These are the names
 // that should be used by the application layer.
 const (
 	OrderByCreatedAt = "created_at"
 	OrderByUpdatedAt = "updated_at"
+	OrderByPublishAt = "publish_at"
+	OrderByReadingTime = "reading_time"
 )
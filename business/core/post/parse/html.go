@@ -0,0 +1,345 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// inlineStyles maps the inline elements to the style they apply.
+var inlineStyles = map[atom.Atom]string{
+	atom.Strong: post.StyleBold,
+	atom.B:      post.StyleBold,
+	atom.Em:     post.StyleItalic,
+	atom.I:      post.StyleItalic,
+	atom.U:      post.StyleUnderline,
+	atom.S:      post.StyleStrikethrough,
+	atom.Del:    post.StyleStrikethrough,
+	atom.Strike: post.StyleStrikethrough,
+	atom.Code:   post.StyleCode,
+}
+
+// containers are the elements whose children are parsed as blocks, the
+// element itself has no equivalent and is ignored.
+var containers = map[atom.Atom]bool{
+	atom.Html:    true,
+	atom.Body:    true,
+	atom.Div:     true,
+	atom.Section: true,
+	atom.Article: true,
+	atom.Main:    true,
+	atom.Header:  true,
+	atom.Footer:  true,
+}
+
+// HTML parses an HTML document or fragment. Headings, paragraphs, block
+// quotes, lists, preformatted text and images become blocks, the elements
+// for bold, italic, underlined, struck through and code text and links become
+// styles. Any other element, scripts included, and links or images to other
+// than http and https URLs are reported in an UnsupportedError. Text is only
+// ever extracted, no markup of the source ends up in the content.
+func HTML(src string) (post.Content, error) {
+	root, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return post.Content{}, fmt.Errorf("parsing html: %w", err)
+	}
+
+	var d document
+	htmlBlocks(&d, root)
+
+	return d.result()
+}
+
+// htmlBlocks adds the blocks found among the children of n. Runs of inline
+// content between block elements form paragraphs.
+func htmlBlocks(d *document, n *html.Node) {
+	var para text
+	flush := func() {
+		d.add(para.block(post.BlockParagraph))
+		para = text{}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			if c.Type == html.TextNode {
+				htmlInline(d, &para, c)
+			}
+			continue
+		}
+
+		switch {
+		case c.DataAtom == atom.Head:
+			htmlHead(d, c)
+			continue
+		case containers[c.DataAtom]:
+			flush()
+			htmlBlocks(d, c)
+			continue
+		}
+
+		switch c.DataAtom {
+		case atom.P:
+			flush()
+			d.add(htmlTextBlock(d, post.BlockParagraph, c))
+
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			flush()
+			d.add(htmlTextBlock(d, post.BlockHeading, c))
+
+		case atom.Blockquote:
+			flush()
+			htmlQuote(d, c)
+
+		case atom.Pre:
+			flush()
+			d.add(post.Block{Type: post.BlockCode, Content: strings.Trim(textContent(c), "\n")})
+
+		case atom.Ul, atom.Ol:
+			flush()
+			htmlList(d, c)
+
+		case atom.Figure:
+			flush()
+			htmlFigure(d, c)
+
+		case atom.Img:
+			flush()
+			htmlImage(d, c, attr(c, "alt"))
+
+		default:
+			if _, ok := inlineStyles[c.DataAtom]; ok || c.DataAtom == atom.A || c.DataAtom == atom.Span || c.DataAtom == atom.Br {
+				htmlInline(d, &para, c)
+				continue
+			}
+			flush()
+			d.report(0, "<%s> element", c.Data)
+		}
+	}
+
+	flush()
+}
+
+// htmlHead reports the scripts and styles of the head of a document, the
+// parser moves those found before the content of a fragment there. The rest
+// of the head holds no content and is skipped.
+func htmlHead(d *document, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom == atom.Script || c.DataAtom == atom.Style {
+			d.report(0, "<%s> element", c.Data)
+		}
+	}
+}
+
+// htmlTextBlock returns a block of type typ holding the inline content of n.
+func htmlTextBlock(d *document, typ string, n *html.Node) post.Block {
+	var t text
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		htmlInline(d, &t, c)
+	}
+
+	return t.block(typ)
+}
+
+// htmlQuote adds a quote block. Paragraphs of the quote are separated by line
+// breaks, a footer or cite element becomes the caption.
+func htmlQuote(d *document, n *html.Node) {
+	var t text
+	var caption string
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.DataAtom {
+		case atom.Footer, atom.Cite:
+			caption = collapse(textContent(c))
+		case atom.P:
+			if t.runes > 0 {
+				t.write("\n")
+			}
+			for gc := c.FirstChild; gc != nil; gc = gc.NextSibling {
+				htmlInline(d, &t, gc)
+			}
+		default:
+			htmlInline(d, &t, c)
+		}
+	}
+
+	b := t.block(post.BlockQuote)
+	b.Caption = caption
+	d.add(b)
+}
+
+// htmlList adds a list block, one line per item.
+func htmlList(d *document, n *html.Node) {
+	var t text
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.DataAtom == atom.Li:
+			if t.runes > 0 {
+				t.write("\n")
+			}
+			var item text
+			for gc := c.FirstChild; gc != nil; gc = gc.NextSibling {
+				if gc.DataAtom == atom.Ul || gc.DataAtom == atom.Ol {
+					d.report(0, "nested list")
+					continue
+				}
+				htmlInline(d, &item, gc)
+			}
+			b := item.block("")
+			start := t.runes
+			t.write(strings.ReplaceAll(b.Content, "\n", " "))
+			for _, s := range b.Styles {
+				t.styles = append(t.styles, post.Style{Offset: start + s.Offset, Length: s.Length, Style: s.Style, URL: s.URL})
+			}
+		case c.Type == html.ElementNode:
+			d.report(0, "<%s> element inside a list", c.Data)
+		}
+	}
+
+	d.add(t.block(post.BlockList))
+}
+
+// htmlFigure adds the image of a figure, captioned by its figcaption.
+func htmlFigure(d *document, n *html.Node) {
+	var img *html.Node
+	var caption string
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.DataAtom == atom.Img && img == nil:
+			img = c
+		case c.DataAtom == atom.Figcaption:
+			caption = collapse(textContent(c))
+		case c.Type == html.ElementNode:
+			d.report(0, "<%s> element inside a figure", c.Data)
+		}
+	}
+
+	if img == nil {
+		d.report(0, "figure without an image")
+		return
+	}
+	if caption == "" {
+		caption = attr(img, "alt")
+	}
+
+	htmlImage(d, img, caption)
+}
+
+// htmlImage adds an image block.
+func htmlImage(d *document, n *html.Node, caption string) {
+	src := attr(n, "src")
+	if !safeURL(src) {
+		d.report(0, "image %q, only http and https images are allowed", src)
+		return
+	}
+
+	d.add(post.Block{Type: post.BlockImage, URL: src, Caption: caption})
+}
+
+// htmlInline writes the inline content of n into t. Whitespace is collapsed
+// like browsers do, only line break elements produce line breaks.
+func htmlInline(d *document, t *text, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		s := collapse(n.Data)
+		if strings.HasSuffix(t.sb.String(), " ") || strings.HasSuffix(t.sb.String(), "\n") {
+			s = strings.TrimLeft(s, " ")
+		}
+		t.write(s)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	start := t.runes
+
+	switch {
+	case n.DataAtom == atom.Br:
+		t.write("\n")
+		return
+
+	case n.DataAtom == atom.A:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			htmlInline(d, t, c)
+		}
+		href := attr(n, "href")
+		if !safeURL(href) {
+			d.report(0, "link to %q, only http and https links are allowed", href)
+			return
+		}
+		t.style(post.StyleLink, start, href)
+		return
+
+	case n.DataAtom == atom.Span:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			htmlInline(d, t, c)
+		}
+		return
+	}
+
+	name, ok := inlineStyles[n.DataAtom]
+	if !ok {
+		d.report(0, "<%s> element", n.Data)
+		return
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		htmlInline(d, t, c)
+	}
+	t.style(name, start, "")
+}
+
+// textContent returns the text of n and its descendants as is.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom == atom.Br {
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(textContent(c))
+	}
+
+	return sb.String()
+}
+
+// attr returns the value of the attribute key of n.
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// collapse replaces runs of whitespace with a single space.
+func collapse(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			return " "
+		}
+		return ""
+	}
+
+	result := strings.Join(fields, " ")
+	if strings.TrimLeft(s, " \t\n\r\f") != s {
+		result = " " + result
+	}
+	if strings.TrimRight(s, " \t\n\r\f") != s {
+		result += " "
+	}
+
+	return result
+}
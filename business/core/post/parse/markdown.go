@@ -0,0 +1,516 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// Set of patterns recognizing the block level constructs of CommonMark.
+var (
+	atxHeading      = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextUnderline = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	thematicBreak   = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceStart      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	listItem        = regexp.MustCompile(`^( *)(?:[-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	quoteLine       = regexp.MustCompile(`^ {0,3}> ?`)
+	imageLine       = regexp.MustCompile(`^ {0,3}!\[([^\]]*)\]\(\s*<?([^\s<>()]+)>?(?:\s+"([^"]*)")?\s*\)\s*$`)
+	tableRow        = regexp.MustCompile(`^ {0,3}\|`)
+	htmlBlock       = regexp.MustCompile(`^ {0,3}<[a-zA-Z!/?]`)
+	indentedCode    = regexp.MustCompile(`^(?: {4}|\t)`)
+)
+
+// Set of patterns recognizing inline constructs.
+var (
+	autolink  = regexp.MustCompile(`^<(https?://[^\s<>]+)>`)
+	inlineTag = regexp.MustCompile(`^</?[a-zA-Z][a-zA-Z0-9-]*(?:\s[^<>]*)?/?>`)
+)
+
+// quoteCaption prefixes the line of a quote holding its caption, as written
+// by render.Markdown.
+const quoteCaption = "— "
+
+// Markdown parses a CommonMark document. Headings, paragraphs, quotes, lists,
+// code blocks and images on a line of their own become blocks, emphasis,
+// strong emphasis, strikethrough, code spans and links become styles.
+// Tables, thematic breaks, raw HTML, nested lists, inline images and links
+// to other than http and https URLs are reported in an UnsupportedError.
+func Markdown(src string) (post.Content, error) {
+	var d document
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	var para []string
+	var paraLine int
+	flush := func() {
+		if len(para) > 0 {
+			d.add(markdownBlock(&d, post.BlockParagraph, para, paraLine))
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line, n := lines[i], i+1
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+
+		case fenceStart.MatchString(line):
+			flush()
+			fence := strings.TrimLeft(fenceStart.FindString(line), " ")
+			var code []string
+			for i++; i < len(lines); i++ {
+				l := strings.TrimLeft(lines[i], " ")
+				if strings.HasPrefix(l, fence) && strings.TrimSpace(strings.TrimLeft(l, fence[:1])) == "" {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			d.add(post.Block{Type: post.BlockCode, Content: strings.Join(code, "\n")})
+
+		case len(para) > 0 && setextUnderline.MatchString(line):
+			d.add(markdownBlock(&d, post.BlockHeading, para, paraLine))
+			para = nil
+
+		case thematicBreak.MatchString(line):
+			flush()
+			d.report(n, "thematic break")
+
+		case atxHeading.MatchString(line):
+			flush()
+			d.add(markdownBlock(&d, post.BlockHeading, []string{atxHeading.FindStringSubmatch(line)[1]}, n))
+
+		case imageLine.MatchString(line):
+			flush()
+			m := imageLine.FindStringSubmatch(line)
+			if !safeURL(m[2]) {
+				d.report(n, "image %q, only http and https images are allowed", m[2])
+				continue
+			}
+			caption := m[3]
+			if caption == "" {
+				caption = unescape(m[1])
+			}
+			d.add(post.Block{Type: post.BlockImage, URL: m[2], Caption: caption})
+
+		case quoteLine.MatchString(line):
+			flush()
+			start := i
+			for ; i < len(lines) && quoteLine.MatchString(lines[i]); i++ {
+			}
+			i--
+			markdownQuote(&d, lines[start:i+1], start+1)
+
+		case listItem.MatchString(line):
+			flush()
+			start := i
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+			}
+			i--
+			markdownList(&d, lines[start:i+1], start+1)
+
+		case indentedCode.MatchString(line) && len(para) == 0:
+			var code []string
+			for ; i < len(lines) && (indentedCode.MatchString(lines[i]) || strings.TrimSpace(lines[i]) == ""); i++ {
+				code = append(code, strings.TrimPrefix(strings.TrimPrefix(lines[i], "\t"), "    "))
+			}
+			i--
+			d.add(post.Block{Type: post.BlockCode, Content: strings.TrimRight(strings.Join(code, "\n"), "\n")})
+
+		case tableRow.MatchString(line):
+			flush()
+			d.report(n, "table")
+			for i+1 < len(lines) && tableRow.MatchString(lines[i+1]) {
+				i++
+			}
+
+		case htmlBlock.MatchString(line) && len(para) == 0:
+			d.report(n, "HTML block")
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+				i++
+			}
+
+		default:
+			if len(para) == 0 {
+				paraLine = n
+			}
+			para = append(para, line)
+		}
+	}
+	flush()
+
+	return d.result()
+}
+
+// markdownQuote adds a quote block made of the lines of a block quote. Blank
+// lines of the quote separate its paragraphs, a last paragraph starting with
+// a dash becomes the caption.
+func markdownQuote(d *document, lines []string, firstLine int) {
+	var paras [][]string
+	var current []string
+	for _, l := range lines {
+		l = quoteLine.ReplaceAllString(l, "")
+		if strings.TrimSpace(l) == "" {
+			if len(current) > 0 {
+				paras = append(paras, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, l)
+	}
+	if len(current) > 0 {
+		paras = append(paras, current)
+	}
+
+	var caption string
+	if n := len(paras); n > 1 && len(paras[n-1]) == 1 && strings.HasPrefix(strings.TrimSpace(paras[n-1][0]), quoteCaption) {
+		caption = unescape(strings.TrimPrefix(strings.TrimSpace(paras[n-1][0]), quoteCaption))
+		paras = paras[:n-1]
+	}
+
+	var joined []string
+	for i, p := range paras {
+		if i > 0 {
+			joined[len(joined)-1] += `\`
+		}
+		joined = append(joined, p...)
+	}
+
+	b := markdownBlock(d, post.BlockQuote, joined, firstLine)
+	b.Caption = caption
+	d.add(b)
+}
+
+// markdownList adds a list block made of the items of a list. Lines that do
+// not start an item continue the previous one.
+func markdownList(d *document, lines []string, firstLine int) {
+	var t text
+	p := inline{d: d, t: &t}
+
+	var items [][]string
+	for i, l := range lines {
+		m := listItem.FindStringSubmatch(l)
+		switch {
+		case m == nil && len(items) > 0:
+			items[len(items)-1] = append(items[len(items)-1], l)
+			continue
+		case m == nil:
+			items = append(items, []string{l})
+			continue
+		case len(m[1]) >= 2:
+			d.report(firstLine+i, "nested list")
+			continue
+		}
+		items = append(items, []string{m[2]})
+	}
+
+	for i, item := range items {
+		if i > 0 {
+			t.write("\n")
+		}
+		p.line = firstLine
+		p.parse(joinLines(item))
+	}
+
+	d.add(t.block(post.BlockList))
+}
+
+// markdownBlock parses the lines of a block holding inline content.
+func markdownBlock(d *document, typ string, lines []string, firstLine int) post.Block {
+	var t text
+	p := inline{d: d, t: &t, line: firstLine}
+	p.parse(joinLines(lines))
+
+	return t.block(typ)
+}
+
+// joinLines joins the lines of a block. Lines ending with a backslash or two
+// spaces end with a hard line break, the others with a space.
+func joinLines(lines []string) string {
+	var sb strings.Builder
+	for i, l := range lines {
+		l = strings.TrimLeft(l, " \t")
+		last := i == len(lines)-1
+
+		switch {
+		case last:
+			sb.WriteString(strings.TrimRight(l, " \t"))
+		case strings.HasSuffix(l, `\`) && !strings.HasSuffix(l, `\\`):
+			sb.WriteString(strings.TrimSuffix(l, `\`) + "\n")
+		case strings.HasSuffix(l, "  "):
+			sb.WriteString(strings.TrimRight(l, " ") + "\n")
+		default:
+			sb.WriteString(strings.TrimRight(l, " \t") + " ")
+		}
+	}
+
+	return sb.String()
+}
+
+// =============================================================================
+
+// inline parses the inline content of a block into a text.
+type inline struct {
+	d    *document
+	t    *text
+	line int
+}
+
+// parse writes the text of s, applying the styles of its inline constructs.
+func (p *inline) parse(s string) {
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && isPunct(s[i+1]) {
+				p.t.write(s[i+1 : i+2])
+				i += 2
+				continue
+			}
+
+		case '`':
+			n := run(s, i)
+			if end := findRun(s, i+n, '`', n); end >= 0 {
+				code := strings.ReplaceAll(s[i+n:end], "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				start := p.t.runes
+				p.t.write(code)
+				p.t.style(post.StyleCode, start, "")
+				i = end + n
+				continue
+			}
+			p.t.write(s[i : i+n])
+			i += n
+			continue
+
+		case '*', '_', '~':
+			if next, ok := p.emphasis(s, i); ok {
+				i = next
+				continue
+			}
+			n := run(s, i)
+			p.t.write(s[i : i+n])
+			i += n
+			continue
+
+		case '[':
+			if next, ok := p.link(s, i); ok {
+				i = next
+				continue
+			}
+
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				if end, ok := linkEnd(s, i+1); ok {
+					p.d.report(p.line, "inline image, images must be on a line of their own")
+					i = end
+					continue
+				}
+			}
+
+		case '<':
+			if m := autolink.FindStringSubmatch(s[i:]); m != nil {
+				start := p.t.runes
+				p.t.write(m[1])
+				p.t.style(post.StyleLink, start, m[1])
+				i += len(m[0])
+				continue
+			}
+			if tag := inlineTag.FindString(s[i:]); tag != "" {
+				p.d.report(p.line, "inline HTML %s", tag)
+				i += len(tag)
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		p.t.write(s[i : i+size])
+		i += size
+	}
+}
+
+// emphasis parses the emphasis, strong emphasis or strikethrough starting
+// at s[i] and returns the index following it. It reports false when the
+// delimiters are not closed.
+func (p *inline) emphasis(s string, i int) (int, bool) {
+	c, n := s[i], run(s, i)
+
+	var name string
+	switch {
+	case c == '~' && n == 2:
+		name = post.StyleStrikethrough
+	case c == '~':
+		return 0, false
+	case n == 2:
+		name = post.StyleBold
+	case n == 1:
+		name = post.StyleItalic
+	default:
+		return 0, false
+	}
+
+	// Delimiters followed by whitespace, or underscores inside a word, do
+	// not open anything.
+	if i+n >= len(s) || isSpace(s[i+n]) || (c == '_' && i > 0 && isAlnum(s[i-1])) {
+		return 0, false
+	}
+
+	for j := i + n; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			continue
+		case '`':
+			m := run(s, j)
+			if end := findRun(s, j+m, '`', m); end >= 0 {
+				j = end + m - 1
+			}
+			continue
+		case c:
+		default:
+			continue
+		}
+
+		m := run(s, j)
+		if m == n && !isSpace(s[j-1]) && !(c == '_' && j+m < len(s) && isAlnum(s[j+m])) {
+			start := p.t.runes
+			p.parse(s[i+n : j])
+			p.t.style(name, start, "")
+			return j + m, true
+		}
+		j += m - 1
+	}
+
+	return 0, false
+}
+
+// link parses the inline link starting at s[i] and returns the index
+// following it. Links to other than http and https URLs are reported, their
+// text is kept.
+func (p *inline) link(s string, i int) (int, bool) {
+	end, ok := linkEnd(s, i)
+	if !ok {
+		return 0, false
+	}
+
+	closing := matchBracket(s, i)
+	dest := strings.TrimSpace(s[closing+2 : end-1])
+	if fields := strings.Fields(dest); len(fields) > 0 {
+		dest = strings.Trim(fields[0], "<>")
+	}
+
+	start := p.t.runes
+	p.parse(s[i+1 : closing])
+
+	if !safeURL(dest) {
+		p.d.report(p.line, "link to %q, only http and https links are allowed", dest)
+		return end, true
+	}
+	p.t.style(post.StyleLink, start, dest)
+
+	return end, true
+}
+
+// linkEnd returns the index following the inline link or image starting
+// with the bracket at s[i].
+func linkEnd(s string, i int) (int, bool) {
+	closing := matchBracket(s, i)
+	if closing < 0 || closing+1 >= len(s) || s[closing+1] != '(' {
+		return 0, false
+	}
+
+	// Destinations may hold balanced parentheses.
+	depth := 0
+	for j := closing + 2; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return j + 1, true
+			}
+			depth--
+		}
+	}
+
+	return 0, false
+}
+
+// matchBracket returns the index of the bracket closing the one at s[i], or
+// -1 when it is not closed.
+func matchBracket(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+
+	return -1
+}
+
+// run returns the length of the run of the character at s[i].
+func run(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// findRun returns the index of the next run of exactly n c characters in s
+// starting from i, or -1.
+func findRun(s string, i int, c byte, n int) int {
+	for j := i; j < len(s); j++ {
+		if s[j] != c {
+			continue
+		}
+		m := run(s, j)
+		if m == n {
+			return j
+		}
+		j += m - 1
+	}
+
+	return -1
+}
+
+// unescape removes the backslashes escaping punctuation in s.
+func unescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+
+	return sb.String()
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
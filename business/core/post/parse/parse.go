@@ -0,0 +1,127 @@
+// Package parse converts Markdown and HTML documents into post content, the
+// reverse of package render. Only the constructs post content can represent
+// are accepted, the others are reported instead of being dropped.
+package parse
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// Issue is a construct of a document that has no equivalent in post content.
+// Line is the line of the construct in the source, or 0 when unknown.
+type Issue struct {
+	Line      int    `json:"line,omitempty"`
+	Construct string `json:"construct"`
+}
+
+// String implements the fmt.Stringer interface.
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return i.Construct
+	}
+	return fmt.Sprintf("line %d: %s", i.Line, i.Construct)
+}
+
+// UnsupportedError lists the issues found while parsing a document.
+type UnsupportedError struct {
+	Issues []Issue
+}
+
+// Error implements the error interface.
+func (e *UnsupportedError) Error() string {
+	issues := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		issues[i] = issue.String()
+	}
+
+	return "unsupported constructs: " + strings.Join(issues, "; ")
+}
+
+// =============================================================================
+
+// document collects the blocks and issues of a parsed document.
+type document struct {
+	blocks []post.Block
+	issues []Issue
+}
+
+// report records an unsupported construct.
+func (d *document) report(line int, format string, args ...any) {
+	d.issues = append(d.issues, Issue{Line: line, Construct: fmt.Sprintf(format, args...)})
+}
+
+// add appends a block, blocks without content are skipped.
+func (d *document) add(b post.Block) {
+	if b.Type != post.BlockImage && strings.TrimSpace(b.Content) == "" {
+		return
+	}
+	d.blocks = append(d.blocks, b)
+}
+
+// result returns the content of the document, or the issues found.
+func (d *document) result() (post.Content, error) {
+	if len(d.issues) > 0 {
+		return post.Content{}, &UnsupportedError{Issues: d.issues}
+	}
+
+	return post.Content{Blocks: d.blocks}, nil
+}
+
+// =============================================================================
+
+// text builds the content of a block along with its style ranges, which are
+// counted in runes like post.Style expects.
+type text struct {
+	sb     strings.Builder
+	runes  int
+	styles []post.Style
+}
+
+// write appends s to the content.
+func (t *text) write(s string) {
+	t.sb.WriteString(s)
+	t.runes += utf8.RuneCountInString(s)
+}
+
+// style applies a style to the content written since offset start.
+func (t *text) style(name string, start int, link string) {
+	if t.runes == start {
+		return
+	}
+	t.styles = append(t.styles, post.Style{Offset: start, Length: t.runes - start, Style: name, URL: link})
+}
+
+// block returns a block of type typ holding the content, trimmed of the
+// whitespace at its edges.
+func (t *text) block(typ string) post.Block {
+	content := t.sb.String()
+
+	// Trimming shifts the style ranges by the runes removed at the start.
+	trimmed := strings.TrimLeft(content, " \t\n")
+	shift := utf8.RuneCountInString(content) - utf8.RuneCountInString(trimmed)
+	trimmed = strings.TrimRight(trimmed, " \t\n")
+	length := utf8.RuneCountInString(trimmed)
+
+	var styles []post.Style
+	for _, s := range t.styles {
+		start, end := max(s.Offset-shift, 0), min(s.Offset+s.Length-shift, length)
+		if end > start {
+			s.Offset, s.Length = start, end-start
+			styles = append(styles, s)
+		}
+	}
+
+	return post.Block{Type: typ, Content: trimmed, Styles: styles}
+}
+
+// safeURL reports whether rawURL is an absolute http or https URL, the only
+// targets accepted for links and images.
+func safeURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
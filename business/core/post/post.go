@@ -13,41 +13,88 @@ import (
 // Set of error variables for CRUD operations.
 var (
 	ErrNotFound  = errors.New("post not found")
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrConcurrentEdit = errors.New("post was edited concurrently")
+	ErrSlugTaken = errors.New("slug is taken")
 )
 
 type Storer interface {
 	Create(ctx context.Context, post Post) (error)
+	Update(ctx context.Context, post Post) error
 	Delete(ctx context.Context, post Post) error
+	Publish(ctx context.Context, post Post) (bool, error)
 	QueryById(ctx context.Context, id int64) (Post, error)
+	QueryByIds(ctx context.Context, ids []int64) ([]Post, error)
+	QueryBySlug(ctx context.Context, slug string) (Post, error)
 	Query(ctx context.Context, filter QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]Post, error)
+	QuerySlugs(ctx context.Context, base string) (map[string]int64, error)
+	ReserveSlug(ctx context.Context, slug string, postId int64) error
+	CreateRevision(ctx context.Context, rev Revision) error
+	DeleteRevision(ctx context.Context, contentId int64, version int) error
+	QueryRevisions(ctx context.Context, contentId int64) ([]Revision, error)
+	QueryRevision(ctx context.Context, contentId int64, version int) (Revision, error)
+	QueryLatestRevision(ctx context.Context, contentId int64) (Revision, error)
 }
 
 type CacheStore interface {
 	CreatePost(context.Context, Post) (error)
+	CreatePosts(context.Context, []Post) error
 	QueryPostById(context.Context, int64) (Post, bool, error)
+	QueryPostsByIds(context.Context, []int64) (map[int64]Post, error)
+	QueryPostIdBySlug(context.Context, string) (int64, bool, error)
 	DeletePost(context.Context, int64) error
+	DeleteSlugs(context.Context, []string) error
 }
 
 type SQLstore interface {
 	Create(context.Context, Post) (error)
+	Update(context.Context, Post) error
 	Delete(context.Context, int64) error
+	Publish(context.Context, Post) (bool, error)
 	QueryById(context.Context, int64) (Post, error)
+	QueryByIds(context.Context, []int64) ([]Post, error)
+	QueryIdBySlug(context.Context, string) (int64, error)
 	Query(ctx context.Context, filter QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]Post, error)
+	QuerySlugs(context.Context, string) (map[string]int64, error)
+	ReserveSlug(context.Context, string, int64) error
+	QueryPostSlugs(context.Context, int64) ([]string, error)
+	ReleaseSlugs(context.Context, int64) error
 }
 
 type NOSQLStore interface {
 	Create(context.Context, Content, int64) (error)
+	Update(context.Context, Content, int64) error
 	Delete(context.Context, int64) error
 	QueryById(context.Context, int64) (Content, error)
+	QueryByIds(context.Context, []int64) (map[int64]Content, error)
+}
+
+// RevisionStore keeps the history of the metadata and content of posts.
+// Revisions are never modified, creating a revision whose version already
+// exists fails with ErrConcurrentEdit.
+type RevisionStore interface {
+	Create(context.Context, Revision) error
+	Delete(context.Context, int64, int) error
+	DeleteAll(context.Context, int64) error
+	QueryAll(context.Context, int64) ([]Revision, error)
+	QueryByVersion(context.Context, int64, int) (Revision, error)
+	QueryLatest(context.Context, int64) (Revision, error)
 }
 
 type IdGenerator interface {
 	GenerateId() (uint64, error)
 }
 
+// Publisher announces posts becoming public to the other services.
+type Publisher interface {
+	Published(ctx context.Context, post Post) error
+}
+
 // Core manages the set of APIs for posts api access
 type Core struct {
 	storer Storer
+	publisher Publisher
 	log *logger.Logger
 	idGenerator IdGenerator
 }
@@ -56,10 +103,12 @@ type Core struct {
 //
 // Parameters:
 //   - st: struct that implements the Storer interface for repository operations.
+//   - pub: struct that implements the Publisher interface for post events.
 //   - log: pointer to the logger used for logging within the core.
-func NewCore(s Storer, log *logger.Logger, idGen IdGenerator) *Core {
+func NewCore(s Storer, pub Publisher, log *logger.Logger, idGen IdGenerator) *Core {
 	return &Core{
 		storer: s, 
+		publisher: pub,
 		log: log,
 		idGenerator: idGen,
 	}
@@ -73,6 +122,14 @@ func NewCore(s Storer, log *logger.Logger, idGen IdGenerator) *Core {
 //   - newPost: the contents of the new post to be created.
 func (c *Core) Create(ctx context.Context, newPost NewPost) (Post, error) {
 	now := time.Now()
+
+	status := newPost.Status
+	if status == "" {
+		status = StatusPublished
+	}
+	if status == StatusArchived || !ValidStatus(status) {
+		return Post{}, fmt.Errorf("%w: new posts cannot be %s", ErrInvalidTransition, status)
+	}
 	
 	id, err := c.idGenerator.GenerateId()
 	if err != nil {
@@ -89,18 +146,155 @@ func (c *Core) Create(ctx context.Context, newPost NewPost) (Post, error) {
 		UserId: newPost.UserId,
 		Title: newPost.Title,
 		Description: newPost.Description,
+		FrontImage: newPost.FrontImage,
 		ContentId: int64(contentId),
 		Content: newPost.Content,
+		Stats: ComputeStats(newPost.Content),
+		Status: StatusDraft,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+
+	if err := applyStatus(&post, status, newPost.PublishAt, now); err != nil {
+		return Post{}, err
+	}
+
+	if err := c.assignSlug(ctx, &post); err != nil {
+		return Post{}, err
+	}
 	
 	if err := c.storer.Create(ctx, post); err != nil {
 		return Post{}, fmt.Errorf("error creating a post: %w", err)
 	}
+
+	if err := c.storer.CreateRevision(ctx, newRevision(post, 1, now)); err != nil {
+		return Post{}, fmt.Errorf("create revision: %w", err)
+	}
+
+	if post.Status == StatusPublished {
+		c.published(ctx, post)
+	}
+
+	return post, nil
+}
+
+// Update modifies the fields of a post that are set in the update. The new
+// state is recorded as a revision before the post is stored, an edit racing
+// another one fails with ErrConcurrentEdit instead of overwriting it. The
+// revision is deleted again when the post cannot be stored.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - post: the post to be updated.
+//   - up: the fields of the post to be changed.
+func (c *Core) Update(ctx context.Context, post Post, up UpdatePost) (Post, error) {
+	now := time.Now()
+	before := post
+	wasPublished := post.Status == StatusPublished
+
+	if up.Status != nil || up.PublishAt != nil {
+		status := post.Status
+		if up.Status != nil {
+			status = *up.Status
+		}
+		var publishAt time.Time
+		if up.PublishAt != nil {
+			publishAt = *up.PublishAt
+		}
+		if err := applyStatus(&post, status, publishAt, now); err != nil {
+			return Post{}, err
+		}
+	}
+
+	if up.Title != nil {
+		post.Title = *up.Title
+	}
+	if up.Description != nil {
+		post.Description = *up.Description
+	}
+	if up.FrontImage != nil {
+		post.FrontImage = *up.FrontImage
+	}
+	if up.Content != nil {
+		post.Content = *up.Content
+	}
+	post.Stats = ComputeStats(post.Content)
+	post.UpdatedAt = now
+
+	if err := c.assignSlug(ctx, &post); err != nil {
+		return Post{}, err
+	}
+
+	rev, recorded, err := c.recordRevision(ctx, before, post, now)
+	if err != nil {
+		return Post{}, err
+	}
+
+	if err := c.storer.Update(ctx, post); err != nil {
+		if recorded {
+			c.dropRevision(ctx, rev)
+		}
+		return Post{}, fmt.Errorf("update: %w", err)
+	}
+
+	if !wasPublished && post.Status == StatusPublished {
+		c.published(ctx, post)
+	}
+
 	return post, nil
 }
 
+// PublishDue publishes the scheduled posts whose publication time has come,
+// at most limit of them, and returns how many were published. A post is
+// only published by the caller that moves it out of the scheduled status,
+// concurrent callers never publish a post twice.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - now: the time posts scheduled before are due.
+//   - limit: the largest number of posts published by the call.
+func (c *Core) PublishDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	var filter QueryFilter
+	filter.WithStatus(StatusScheduled)
+	filter.WithPublishBefore(now)
+
+	due, err := c.storer.Query(ctx, filter, order.NewBy(OrderByPublishAt, order.ASC), 1, limit)
+	if err != nil {
+		return 0, fmt.Errorf("query due: %w", err)
+	}
+
+	var published int
+	for _, post := range due {
+		post.Status = StatusPublished
+		post.UpdatedAt = now
+
+		ok, err := c.storer.Publish(ctx, post)
+		if err != nil {
+			return published, fmt.Errorf("publish[%d]: %w", post.Id, err)
+		}
+		if !ok {
+			continue
+		}
+
+		published++
+		c.published(ctx, post)
+	}
+
+	return published, nil
+}
+
+// published announces a post that became public. The post is stored by
+// then, a failure to announce it is logged rather than failing the change.
+func (c *Core) published(ctx context.Context, post Post) {
+	if c.publisher == nil {
+		return
+	}
+
+	if err := c.publisher.Published(ctx, post); err != nil {
+		c.log.Error(ctx, "post published event", "postId", post.Id, "msg", err)
+	}
+}
+
 // Delete removes a specified post from the repository.
 //
 // Parameters:
@@ -122,6 +316,46 @@ func (c *Core) QueryById(ctx context.Context, id int64) (Post, error) {
 	return post, nil
 }
 
+// QueryByIds returns the posts of a set of ids in the order of the ids, along
+// with the ids that have no post. Repeated ids are returned once.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - ids: the ids of the posts.
+func (c *Core) QueryByIds(ctx context.Context, ids []int64) ([]Post, []int64, error) {
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	found, err := c.storer.QueryByIds(ctx, unique)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byId := make(map[int64]Post, len(found))
+	for _, post := range found {
+		byId[post.Id] = post
+	}
+
+	posts := make([]Post, 0, len(found))
+	var missing []int64
+	for _, id := range unique {
+		post, ok := byId[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, missing, nil
+}
+
 func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]Post, error) {
 	posts, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
 	if err != nil {
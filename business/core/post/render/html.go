@@ -0,0 +1,161 @@
+package render
+
+import (
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// htmlTags maps the styles to the element they are rendered with.
+var htmlTags = map[style]string{
+	styleBold:          "strong",
+	styleItalic:        "em",
+	styleUnderline:     "u",
+	styleStrikethrough: "s",
+	styleCode:          "code",
+}
+
+// HTML renders c as an HTML fragment. All text is escaped and only a fixed
+// set of elements is produced, image sources are limited to http and https
+// URLs, so the result is safe to embed in a page.
+func HTML(c post.Content) string {
+	var sb strings.Builder
+
+	for _, b := range c.Blocks {
+		switch b.Type {
+		case post.BlockParagraph:
+			sb.WriteString("<p>")
+			htmlLines(&sb, lines(segments(b)))
+			sb.WriteString("</p>\n")
+
+		case post.BlockHeading:
+			sb.WriteString("<h2>")
+			htmlLines(&sb, lines(segments(b)))
+			sb.WriteString("</h2>\n")
+
+		case post.BlockQuote:
+			sb.WriteString("<blockquote><p>")
+			htmlLines(&sb, lines(segments(b)))
+			sb.WriteString("</p>")
+			if b.Caption != "" {
+				sb.WriteString("<footer>" + html.EscapeString(b.Caption) + "</footer>")
+			}
+			sb.WriteString("</blockquote>\n")
+
+		case post.BlockCode:
+			sb.WriteString("<pre><code>" + html.EscapeString(b.Content) + "</code></pre>\n")
+
+		case post.BlockList:
+			sb.WriteString("<ul>\n")
+			for _, item := range listItems(b) {
+				sb.WriteString("<li>")
+				htmlInline(&sb, item)
+				sb.WriteString("</li>\n")
+			}
+			sb.WriteString("</ul>\n")
+
+		case post.BlockImage:
+			if !safeURL(b.URL) {
+				continue
+			}
+			caption := html.EscapeString(b.Caption)
+			sb.WriteString(`<figure><img src="` + html.EscapeString(b.URL) + `" alt="` + caption + `">`)
+			if b.Caption != "" {
+				sb.WriteString("<figcaption>" + caption + "</figcaption>")
+			}
+			sb.WriteString("</figure>\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// htmlLines writes lines separated by line breaks.
+func htmlLines(sb *strings.Builder, ls [][]segment) {
+	for i, l := range ls {
+		if i > 0 {
+			sb.WriteString("<br>")
+		}
+		htmlInline(sb, l)
+	}
+}
+
+// htmlInline writes styled segments. Elements are kept open while their
+// style continues, overlapping ranges close and reopen the elements nested
+// inside the one that ends so the markup stays well formed. Links are the
+// outermost element, every style is closed when a link starts or ends.
+func htmlInline(sb *strings.Builder, segs []segment) {
+	var open []style
+	var link string
+
+	for _, s := range segs {
+		if s.link != link {
+			open = closeEnded(open, 0, func(st style) {
+				sb.WriteString("</" + htmlTags[st] + ">")
+			})
+			if link != "" {
+				sb.WriteString("</a>")
+			}
+			if s.link != "" {
+				sb.WriteString(`<a href="` + html.EscapeString(s.link) + `" rel="nofollow noopener">`)
+			}
+			link = s.link
+		}
+
+		open = closeEnded(open, s.styles, func(st style) {
+			sb.WriteString("</" + htmlTags[st] + ">")
+		})
+		open = openStarted(open, s.styles, func(st style) {
+			sb.WriteString("<" + htmlTags[st] + ">")
+		})
+		sb.WriteString(html.EscapeString(s.text))
+	}
+
+	closeEnded(open, 0, func(st style) {
+		sb.WriteString("</" + htmlTags[st] + ">")
+	})
+	if link != "" {
+		sb.WriteString("</a>")
+	}
+}
+
+// closeEnded closes the styles of open that are not in set, along with every
+// style opened after them, and returns the styles still open.
+func closeEnded(open []style, set style, close func(style)) []style {
+	i := 0
+	for i < len(open) && set&open[i] != 0 {
+		i++
+	}
+
+	for j := len(open) - 1; j >= i; j-- {
+		close(open[j])
+	}
+
+	return open[:i]
+}
+
+// openStarted opens the styles of set that are not open yet and returns the
+// styles open afterwards.
+func openStarted(open []style, set style, openFn func(style)) []style {
+	var current style
+	for _, st := range open {
+		current |= st
+	}
+
+	for _, st := range styleOrder {
+		if set&st != 0 && current&st == 0 {
+			openFn(st)
+			open = append(open, st)
+		}
+	}
+
+	return open
+}
+
+// safeURL reports whether rawURL is an absolute http or https URL.
+func safeURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
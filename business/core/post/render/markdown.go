@@ -0,0 +1,174 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// markdownDelims maps the styles to their CommonMark delimiters. Underline
+// has no CommonMark equivalent and is dropped, code is rendered as a code
+// span, strikethrough uses the widely supported GFM extension.
+var markdownDelims = map[style]string{
+	styleBold:          "**",
+	styleItalic:        "_",
+	styleStrikethrough: "~~",
+}
+
+// markdownEscaper escapes the characters that carry a meaning anywhere in a
+// CommonMark line.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `~`, `\~`, `|`, `\|`, `!`, `\!`, `#`, `\#`, `&`, `\&`,
+)
+
+// blockStart matches the beginnings of a line that would turn it into a
+// list item, a thematic break or a setext heading underline.
+var blockStart = regexp.MustCompile(`^([-+=]|\d+[.)])`)
+
+// Markdown renders c as a CommonMark document. The text of the blocks is
+// escaped so it is never interpreted as markup.
+func Markdown(c post.Content) string {
+	var blocks []string
+
+	for _, b := range c.Blocks {
+		switch b.Type {
+		case post.BlockParagraph:
+			blocks = append(blocks, strings.Join(markdownLines(b), "\\\n"))
+
+		case post.BlockHeading:
+			blocks = append(blocks, "## "+strings.Join(markdownLines(b), " "))
+
+		case post.BlockQuote:
+			quote := "> " + strings.Join(markdownLines(b), "\\\n> ")
+			if b.Caption != "" {
+				quote += "\n>\n> — " + markdownEscaper.Replace(b.Caption)
+			}
+			blocks = append(blocks, quote)
+
+		case post.BlockCode:
+			fence := strings.Repeat("`", max(3, longestRun(b.Content, '`')+1))
+			blocks = append(blocks, fence+"\n"+strings.TrimSuffix(b.Content, "\n")+"\n"+fence)
+
+		case post.BlockList:
+			var items []string
+			for _, item := range listItems(b) {
+				items = append(items, "- "+markdownLine(item))
+			}
+			blocks = append(blocks, strings.Join(items, "\n"))
+
+		case post.BlockImage:
+			if !safeURL(b.URL) {
+				continue
+			}
+			blocks = append(blocks, "!["+markdownEscaper.Replace(b.Caption)+"](<"+markdownURL(b.URL)+">)")
+		}
+	}
+
+	if len(blocks) == 0 {
+		return ""
+	}
+
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// markdownLines renders the lines of a block, skipping blank ones which
+// would end the block.
+func markdownLines(b post.Block) []string {
+	var result []string
+	for _, l := range lines(segments(b)) {
+		if line := markdownLine(l); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+// markdownLine renders a line of styled segments. Leading whitespace is
+// dropped, it would turn the line into an indented code block.
+func markdownLine(segs []segment) string {
+	line := strings.TrimLeft(markdownInline(segs), " \t")
+	if loc := blockStart.FindStringIndex(line); loc != nil {
+		line = line[:loc[1]-1] + `\` + line[loc[1]-1:]
+	}
+
+	return line
+}
+
+// markdownInline renders styled segments. Delimiters are kept next to the
+// text they apply to, CommonMark does not recognize them next to whitespace,
+// so whitespace at the edges of a styled run is moved outside of them. Links
+// close every style when they start or end, like in HTML.
+func markdownInline(segs []segment) string {
+	var sb strings.Builder
+	var open []style
+	var pending string
+	var link string
+
+	closeFn := func(st style) { sb.WriteString(markdownDelims[st]) }
+	openFn := func(st style) { sb.WriteString(markdownDelims[st]) }
+
+	for _, s := range segs {
+		set := s.styles &^ (styleUnderline | styleCode)
+
+		if s.link != link {
+			open = closeEnded(open, 0, closeFn)
+			if link != "" {
+				sb.WriteString("](<" + markdownURL(link) + ">)")
+			}
+			sb.WriteString(pending)
+			pending = ""
+			if s.link != "" {
+				sb.WriteString("[")
+			}
+			link = s.link
+		}
+
+		core := strings.TrimSpace(s.text)
+		if core == "" {
+			open = closeEnded(open, set, closeFn)
+			pending += s.text
+			continue
+		}
+		lead := s.text[:strings.Index(s.text, core)]
+		trail := s.text[len(lead)+len(core):]
+
+		open = closeEnded(open, set, closeFn)
+		sb.WriteString(pending + lead)
+		open = openStarted(open, set, openFn)
+
+		if s.styles&styleCode != 0 {
+			sb.WriteString(codeSpan(core))
+		} else {
+			sb.WriteString(markdownEscaper.Replace(core))
+		}
+		pending = trail
+	}
+
+	closeEnded(open, 0, closeFn)
+	if link != "" {
+		sb.WriteString("](<" + markdownURL(link) + ">)")
+	}
+	sb.WriteString(pending)
+
+	return sb.String()
+}
+
+// codeSpan wraps text in a code span, using a backtick string longer than
+// any run of backticks inside the text.
+func codeSpan(text string) string {
+	fence := strings.Repeat("`", longestRun(text, '`')+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") {
+		text = " " + text + " "
+	}
+
+	return fence + text + fence
+}
+
+// markdownURL escapes the characters that cannot appear in a link
+// destination enclosed in angle brackets.
+func markdownURL(rawURL string) string {
+	return strings.NewReplacer("<", "%3C", ">", "%3E", " ", "%20", "\n", "").Replace(rawURL)
+}
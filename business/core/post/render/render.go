@@ -0,0 +1,213 @@
+// Package render converts the content of a post into standard document
+// formats: sanitized HTML, CommonMark and plain text.
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// Format is a document format the content of a post can be rendered to.
+type Format string
+
+// Set of formats the content of a post can be rendered to.
+const (
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "markdown"
+	FormatText     Format = "text"
+)
+
+// ParseFormat returns the Format matching name.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatHTML, FormatMarkdown, FormatText:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("unknown format %q", name)
+}
+
+// ContentType returns the media type of documents in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8; variant=CommonMark"
+	}
+
+	return "text/plain; charset=utf-8"
+}
+
+// Render converts c to the format f.
+func Render(f Format, c post.Content) (string, error) {
+	switch f {
+	case FormatHTML:
+		return HTML(c), nil
+	case FormatMarkdown:
+		return Markdown(c), nil
+	case FormatText:
+		return Text(c), nil
+	}
+
+	return "", fmt.Errorf("unknown format %q", f)
+}
+
+// =============================================================================
+
+// style is a bit set of the styles applied to a run of text.
+type style uint8
+
+// Set of styles, in the order they are nested in, the outermost first.
+const (
+	styleBold style = 1 << iota
+	styleItalic
+	styleUnderline
+	styleStrikethrough
+	styleCode
+)
+
+// styleOrder lists the styles in their nesting order.
+var styleOrder = []style{styleBold, styleItalic, styleUnderline, styleStrikethrough, styleCode}
+
+// styleByName maps the style names of the post package to styles.
+var styleByName = map[string]style{
+	post.StyleBold:          styleBold,
+	post.StyleItalic:        styleItalic,
+	post.StyleUnderline:     styleUnderline,
+	post.StyleStrikethrough: styleStrikethrough,
+	post.StyleCode:          styleCode,
+}
+
+// segment is a run of text with the same set of styles applied. Link is the
+// target of the link the text is part of, if any.
+type segment struct {
+	text   string
+	styles style
+	link   string
+}
+
+// segments splits the content of a block into runs of equally styled text.
+// Style ranges are expressed in runes, they may overlap and are clamped to
+// the content, unknown style names are ignored. Where links overlap the one
+// listed last wins.
+func segments(b post.Block) []segment {
+	runes := []rune(b.Content)
+	if len(runes) == 0 {
+		return nil
+	}
+
+	bounds := map[int]bool{0: true, len(runes): true}
+	for _, s := range b.Styles {
+		bounds[clamp(s.Offset, len(runes))] = true
+		bounds[clamp(s.Offset+s.Length, len(runes))] = true
+	}
+
+	points := make([]int, 0, len(bounds))
+	for p := range bounds {
+		points = append(points, p)
+	}
+	sort.Ints(points)
+
+	var segs []segment
+	for i := 0; i < len(points)-1; i++ {
+		start, end := points[i], points[i+1]
+
+		var set style
+		var link string
+		for _, s := range b.Styles {
+			if s.Offset <= start && end <= s.Offset+s.Length {
+				set |= styleByName[s.Style]
+				if s.Style == post.StyleLink && safeURL(s.URL) {
+					link = s.URL
+				}
+			}
+		}
+
+		// Neighbours with the same styles are merged, the formats would
+		// otherwise close and reopen the same markup.
+		if n := len(segs); n > 0 && segs[n-1].styles == set && segs[n-1].link == link {
+			segs[n-1].text += string(runes[start:end])
+			continue
+		}
+		segs = append(segs, segment{text: string(runes[start:end]), styles: set, link: link})
+	}
+
+	return segs
+}
+
+// lines splits segments at the line breaks of their text.
+func lines(segs []segment) [][]segment {
+	result := [][]segment{nil}
+	for _, s := range segs {
+		parts := strings.Split(s.text, "\n")
+		for i, p := range parts {
+			if i > 0 {
+				result = append(result, nil)
+			}
+			if p != "" {
+				result[len(result)-1] = append(result[len(result)-1], segment{text: p, styles: s.styles, link: s.link})
+			}
+		}
+	}
+
+	return result
+}
+
+// listItems returns the non blank lines of a list block, one per item.
+func listItems(b post.Block) [][]segment {
+	var items [][]segment
+	for _, l := range lines(segments(b)) {
+		if strings.TrimSpace(plain(l)) != "" {
+			items = append(items, l)
+		}
+	}
+
+	return items
+}
+
+// plain returns the text of segments without styles.
+func plain(segs []segment) string {
+	var sb strings.Builder
+	for _, s := range segs {
+		sb.WriteString(s.text)
+	}
+
+	return sb.String()
+}
+
+// clamp limits n to the range [0, max].
+func clamp(n int, max int) int {
+	switch {
+	case n < 0:
+		return 0
+	case n > max:
+		return max
+	}
+
+	return n
+}
+
+// longestRun returns the length of the longest run of r in s.
+func longestRun(s string, r rune) int {
+	var longest, current int
+	for len(s) > 0 {
+		c, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+
+		if c != r {
+			current = 0
+			continue
+		}
+		current++
+		if current > longest {
+			longest = current
+		}
+	}
+
+	return longest
+}
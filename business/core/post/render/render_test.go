@@ -0,0 +1,99 @@
+package render
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// update rewrites the golden files with the current output:
+//
+//	go test ./business/core/post/render -update
+var update = flag.Bool("update", false, "update the golden files")
+
+// content exercises the cases the formats get wrong most easily: styles
+// that overlap without nesting, links over styled text and offsets counted
+// in runes over multi-byte text.
+var content = post.Content{
+	Blocks: []post.Block{
+		{Type: post.BlockHeading, Content: "Rendering *posts*"},
+		{
+			Type:    post.BlockParagraph,
+			Content: "bold and italic overlap here",
+			Styles: []post.Style{
+				{Offset: 0, Length: 15, Style: post.StyleBold},
+				{Offset: 9, Length: 14, Style: post.StyleItalic},
+			},
+		},
+		{
+			Type:    post.BlockParagraph,
+			Content: "see the docs or this",
+			Styles: []post.Style{
+				{Offset: 4, Length: 8, Style: post.StyleLink, URL: "https://example.com/docs?a=1&b=2"},
+				{Offset: 8, Length: 4, Style: post.StyleBold},
+				{Offset: 16, Length: 4, Style: post.StyleLink, URL: "javascript:alert(1)"},
+			},
+		},
+		{
+			Type:    post.BlockParagraph,
+			Content: "Здравей, свят 👋 café",
+			Styles: []post.Style{
+				{Offset: 0, Length: 7, Style: post.StyleBold},
+				{Offset: 9, Length: 4, Style: post.StyleItalic},
+				{Offset: 14, Length: 1, Style: post.StyleUnderline},
+				{Offset: 16, Length: 4, Style: post.StyleCode},
+			},
+		},
+		{
+			Type:    post.BlockQuote,
+			Content: "first line\nsecond <line>",
+			Styles:  []post.Style{{Offset: 6, Length: 10, Style: post.StyleStrikethrough}},
+		},
+		{Type: post.BlockCode, Content: "if a < b {\n\treturn `b`\n}"},
+		{
+			Type:    post.BlockList,
+			Content: "one\ntwo ✓\n3. three",
+			Styles:  []post.Style{{Offset: 8, Length: 1, Style: post.StyleBold}},
+		},
+		{Type: post.BlockImage, URL: "https://cdn.example.com/media/1/cat.png", Caption: "A \"cat\" & co"},
+	},
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format Format
+		golden string
+	}{
+		{FormatHTML, "post.html.golden"},
+		{FormatMarkdown, "post.md.golden"},
+		{FormatText, "post.txt.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := Render(tt.format, content)
+			if err != nil {
+				t.Fatalf("render: %s", err)
+			}
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatalf("update: %s", err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("golden: %s", err)
+			}
+
+			if got != string(want) {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// Text renders c as plain text, e.g. for search snippets and the text part of
+// emails. Styles are dropped, list items are prefixed with a dash and images
+// are replaced by their caption.
+func Text(c post.Content) string {
+	var blocks []string
+
+	for _, b := range c.Blocks {
+		switch b.Type {
+		case post.BlockParagraph, post.BlockHeading, post.BlockCode:
+			blocks = append(blocks, strings.TrimRight(b.Content, "\n"))
+
+		case post.BlockQuote:
+			quote := b.Content
+			if b.Caption != "" {
+				quote += "\n— " + b.Caption
+			}
+			blocks = append(blocks, quote)
+
+		case post.BlockList:
+			var items []string
+			for _, item := range listItems(b) {
+				items = append(items, "- "+strings.TrimSpace(plain(item)))
+			}
+			blocks = append(blocks, strings.Join(items, "\n"))
+
+		case post.BlockImage:
+			if b.Caption != "" {
+				blocks = append(blocks, b.Caption)
+			}
+		}
+	}
+
+	if len(blocks) == 0 {
+		return ""
+	}
+
+	return strings.Join(blocks, "\n\n") + "\n"
+}
@@ -0,0 +1,296 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Revision is an immutable snapshot of the metadata and content of a post,
+// identified by the content id of the post and a version number starting at
+// one. A revision is recorded every time the post is created or edited.
+type Revision struct {
+	ContentId   int64
+	Version     int
+	PostId      int64
+	Title       string
+	Description string
+	FrontImage  string
+	Content     Content
+	CreatedAt   time.Time
+}
+
+// newRevision returns the snapshot of post as its version-th revision.
+func newRevision(post Post, version int, now time.Time) Revision {
+	return Revision{
+		ContentId:   post.ContentId,
+		Version:     version,
+		PostId:      post.Id,
+		Title:       post.Title,
+		Description: post.Description,
+		FrontImage:  post.FrontImage,
+		Content:     post.Content,
+		CreatedAt:   now,
+	}
+}
+
+// sameSnapshot reports whether two revisions hold the same metadata and
+// content, regardless of their version.
+func sameSnapshot(a, b Revision) bool {
+	return len(diffFields(a, b)) == 0 && blocksEqual(a.Content.Blocks, b.Content.Blocks)
+}
+
+// recordRevision stores post as a new revision unless it matches the latest
+// one, status changes alone do not make a revision. Posts created before
+// revisions were recorded get their stored state, before, as first revision.
+// It returns the revision of post and whether it was stored.
+func (c *Core) recordRevision(ctx context.Context, before Post, post Post, now time.Time) (Revision, bool, error) {
+	latest, err := c.storer.QueryLatestRevision(ctx, post.ContentId)
+	switch {
+	case errors.Is(err, ErrRevisionNotFound):
+		latest = newRevision(before, 1, before.UpdatedAt)
+		if err := c.storer.CreateRevision(ctx, latest); err != nil {
+			return Revision{}, false, fmt.Errorf("create revision[%d]: %w", latest.Version, err)
+		}
+	case err != nil:
+		return Revision{}, false, fmt.Errorf("query latest revision: %w", err)
+	}
+
+	rev := newRevision(post, latest.Version+1, now)
+	if sameSnapshot(latest, rev) {
+		return rev, false, nil
+	}
+
+	if err := c.storer.CreateRevision(ctx, rev); err != nil {
+		return Revision{}, false, fmt.Errorf("create revision[%d]: %w", rev.Version, err)
+	}
+
+	return rev, true, nil
+}
+
+// dropRevision deletes a revision recorded for an update that was not
+// stored, the history would otherwise hold content the post never had and
+// the next edit would skip a version. The update may have failed on the
+// deadline of ctx, the revision is deleted regardless.
+func (c *Core) dropRevision(ctx context.Context, rev Revision) {
+	if err := c.storer.DeleteRevision(context.WithoutCancel(ctx), rev.ContentId, rev.Version); err != nil {
+		c.log.Error(ctx, "drop revision", "contentId", rev.ContentId, "version", rev.Version, "msg", err)
+	}
+}
+
+// QueryRevisions returns the revisions of a post, oldest first.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - post: the post the revisions belong to.
+func (c *Core) QueryRevisions(ctx context.Context, post Post) ([]Revision, error) {
+	revs, err := c.storer.QueryRevisions(ctx, post.ContentId)
+	if err != nil {
+		return nil, fmt.Errorf("query revisions: %w", err)
+	}
+
+	return revs, nil
+}
+
+// QueryRevision returns the version-th revision of a post.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - post: the post the revision belongs to.
+//   - version: the version number of the revision.
+func (c *Core) QueryRevision(ctx context.Context, post Post, version int) (Revision, error) {
+	rev, err := c.storer.QueryRevision(ctx, post.ContentId, version)
+	if err != nil {
+		return Revision{}, fmt.Errorf("query revision[%d]: %w", version, err)
+	}
+
+	return rev, nil
+}
+
+// Restore makes the metadata and content of a revision the current state of
+// the post. The history is kept as it is, the restored state is recorded as
+// a new revision. The status of the post does not change.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - post: the post to be restored.
+//   - version: the version number of the revision to restore.
+func (c *Core) Restore(ctx context.Context, post Post, version int) (Post, error) {
+	rev, err := c.QueryRevision(ctx, post, version)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return c.Update(ctx, post, UpdatePost{
+		Title:       &rev.Title,
+		Description: &rev.Description,
+		FrontImage:  &rev.FrontImage,
+		Content:     &rev.Content,
+	})
+}
+
+// =============================================================================
+
+// Set of operations of a block level diff.
+const (
+	DiffEqual   = "equal"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// Diff describes the changes between two revisions of a post.
+type Diff struct {
+	From   int
+	To     int
+	Fields []string
+	Blocks []BlockChange
+}
+
+// BlockChange is a step of a block level diff. OldIndex is the position of
+// the block in the old revision and NewIndex in the new one, -1 when the
+// block is missing from that revision. Changed blocks are blocks of the
+// same type edited in place.
+type BlockChange struct {
+	Op       string
+	OldIndex int
+	NewIndex int
+	Old      Block
+	New      Block
+}
+
+// Diff returns the changes made to a post from one revision to another.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - post: the post the revisions belong to.
+//   - from: the version number of the old revision.
+//   - to: the version number of the new revision.
+func (c *Core) Diff(ctx context.Context, post Post, from int, to int) (Diff, error) {
+	before, err := c.QueryRevision(ctx, post, from)
+	if err != nil {
+		return Diff{}, err
+	}
+
+	after, err := c.QueryRevision(ctx, post, to)
+	if err != nil {
+		return Diff{}, err
+	}
+
+	return Diff{
+		From:   from,
+		To:     to,
+		Fields: diffFields(before, after),
+		Blocks: diffBlocks(before.Content.Blocks, after.Content.Blocks),
+	}, nil
+}
+
+// diffFields returns the names of the metadata fields that differ between
+// two revisions.
+func diffFields(before, after Revision) []string {
+	var fields []string
+	if before.Title != after.Title {
+		fields = append(fields, "title")
+	}
+	if before.Description != after.Description {
+		fields = append(fields, "description")
+	}
+	if before.FrontImage != after.FrontImage {
+		fields = append(fields, "frontImage")
+	}
+	return fields
+}
+
+// diffBlocks computes the block level diff of two lists of blocks from
+// their longest common subsequence. Within a run of changes, removed and
+// added blocks of the same type at the same position are reported as
+// changed blocks.
+func diffBlocks(before, after []Block) []BlockChange {
+	// lcs[i][j] is the length of the longest common subsequence of
+	// before[i:] and after[j:].
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			switch {
+			case blockEqual(before[i], after[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var changes []BlockChange
+	var removed, added []int
+
+	flush := func() {
+		n := min(len(removed), len(added))
+		k := 0
+		for ; k < n && before[removed[k]].Type == after[added[k]].Type; k++ {
+			changes = append(changes, BlockChange{Op: DiffChanged, OldIndex: removed[k], NewIndex: added[k], Old: before[removed[k]], New: after[added[k]]})
+		}
+		for _, i := range removed[k:] {
+			changes = append(changes, BlockChange{Op: DiffRemoved, OldIndex: i, NewIndex: -1, Old: before[i]})
+		}
+		for _, j := range added[k:] {
+			changes = append(changes, BlockChange{Op: DiffAdded, OldIndex: -1, NewIndex: j, New: after[j]})
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && blockEqual(before[i], after[j]):
+			flush()
+			changes = append(changes, BlockChange{Op: DiffEqual, OldIndex: i, NewIndex: j, Old: before[i], New: after[j]})
+			i++
+			j++
+		case j == len(after) || (i < len(before) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+	flush()
+
+	return changes
+}
+
+// blocksEqual reports whether two lists of blocks are the same.
+func blocksEqual(a, b []Block) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !blockEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// blockEqual reports whether two blocks are the same. A missing list of
+// styles equals an empty one, stores do not keep the difference.
+func blockEqual(a, b Block) bool {
+	if a.Type != b.Type || a.Content != b.Content || a.URL != b.URL || a.Caption != b.Caption {
+		return false
+	}
+	if len(a.Styles) != len(b.Styles) {
+		return false
+	}
+	for i := range a.Styles {
+		if a.Styles[i] != b.Styles[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package post
+
+import (
+	"context"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/logger"
+)
+
+// schedulerLock is the name of the lock held by the scheduler publishing.
+const schedulerLock = "post-scheduler"
+
+// schedulerBatch is the largest number of posts published per run, the next
+// run picks up the rest.
+const schedulerBatch = 100
+
+// Locker hands out locks shared by every instance of the service.
+type Locker interface {
+	TryLock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error)
+}
+
+// Scheduler publishes the scheduled posts once they are due. Every instance
+// of the service runs one, the lock makes sure a single one of them works at
+// a time.
+type Scheduler struct {
+	core     *Core
+	locker   Locker
+	log      *logger.Logger
+	interval time.Duration
+}
+
+// NewScheduler constructs a scheduler checking for due posts every interval.
+func NewScheduler(core *Core, locker Locker, log *logger.Logger, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		core:     core,
+		locker:   locker,
+		log:      log,
+		interval: interval,
+	}
+}
+
+// Run publishes the due posts every interval until ctx is cancelled. A zero
+// interval disables the scheduler.
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.run(ctx)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// run publishes the posts due now if no other instance is doing so. The lock
+// expires after an interval, a run that takes longer can overlap with the
+// next one, which the storer tolerates.
+func (s *Scheduler) run(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+
+	unlock, ok, err := s.locker.TryLock(ctx, schedulerLock, s.interval)
+	if err != nil {
+		s.log.Error(ctx, "post scheduler", "status", "acquiring lock", "msg", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := unlock(context.Background()); err != nil {
+			s.log.Error(ctx, "post scheduler", "status", "releasing lock", "msg", err)
+		}
+	}()
+
+	n, err := s.core.PublishDue(ctx, time.Now(), schedulerBatch)
+	if err != nil {
+		s.log.Error(ctx, "post scheduler", "status", "publishing due posts", "published", n, "msg", err)
+		return
+	}
+	if n > 0 {
+		s.log.Info(ctx, "post scheduler", "status", "published due posts", "published", n)
+	}
+}
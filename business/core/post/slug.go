@@ -0,0 +1,180 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSlugLength is the largest number of characters of a slug, collision
+// suffixes included.
+const MaxSlugLength = 100
+
+// slugFallback is the slug of posts whose title has no character that can
+// be transliterated, e.g. titles in Chinese.
+const slugFallback = "post"
+
+// slugAttempts is the number of slugs tried on top of the known ones when
+// other posts reserve them in the meantime.
+const slugAttempts = 3
+
+// transliterations maps the letters that do not decompose to an ASCII letter
+// and a combining mark to their Latin spelling. Cyrillic follows the
+// Bulgarian streamlined system, extended with the Russian and Ukrainian
+// letters.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i",
+
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p",
+	'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "sht", 'ъ': "a", 'ь': "y", 'ю': "yu", 'я': "ya",
+	'ё': "yo", 'ы': "y", 'э': "e", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+// Slugify returns the URL-safe form of a title: lower case ASCII letters and
+// digits separated by single hyphens. Accented letters lose their accents
+// and Cyrillic letters are transliterated, other characters separate words.
+func Slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+
+	write := func(s string) {
+		if hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		hyphen = false
+		b.WriteString(s)
+	}
+
+	// Letters are transliterated before they are decomposed, 'й' is not an
+	// 'и' with a breve.
+	for _, r := range strings.ToLower(title) {
+		if t, ok := transliterations[r]; ok {
+			write(t)
+			continue
+		}
+
+		for _, d := range norm.NFD.String(string(r)) {
+			switch {
+			case d <= unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)):
+				write(string(d))
+			case unicode.Is(unicode.Mn, d):
+				// Combining marks left by the decomposition of accented letters.
+			case d == '\'' || d == '’':
+				// Apostrophes join the parts of a word, "don't" is "dont".
+			default:
+				hyphen = true
+			}
+		}
+	}
+
+	slug := truncateSlug(b.String(), MaxSlugLength)
+	if slug == "" {
+		return slugFallback
+	}
+
+	return slug
+}
+
+// truncateSlug cuts a slug to at most max characters, at a word boundary
+// when there is one.
+func truncateSlug(slug string, max int) string {
+	if len(slug) <= max {
+		return slug
+	}
+
+	slug = slug[:max]
+	if i := strings.LastIndexByte(slug, '-'); i > 0 {
+		slug = slug[:i]
+	}
+
+	return strings.TrimSuffix(slug, "-")
+}
+
+// slugCandidate returns the n-th slug tried for base, the first one being
+// base itself and the next ones carrying a numeric suffix.
+func slugCandidate(base string, n int) string {
+	if n == 1 {
+		return base
+	}
+
+	suffix := "-" + strconv.Itoa(n)
+	return truncateSlug(base, MaxSlugLength-len(suffix)) + suffix
+}
+
+// hasSlugBase reports whether slug is base or base with a collision suffix.
+func hasSlugBase(slug string, base string) bool {
+	if slug == base {
+		return true
+	}
+
+	i := strings.LastIndexByte(slug, '-')
+	if i < 0 {
+		return false
+	}
+
+	n, err := strconv.Atoi(slug[i+1:])
+	return err == nil && n > 1 && slugCandidate(base, n) == slug
+}
+
+// assignSlug gives post a slug made from its title. A post keeps its slug
+// while its title slugifies the same way, a slug the post had before is
+// reused. Slugs are reserved for good, the previous slugs of a post keep
+// leading to it.
+func (c *Core) assignSlug(ctx context.Context, post *Post) error {
+	base := Slugify(post.Title)
+	if post.Slug != "" && hasSlugBase(post.Slug, base) {
+		return nil
+	}
+
+	owners, err := c.storer.QuerySlugs(ctx, base)
+	if err != nil {
+		return fmt.Errorf("query slugs: %w", err)
+	}
+
+	// Candidates reserved by other posts since the query fail to be
+	// reserved, a few of them are tried before giving up.
+	for n := 1; n <= len(owners)+slugAttempts; n++ {
+		slug := slugCandidate(base, n)
+
+		if owner, taken := owners[slug]; taken {
+			if owner == post.Id {
+				post.Slug = slug
+				return nil
+			}
+			continue
+		}
+
+		err := c.storer.ReserveSlug(ctx, slug, post.Id)
+		switch {
+		case err == nil:
+			post.Slug = slug
+			return nil
+		case !errors.Is(err, ErrSlugTaken):
+			return fmt.Errorf("reserve slug: %w", err)
+		}
+	}
+
+	return fmt.Errorf("reserve slug: %w", ErrSlugTaken)
+}
+
+// QueryBySlug returns the post a slug leads to, the current slug of the
+// post or one of its previous slugs.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - slug: the slug of the post.
+func (c *Core) QueryBySlug(ctx context.Context, slug string) (Post, error) {
+	post, err := c.storer.QueryBySlug(ctx, slug)
+	if err != nil {
+		return Post{}, err
+	}
+
+	return post, nil
+}
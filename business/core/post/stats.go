@@ -0,0 +1,190 @@
+package post
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Set of rates used to estimate the reading time of a post.
+const (
+	// WordsPerMinute is the reading speed of an average adult.
+	WordsPerMinute = 230
+
+	// SecondsPerImage is the time spent looking at an image.
+	SecondsPerImage = 12
+)
+
+// Stats holds the figures computed from the content of a post, they are
+// stored with its metadata so listings show them without the content.
+// ReadingTime is in minutes and Language is an ISO 639-1 code, empty when
+// the language could not be told.
+type Stats struct {
+	WordCount   int
+	ReadingTime int
+	ImageCount  int
+	Language    string
+}
+
+// ComputeStats computes the statistics of the content of a post. Every
+// ideograph counts as a word, languages written without spaces have no other
+// word boundary.
+func ComputeStats(content Content) Stats {
+	var stats Stats
+	var words []string
+
+	for _, b := range content.Blocks {
+		if b.Type == BlockImage {
+			stats.ImageCount++
+			continue
+		}
+
+		for _, field := range strings.FieldsFunc(b.Content, isWordSeparator) {
+			ideographs := 0
+			for _, r := range field {
+				if isIdeograph(r) {
+					ideographs++
+				}
+			}
+
+			switch {
+			case ideographs > 0:
+				stats.WordCount += ideographs
+			case strings.IndexFunc(field, isWordRune) >= 0:
+				stats.WordCount++
+			}
+
+			// Code is written in English whatever the language of the post.
+			if b.Type != BlockCode {
+				words = append(words, strings.ToLower(field))
+			}
+		}
+	}
+
+	if stats.WordCount > 0 || stats.ImageCount > 0 {
+		seconds := float64(stats.WordCount)*60/WordsPerMinute + float64(stats.ImageCount*SecondsPerImage)
+		stats.ReadingTime = max(1, int(math.Ceil(seconds/60)))
+	}
+
+	stats.Language = guessLanguage(words)
+
+	return stats
+}
+
+// isWordSeparator reports whether r separates words. Apostrophes and
+// hyphens are part of words, "don't" and "e-mail" are one word each.
+func isWordSeparator(r rune) bool {
+	return !isWordRune(r) && r != '\'' && r != '’' && r != '-'
+}
+
+// isWordRune reports whether r can be part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// isIdeograph reports whether r belongs to a script written without spaces
+// between words.
+func isIdeograph(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai)
+}
+
+// =============================================================================
+
+// minLanguageHits is the number of stop words a text needs before its
+// language is guessed from them.
+const minLanguageHits = 3
+
+// scriptLanguages maps the scripts used by a single language to it.
+var scriptLanguages = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Greek, "el"},
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Thai, "th"},
+	{unicode.Hebrew, "he"},
+	{unicode.Arabic, "ar"},
+}
+
+// stopWords are the most frequent words of the languages told apart by
+// their vocabulary, those sharing the Latin or the Cyrillic script.
+var stopWords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "was", "on", "are", "this", "you"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "ein", "eine", "den", "von", "zu", "auf", "sich", "ich"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "du", "que", "pas", "pour", "dans", "qui", "sur", "avec"},
+	"es": {"el", "los", "las", "y", "es", "que", "del", "una", "por", "con", "para", "como", "pero", "su", "se"},
+	"it": {"il", "di", "che", "è", "gli", "della", "non", "per", "una", "sono", "con", "anche", "nel", "lo", "si"},
+	"bg": {"и", "на", "за", "се", "да", "е", "от", "не", "че", "са", "това", "като", "по", "които", "към"},
+	"ru": {"и", "в", "не", "на", "что", "он", "как", "это", "по", "но", "из", "у", "за", "его", "от"},
+}
+
+// stopWordLanguages indexes stopWords by word.
+var stopWordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopWords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+	return index
+}()
+
+// guessLanguage guesses the language of a text from its lower case words.
+// Scripts used by a single language decide on their own, other texts are
+// attributed to the language most of their stop words belong to.
+func guessLanguage(words []string) string {
+	scripts := make(map[string]int)
+	letters := 0
+	for _, w := range words {
+		for _, r := range w {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			letters++
+			for _, sl := range scriptLanguages {
+				if unicode.Is(sl.script, r) {
+					scripts[sl.language]++
+					break
+				}
+			}
+		}
+	}
+
+	// Japanese is written with kanji next to kana, the kanji are Han.
+	if scripts["ja"] > 0 {
+		scripts["ja"] += scripts["zh"]
+		delete(scripts, "zh")
+	}
+
+	for lang, n := range scripts {
+		if n*2 > letters {
+			return lang
+		}
+	}
+
+	hits := make(map[string]int)
+	for _, w := range words {
+		for _, lang := range stopWordLanguages[w] {
+			hits[lang]++
+		}
+	}
+
+	best, bestHits, tie := "", 0, false
+	for lang, n := range hits {
+		switch {
+		case n > bestHits:
+			best, bestHits, tie = lang, n, false
+		case n == bestHits:
+			tie = true
+		}
+	}
+
+	if bestHits < minLanguageHits || tie {
+		return ""
+	}
+
+	return best
+}
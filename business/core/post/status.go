@@ -0,0 +1,82 @@
+package post
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/validate"
+)
+
+// Set of statuses of the post lifecycle. Only published posts are public,
+// the others are visible to their author alone.
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// transitions maps each status to the statuses a post can move to from it.
+// Published posts are archived rather than turned back into drafts, their
+// URLs have been shared already.
+var transitions = map[string][]string{
+	StatusDraft:     {StatusDraft, StatusScheduled, StatusPublished},
+	StatusScheduled: {StatusDraft, StatusScheduled, StatusPublished},
+	StatusPublished: {StatusPublished, StatusArchived},
+	StatusArchived:  {StatusArchived, StatusPublished},
+}
+
+// Statuses returns the statuses of the post lifecycle.
+func Statuses() []string {
+	return []string{StatusDraft, StatusScheduled, StatusPublished, StatusArchived}
+}
+
+// ValidStatus reports whether status is one of the lifecycle statuses.
+func ValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// applyStatus moves post to status, publishAt being the requested
+// publication time of scheduled posts. Posts get their publication time when
+// they are published and lose it when they go back to being drafts.
+func applyStatus(post *Post, status string, publishAt time.Time, now time.Time) error {
+	allowed := false
+	for _, to := range transitions[post.Status] {
+		if to == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, post.Status, status)
+	}
+
+	if !publishAt.IsZero() && status != StatusScheduled {
+		return validate.NewFieldsError("publishAt", errors.New("publishAt can only be set on scheduled posts"))
+	}
+
+	switch status {
+	case StatusDraft:
+		post.PublishAt = time.Time{}
+
+	case StatusScheduled:
+		if publishAt.IsZero() {
+			publishAt = post.PublishAt
+		}
+		if !publishAt.After(now) {
+			return validate.NewFieldsError("publishAt", errors.New("scheduled posts need a publishAt in the future"))
+		}
+		post.PublishAt = publishAt
+
+	case StatusPublished:
+		if post.Status != StatusPublished && post.Status != StatusArchived {
+			post.PublishAt = now
+		}
+	}
+
+	post.Status = status
+
+	return nil
+}
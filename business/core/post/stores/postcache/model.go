@@ -10,14 +10,26 @@ type dbPost struct {
 	Id           	int64   		`json:"id"`
 	UserId       	int64	 		`json:"userId"`
 	Title        	string   		`json:"title"`
+	Slug        	string   		`json:"slug,omitempty"`
 	Description 	string 			`json:"description"`
 	FrontImage  	string 			`json:"frontImage"`
 	ContentId   	int64   		`json:"contentId"`
 	Content   		dbContent   	`json:"content"`
+	Stats       	dbStats      	`json:"stats"`
+	Status      	string   		`json:"status"`
+	PublishAt   	time.Time   	`json:"publishAt"`
 	CreatedAt   	time.Time   	`json:"createdAt"`
 	UpdatedAt  		time.Time   	`json:"updatedAt"`
 }
 
+// dbStats contains the statistics computed from the content of a post.
+type dbStats struct {
+	WordCount   int    `json:"wordCount"`
+	ReadingTime int    `json:"readingTime"`
+	ImageCount  int    `json:"imageCount"`
+	Language    string `json:"language"`
+}
+
 // Content contains the entire content of a post.
 type dbContent struct {
 	Blocks []dbBlock `json:"blocks"`
@@ -37,6 +49,7 @@ type dbStyle struct {
 	Offset int    `json:"offset"`
 	Length int    `json:"length"`
 	Style  string `json:"style"`
+	URL    string `json:"url,omitempty"`
 }
 
 func toDBPost(post post.Post) dbPost {
@@ -44,12 +57,16 @@ func toDBPost(post post.Post) dbPost {
 		Id: post.Id,
 		UserId: post.UserId,
 		Title: post.Title,
+		Slug: post.Slug,
 		Description: post.Description,
 		FrontImage: post.FrontImage,
 		ContentId: post.ContentId,
 		Content: toDbContent(post.Content),
+		Stats: dbStats(post.Stats),
+		Status: post.Status,
+		PublishAt: post.PublishAt,
 		CreatedAt: post.CreatedAt,
-		UpdatedAt: post.CreatedAt,
+		UpdatedAt: post.UpdatedAt,
 	}
 }
 
@@ -83,22 +100,33 @@ func toDbStyles(styles []post.Style) []dbStyle {
 			Offset: s.Offset,
 			Length: s.Length,
 			Style:  s.Style,
+			URL:    s.URL,
 		}
 	}
 	return converted
 }
 
 func toCorePost(p dbPost) post.Post {
+	// Posts cached before the status lifecycle existed were all public.
+	status := p.Status
+	if status == "" {
+		status = post.StatusPublished
+	}
+
 	return post.Post{
 		Id: p.Id,
 		UserId: p.UserId,
 		Title: p.Title,
+		Slug: p.Slug,
 		Description: p.Description,
 		FrontImage: p.FrontImage,
 		ContentId: p.ContentId,
 		Content: toCoreContent(p.Content),
+		Stats: post.Stats(p.Stats),
+		Status: status,
+		PublishAt: p.PublishAt,
 		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
 	}
 }
 
@@ -132,6 +160,7 @@ func toCoreStyles(styles  []dbStyle) []post.Style {
 			Offset: s.Offset,
 			Length: s.Length,
 			Style:  s.Style,
+			URL:    s.URL,
 		}
 	}
 	return converted
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/hpetrov29/resttemplate/business/core/post"
 	"github.com/hpetrov29/resttemplate/business/data/cache"
 	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/metrics"
 )
 
 // Store manages the set of APIs for posts database access.
@@ -24,28 +26,130 @@ func NewStore (log *logger.Logger, cache cache.Cache) *Store {
 	}
 }
 
+// postTTL is how long a post stays in the cache.
+const postTTL = 5 * time.Second
+
+// CreatePost caches a post, along with the id its slug leads to.
 func (s *Store) CreatePost(ctx context.Context, post post.Post) (error) {
 	data, _ := json.Marshal(toDBPost(post))
-	return s.CacheStore.SetWithTTL(ctx, fmt.Sprintf("posts:%d", post.Id), data, 5*time.Second)
+	if err := s.CacheStore.SetWithTTL(ctx, postKey(post.Id), data, postTTL); err != nil {
+		return err
+	}
+
+	if post.Slug == "" {
+		return nil
+	}
+	return s.CacheStore.SetWithTTL(ctx, slugKey(post.Slug), []byte(strconv.FormatInt(post.Id, 10)), postTTL)
+}
+
+// CreatePosts caches a set of posts, along with the ids their slugs lead to,
+// in a single round trip.
+func (s *Store) CreatePosts(ctx context.Context, posts []post.Post) error {
+	values := make(map[string][]byte, 2*len(posts))
+	for _, p := range posts {
+		data, _ := json.Marshal(toDBPost(p))
+		values[postKey(p.Id)] = data
+
+		if p.Slug != "" {
+			values[slugKey(p.Slug)] = []byte(strconv.FormatInt(p.Id, 10))
+		}
+	}
+
+	return s.CacheStore.MSetWithTTL(ctx, values, postTTL)
 }
 
 func (s *Store) DeletePost(ctx context.Context, id int64) error {
+	return s.CacheStore.Delete(ctx, postKey(id))
+}
+
+// DeleteSlugs drops the cached post ids of a set of slugs.
+func (s *Store) DeleteSlugs(ctx context.Context, slugs []string) error {
+	for _, slug := range slugs {
+		if err := s.CacheStore.Delete(ctx, slugKey(slug)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (s *Store) QueryPostById(ctx context.Context, id int64) (post.Post, bool, error) {
 	var postData dbPost // change to db post
 
-	data, ok, err := s.CacheStore.GetNonFatal(ctx, fmt.Sprintf("posts:%d", id))
+	data, ok, err := s.CacheStore.GetNonFatal(ctx, postKey(id))
 	if err != nil {
 		return post.Post{}, false, err
 	}
 	if !ok {
+		metrics.CacheMiss("posts")
 		return post.Post{}, false, nil
 	}
+	metrics.CacheHit("posts")
 
 	if err = json.Unmarshal(data, &postData); err != nil {
 		return post.Post{}, false, err
 	}
 	return toCorePost(postData), true, nil
-}
\ No newline at end of file
+}
+
+// QueryPostsByIds returns the cached posts among a set of ids, indexed by
+// their id. Posts that are not cached are left out.
+func (s *Store) QueryPostsByIds(ctx context.Context, ids []int64) (map[int64]post.Post, error) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = postKey(id)
+	}
+
+	values, err := s.CacheStore.MGet(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make(map[int64]post.Post, len(ids))
+	for i, data := range values {
+		if data == nil {
+			metrics.CacheMiss("posts")
+			continue
+		}
+		metrics.CacheHit("posts")
+
+		var postData dbPost
+		if err := json.Unmarshal(data, &postData); err != nil {
+			return nil, err
+		}
+		posts[ids[i]] = toCorePost(postData)
+	}
+
+	return posts, nil
+}
+
+// QueryPostIdBySlug returns the id of the post a slug leads to. Only the
+// current slugs of cached posts are found, previous slugs are not cached.
+func (s *Store) QueryPostIdBySlug(ctx context.Context, slug string) (int64, bool, error) {
+	data, ok, err := s.CacheStore.GetNonFatal(ctx, slugKey(slug))
+	if err != nil {
+		return 0, false, err
+	}
+	if !ok {
+		metrics.CacheMiss("post_slugs")
+		return 0, false, nil
+	}
+	metrics.CacheHit("post_slugs")
+
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// postKey returns the cache key of a post.
+func postKey(id int64) string {
+	return fmt.Sprintf("posts:%d", id)
+}
+
+// slugKey returns the cache key of the post id a slug leads to. The entry is
+// dropped when the post is updated or deleted, a deleted post releases its
+// slugs to other posts.
+func slugKey(slug string) string {
+	return "posts:slug:" + slug
+}
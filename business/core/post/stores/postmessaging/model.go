@@ -0,0 +1,29 @@
+package postmessaging
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// PublishedEvent is the message sent when a post becomes public.
+type PublishedEvent struct {
+	PostId    int64     `json:"postId"`
+	UserId    int64     `json:"userId"`
+	Title     string    `json:"title"`
+	PublishAt time.Time `json:"publishAt"`
+}
+
+func toPublishedEvent(p post.Post) PublishedEvent {
+	return PublishedEvent{
+		PostId:    p.Id,
+		UserId:    p.UserId,
+		Title:     p.Title,
+		PublishAt: p.PublishAt.UTC(),
+	}
+}
+
+func toBytes(e PublishedEvent) ([]byte, error) {
+	return json.Marshal(e)
+}
@@ -0,0 +1,37 @@
+package postmessaging
+
+import (
+	"context"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/data/messaging"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+)
+
+// SubjectPublished is the subject of the "post published" events.
+const SubjectPublished = "posts.published"
+
+// Store publishes the post events on the messaging queue.
+type Store struct {
+	log    			*logger.Logger
+	MessagingQueue 	messaging.MessagingQueue
+	Subject 		string
+}
+
+func NewStore(log *logger.Logger, mq messaging.MessagingQueue, subject string) *Store {
+	return &Store{
+		log: log,
+		MessagingQueue: mq,
+		Subject: subject,
+	}
+}
+
+// Published sends the "post published" event of p.
+func (s *Store) Published(ctx context.Context, p post.Post) error {
+	data, err := toBytes(toPublishedEvent(p))
+	if err != nil {
+		return err
+	}
+
+	return s.MessagingQueue.Publish(ctx, s.Subject, data)
+}
@@ -21,6 +21,7 @@ type Style struct {
 	Offset int    `bson:"offset"`
 	Length int    `bson:"length"`
 	Style  string `bson:"style"`
+	URL    string `bson:"url,omitempty"`
 }
 
 // toDbContent returns the content stored inside a post.Post (core layer) instance
@@ -58,6 +59,7 @@ func toDbStyles(styles []post.Style) []Style {
 			Offset: s.Offset,
 			Length: s.Length,
 			Style:  s.Style,
+			URL:    s.URL,
 		}
 	}
 	return converted
@@ -93,6 +95,7 @@ func toCoreStyles(styles []Style) []post.Style {
 			Offset: s.Offset,
 			Length: s.Length,
 			Style:  s.Style,
+			URL:    s.URL,
 		}
 	}
 	return converted
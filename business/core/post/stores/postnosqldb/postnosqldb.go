@@ -29,6 +29,10 @@ func (s *Store) Create(ctx context.Context, content post.Content, contentId int6
 	return nil
 }
 
+func (s *Store) Update(ctx context.Context, content post.Content, contentId int64) error {
+	return s.NOSQLstore.Replace(ctx, contentId, toDbContent(content, contentId))
+}
+
 func (s *Store) Delete(ctx context.Context, id int64) error {
 	return s.NOSQLstore.Delete(ctx, uint64(id))
 }
@@ -42,3 +46,24 @@ func (s *Store) QueryById(ctx context.Context, id int64) (post.Content, error) {
 
 	return toCoreContent(content), nil
 }
+
+// QueryByIds returns the contents among a set of content ids, indexed by
+// their id. Ids without a content are left out.
+func (s *Store) QueryByIds(ctx context.Context, ids []int64) (map[int64]post.Content, error) {
+	if len(ids) == 0 {
+		return map[int64]post.Content{}, nil
+	}
+
+	var contents []Content
+	filter := dbnosql.Filter{"_id": dbnosql.Filter{"$in": ids}}
+	if err := s.NOSQLstore.Query(ctx, filter, dbnosql.Sort{}, 0, &contents); err != nil {
+		return nil, err
+	}
+
+	items := make(map[int64]post.Content, len(contents))
+	for _, c := range contents {
+		items[c.Id] = toCoreContent(c)
+	}
+
+	return items, nil
+}
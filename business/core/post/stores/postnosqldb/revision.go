@@ -0,0 +1,145 @@
+package postnosqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/data/dbnosql"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+)
+
+// RevisionStore manages the set of APIs for post revisions database access.
+type RevisionStore struct {
+	log        *logger.Logger
+	NOSQLstore dbnosql.NOSQLDBrepo
+}
+
+func NewRevisionStore(log *logger.Logger, nosqlStore dbnosql.NOSQLDBrepo) *RevisionStore {
+	return &RevisionStore{
+		log:        log,
+		NOSQLstore: nosqlStore,
+	}
+}
+
+// Create inserts a revision. The document id is made of the content id and
+// the version, a second revision with the same version is refused.
+func (s *RevisionStore) Create(ctx context.Context, rev post.Revision) error {
+	err := s.NOSQLstore.Insert(ctx, toDbRevision(rev))
+	if err != nil {
+		if errors.Is(err, dbnosql.ErrDuplicateKey) {
+			return fmt.Errorf("revision %d of content %d: %w", rev.Version, rev.ContentId, post.ErrConcurrentEdit)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes a revision of a content.
+func (s *RevisionStore) Delete(ctx context.Context, contentId int64, version int) error {
+	return s.NOSQLstore.DeleteMany(ctx, dbnosql.Filter{"_id": revisionId(contentId, version)})
+}
+
+// DeleteAll deletes every revision of a content.
+func (s *RevisionStore) DeleteAll(ctx context.Context, contentId int64) error {
+	return s.NOSQLstore.DeleteMany(ctx, dbnosql.Filter{"content_id": contentId})
+}
+
+// QueryAll returns the revisions of a content ordered by version.
+func (s *RevisionStore) QueryAll(ctx context.Context, contentId int64) ([]post.Revision, error) {
+	var revs []Revision
+
+	err := s.NOSQLstore.Query(ctx, dbnosql.Filter{"content_id": contentId}, dbnosql.Sort{Field: "version"}, 0, &revs)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCoreRevisions(revs), nil
+}
+
+// QueryByVersion returns a revision of a content.
+func (s *RevisionStore) QueryByVersion(ctx context.Context, contentId int64, version int) (post.Revision, error) {
+	return s.queryOne(ctx, dbnosql.Filter{"_id": revisionId(contentId, version)}, dbnosql.Sort{})
+}
+
+// QueryLatest returns the revision of a content with the highest version.
+func (s *RevisionStore) QueryLatest(ctx context.Context, contentId int64) (post.Revision, error) {
+	return s.queryOne(ctx, dbnosql.Filter{"content_id": contentId}, dbnosql.Sort{Field: "version", Descending: true})
+}
+
+func (s *RevisionStore) queryOne(ctx context.Context, filter dbnosql.Filter, sort dbnosql.Sort) (post.Revision, error) {
+	var rev Revision
+
+	err := s.NOSQLstore.QueryOne(ctx, filter, sort, &rev)
+	if err != nil {
+		if errors.Is(err, dbnosql.ErrNotFound) {
+			return post.Revision{}, post.ErrRevisionNotFound
+		}
+		return post.Revision{}, err
+	}
+
+	return toCoreRevision(rev), nil
+}
+
+// =============================================================================
+
+// Revision is the document of a post revision. The id joins the content id
+// and the version, e.g. "7301:3", which keeps revisions unique without an
+// extra index.
+type Revision struct {
+	Id          string    `bson:"_id"`
+	ContentId   int64     `bson:"content_id"`
+	Version     int       `bson:"version"`
+	PostId      int64     `bson:"post_id"`
+	Title       string    `bson:"title"`
+	Description string    `bson:"description"`
+	FrontImage  string    `bson:"front_image,omitempty"`
+	Blocks      []Block   `bson:"blocks"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
+
+// revisionId returns the document id of a revision.
+func revisionId(contentId int64, version int) string {
+	return fmt.Sprintf("%d:%d", contentId, version)
+}
+
+// Converts post.Revision (core layer) to Revision (repository layer)
+func toDbRevision(rev post.Revision) Revision {
+	return Revision{
+		Id:          revisionId(rev.ContentId, rev.Version),
+		ContentId:   rev.ContentId,
+		Version:     rev.Version,
+		PostId:      rev.PostId,
+		Title:       rev.Title,
+		Description: rev.Description,
+		FrontImage:  rev.FrontImage,
+		Blocks:      toDbBlocks(rev.Content.Blocks),
+		CreatedAt:   rev.CreatedAt.UTC(),
+	}
+}
+
+// Converts Revision (repository layer) to post.Revision (core layer)
+func toCoreRevision(rev Revision) post.Revision {
+	return post.Revision{
+		ContentId:   rev.ContentId,
+		Version:     rev.Version,
+		PostId:      rev.PostId,
+		Title:       rev.Title,
+		Description: rev.Description,
+		FrontImage:  rev.FrontImage,
+		Content:     post.Content{Blocks: toCoreBlocks(rev.Blocks)},
+		CreatedAt:   rev.CreatedAt,
+	}
+}
+
+// Converts a slice of Revision (repository layer) to a slice of post.Revision (core layer)
+func toCoreRevisions(revs []Revision) []post.Revision {
+	converted := make([]post.Revision, len(revs))
+	for i, r := range revs {
+		converted[i] = toCoreRevision(r)
+	}
+	return converted
+}
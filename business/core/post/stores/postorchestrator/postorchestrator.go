@@ -12,23 +12,35 @@ import (
 // Methods that have to be implemented:
 /*
 	Create(ctx context.Context, post Post) (error)
+	Update(ctx context.Context, post Post) error
 	Delete(ctx context.Context, post Post) error
+	Publish(ctx context.Context, post Post) (bool, error)
 	QueryById(ctx context.Context, id int64) (Post, error)
+	QueryByIds(ctx context.Context, ids []int64) ([]Post, error)
+	QueryBySlug(ctx context.Context, slug string) (Post, error)
 	Query(ctx context.Context, filter QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]Post, error)
+	QuerySlugs(ctx context.Context, base string) (map[string]int64, error)
+	ReserveSlug(ctx context.Context, slug string, postId int64) error
+	CreateRevision(ctx context.Context, rev Revision) error
+	QueryRevisions(ctx context.Context, contentId int64) ([]Revision, error)
+	QueryRevision(ctx context.Context, contentId int64, version int) (Revision, error)
+	QueryLatestRevision(ctx context.Context, contentId int64) (Revision, error)
 */
 type Store struct {
 	log 	*logger.Logger
 	Cache 	post.CacheStore
 	SQL   	post.SQLstore
 	NOSQL 	post.NOSQLStore
+	Revisions post.RevisionStore
 }
 
-func NewStore(log *logger.Logger, cache post.CacheStore, sql post.SQLstore, nosql post.NOSQLStore) *Store {
+func NewStore(log *logger.Logger, cache post.CacheStore, sql post.SQLstore, nosql post.NOSQLStore, revisions post.RevisionStore) *Store {
 	return &Store{
 		log: log,
 		Cache: cache,
 		SQL: sql,
 		NOSQL: nosql,
+		Revisions: revisions,
 	}
 }
 
@@ -45,17 +57,67 @@ func (o *Store) Create(ctx context.Context, post post.Post) error {
 	return o.Cache.CreatePost(ctx, post)
 }
 
+func (o *Store) Update(ctx context.Context, post post.Post) error {
+	// update the post metadata in the sql repo
+	// replace the post's content in the nosql repo
+	// drop the cached copy so the next read picks up the changes
+	// drop the cached slugs of the post, the slug may have changed
+	if err := o.SQL.Update(ctx, post); err != nil {
+		return err
+	}
+	if err := o.NOSQL.Update(ctx, post.Content, post.ContentId); err != nil {
+		return err
+	}
+	if err := o.Cache.DeletePost(ctx, post.Id); err != nil {
+		return err
+	}
+	return o.deleteCachedSlugs(ctx, post.Id)
+}
+
 func (o *Store) Delete(ctx context.Context, post post.Post) error {
-	// first, delete post in the sql repo
+	// first, delete post in the sql repo and release its slugs
 	// second, delete the post's content in the nosql repo
-	// third, if present in cache, delete
+	// third, delete the history of the post's content
+	// fourth, if present in cache, delete the post and its slugs
+	slugs, err := o.SQL.QueryPostSlugs(ctx, post.Id)
+	if err != nil {
+		return err
+	}
 	if err := o.SQL.Delete(ctx, post.Id); err != nil {
 		return err
 	}
+	if err := o.SQL.ReleaseSlugs(ctx, post.Id); err != nil {
+		return err
+	}
 	if err := o.NOSQL.Delete(ctx, post.ContentId); err != nil {
 		return err
 	}
-	return o.Cache.DeletePost(ctx, post.Id)
+	if err := o.Revisions.DeleteAll(ctx, post.ContentId); err != nil {
+		return err
+	}
+	if err := o.Cache.DeletePost(ctx, post.Id); err != nil {
+		return err
+	}
+	return o.Cache.DeleteSlugs(ctx, slugs)
+}
+
+// deleteCachedSlugs drops the cached ids of every slug the post has or had.
+func (o *Store) deleteCachedSlugs(ctx context.Context, postId int64) error {
+	slugs, err := o.SQL.QueryPostSlugs(ctx, postId)
+	if err != nil {
+		return err
+	}
+	return o.Cache.DeleteSlugs(ctx, slugs)
+}
+
+func (o *Store) Publish(ctx context.Context, post post.Post) (bool, error) {
+	// only the metadata changes, the content stays as it is
+	// drop the cached copy, it still holds the scheduled status
+	ok, err := o.SQL.Publish(ctx, post)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return true, o.Cache.DeletePost(ctx, post.Id)
 }
 
 func (o *Store) QueryById(ctx context.Context, id int64) (post.Post, error) {
@@ -83,7 +145,111 @@ func (o *Store) QueryById(ctx context.Context, id int64) (post.Post, error) {
 	return p, nil
 }
 
+func (o *Store) QueryByIds(ctx context.Context, ids []int64) ([]post.Post, error) {
+	// first, get the cached posts in a single MGET
+	// second, get the metadata of the others in a single sql IN query
+	// third, get their contents in a single nosql $in query
+	// fourth, cache them in a single pipeline
+	cached, err := o.Cache.QueryPostsByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]post.Post, 0, len(ids))
+	var misses []int64
+	for _, id := range ids {
+		if p, ok := cached[id]; ok {
+			posts = append(posts, p)
+			continue
+		}
+		misses = append(misses, id)
+	}
+
+	if len(misses) == 0 {
+		return posts, nil
+	}
+
+	stored, err := o.SQL.QueryByIds(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	contentIds := make([]int64, len(stored))
+	for i, p := range stored {
+		contentIds[i] = p.ContentId
+	}
+	contents, err := o.NOSQL.QueryByIds(ctx, contentIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// a post whose content is missing cannot be shown, it is left out like
+	// the ids without a post
+	loaded := make([]post.Post, 0, len(stored))
+	for _, p := range stored {
+		content, ok := contents[p.ContentId]
+		if !ok {
+			o.log.Error(ctx, "post content not found", "postId", p.Id, "contentId", p.ContentId)
+			continue
+		}
+		p.Content = content
+		loaded = append(loaded, p)
+	}
+
+	if err := o.Cache.CreatePosts(ctx, loaded); err != nil {
+		return nil, err
+	}
+
+	return append(posts, loaded...), nil
+}
+
+func (o *Store) QueryBySlug(ctx context.Context, slug string) (post.Post, error) {
+	// resolve the slug to the post id through the cache, then the sql repo
+	// load the post itself the same way as by id
+	id, ok, err := o.Cache.QueryPostIdBySlug(ctx, slug)
+	if err != nil {
+		return post.Post{}, err
+	}
+
+	if !ok {
+		if id, err = o.SQL.QueryIdBySlug(ctx, slug); err != nil {
+			return post.Post{}, err
+		}
+	}
+
+	return o.QueryById(ctx, id)
+}
+
 func (o *Store) Query(ctx context.Context, filter post.QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]post.Post, error) {
 	//only call the sql store since the nosql store doesnt care about post metadata
 	return o.SQL.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
 }
+
+func (o *Store) QuerySlugs(ctx context.Context, base string) (map[string]int64, error) {
+	return o.SQL.QuerySlugs(ctx, base)
+}
+
+func (o *Store) ReserveSlug(ctx context.Context, slug string, postId int64) error {
+	return o.SQL.ReserveSlug(ctx, slug, postId)
+}
+
+func (o *Store) CreateRevision(ctx context.Context, rev post.Revision) error {
+	// revisions only live in the nosql repo, they are never cached
+	return o.Revisions.Create(ctx, rev)
+}
+
+func (o *Store) DeleteRevision(ctx context.Context, contentId int64, version int) error {
+	return o.Revisions.Delete(ctx, contentId, version)
+}
+
+func (o *Store) QueryRevisions(ctx context.Context, contentId int64) ([]post.Revision, error) {
+	return o.Revisions.QueryAll(ctx, contentId)
+}
+
+func (o *Store) QueryRevision(ctx context.Context, contentId int64, version int) (post.Revision, error) {
+	return o.Revisions.QueryByVersion(ctx, contentId, version)
+}
+
+func (o *Store) QueryLatestRevision(ctx context.Context, contentId int64) (post.Revision, error) {
+	return o.Revisions.QueryLatest(ctx, contentId)
+}
@@ -7,30 +7,82 @@ import (
 	"github.com/hpetrov29/resttemplate/business/core/post"
 )
 
+// likeEscaper escapes the wildcards of LIKE patterns, the texts matched are
+// taken literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// applyFilter writes the WHERE clause of filter, its conditions joined with
+// AND. Lists of values are bound as IN clauses, the query must be run with
+// mysql.NamedQuerySliceUsingIn.
 func (s *Store) applyFilter(filter post.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
 	var wc []string
-	if filter.UserId != nil {
-		data["user_id"] = filter.UserId
-		wc = append(wc, "user_id = :user_id")
+	if len(filter.UserIds) > 0 {
+		data["user_ids"] = filter.UserIds
+		wc = append(wc, "user_id IN (:user_ids)")
+	}
+
+	if len(filter.ExcludeUserIds) > 0 {
+		data["exclude_user_ids"] = filter.ExcludeUserIds
+		wc = append(wc, "user_id NOT IN (:exclude_user_ids)")
+	}
+
+	if len(filter.ExcludeIds) > 0 {
+		data["exclude_ids"] = filter.ExcludeIds
+		wc = append(wc, "id NOT IN (:exclude_ids)")
+	}
+
+	if filter.Title != nil {
+		data["title"] = "%" + likeEscaper.Replace(*filter.Title) + "%"
+		wc = append(wc, "title LIKE :title")
+	}
+
+	if filter.Description != nil {
+		data["description"] = "%" + likeEscaper.Replace(*filter.Description) + "%"
+		wc = append(wc, "description LIKE :description")
+	}
+
+	if filter.StartCreatedAt != nil {
+		data["start_created_at"] = filter.StartCreatedAt.UTC()
+		wc = append(wc, "created_at >= :start_created_at")
+	}
+
+	if filter.EndCreatedAt != nil {
+		data["end_created_at"] = filter.EndCreatedAt.UTC()
+		wc = append(wc, "created_at <= :end_created_at")
+	}
+
+	if filter.StartUpdatedAt != nil {
+		data["start_updated_at"] = filter.StartUpdatedAt.UTC()
+		wc = append(wc, "updated_at >= :start_updated_at")
+	}
+
+	if filter.EndUpdatedAt != nil {
+		data["end_updated_at"] = filter.EndUpdatedAt.UTC()
+		wc = append(wc, "updated_at <= :end_updated_at")
+	}
+
+	if filter.Status != nil {
+		data["status"] = *filter.Status
+		wc = append(wc, "status = :status")
 	}
 
-	var timeConditions []string
-	if filter.CreatedAt != nil {
-		data["created_at"] = *filter.CreatedAt
-		timeConditions = append(timeConditions, "created_at > :created_at")
+	if filter.PublishBefore != nil {
+		data["publish_before"] = filter.PublishBefore.UTC()
+		wc = append(wc, "publish_at <= :publish_before")
 	}
 
-	if filter.UpdatedAt != nil {
-		data["updated_at"] = *filter.UpdatedAt
-		timeConditions = append(timeConditions, "updated_at > :updated_at")
+	if filter.MinReadingTime != nil {
+		data["min_reading_time"] = *filter.MinReadingTime
+		wc = append(wc, "reading_time >= :min_reading_time")
 	}
 
-	if len(timeConditions) > 0 {
-		wc = append(wc, "("+strings.Join(timeConditions, " OR ")+")")
+	if filter.MaxReadingTime != nil {
+		data["max_reading_time"] = *filter.MaxReadingTime
+		wc = append(wc, "reading_time <= :max_reading_time")
 	}
 
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
 	}
-}
\ No newline at end of file
+}
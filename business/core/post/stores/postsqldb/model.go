@@ -1,6 +1,7 @@
 package postsqldb
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/hpetrov29/resttemplate/business/core/post"
@@ -12,9 +13,16 @@ type dbPost struct {
 	Id          int64    	`db:"id"`
 	UserId      int64    	`db:"user_id"`
 	Title       string    	`db:"title"`
+	Slug        sql.NullString `db:"slug"`
 	Description string    	`db:"description"`
 	FrontImage  string    	`db:"front_image"`
 	ContentId   int64    	`db:"content_id"`
+	WordCount   int         `db:"word_count"`
+	ReadingTime int         `db:"reading_time"`
+	ImageCount  int         `db:"image_count"`
+	Language    string      `db:"language"`
+	Status      string      `db:"status"`
+	PublishAt   sql.NullTime `db:"publish_at"`
 	CreatedAt   time.Time 	`db:"created_at"`
 	UpdatedAt   time.Time 	`db:"updated_at"`
 }
@@ -25,9 +33,16 @@ func toDBPost(post post.Post) dbPost {
 		Id:           	post.Id,
 		UserId: 		post.UserId,	
 		Title:          post.Title,
+		Slug: 			toNullString(post.Slug),
 		Description:    post.Description,
 		FrontImage: 	post.FrontImage,
 		ContentId: 		post.ContentId,
+		WordCount: 		post.Stats.WordCount,
+		ReadingTime: 	post.Stats.ReadingTime,
+		ImageCount: 	post.Stats.ImageCount,
+		Language: 		post.Stats.Language,
+		Status: 		post.Status,
+		PublishAt: 		toNullTime(post.PublishAt),
 		CreatedAt: 		post.CreatedAt.UTC(),
 		UpdatedAt: 		post.UpdatedAt.UTC(),
 	}
@@ -39,9 +54,18 @@ func toCorePost(dbPost dbPost) post.Post {
 		Id:           	dbPost.Id,
 		UserId: 		dbPost.UserId,	
 		Title:          dbPost.Title,
+		Slug: 			dbPost.Slug.String,
 		Description:    dbPost.Description,
 		FrontImage: 	dbPost.FrontImage,
 		ContentId: 		dbPost.ContentId,
+		Stats: post.Stats{
+			WordCount:   dbPost.WordCount,
+			ReadingTime: dbPost.ReadingTime,
+			ImageCount:  dbPost.ImageCount,
+			Language:    dbPost.Language,
+		},
+		Status: 		dbPost.Status,
+		PublishAt: 		fromNullTime(dbPost.PublishAt),
 		CreatedAt: 		dbPost.CreatedAt.In(time.Local),
 		UpdatedAt: 		dbPost.UpdatedAt.In(time.Local),
 	}
@@ -56,4 +80,33 @@ func toCorePostSlice(dbPosts []dbPost) []post.Post {
 		prds[i] = toCorePost(dbPost)
 	}
 	return prds
-}
\ No newline at end of file
+}
+// toNullString stores posts without a slug, created before slugs existed,
+// as NULL so the unique index ignores them.
+func toNullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
+
+func toNullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromNullTime(t sql.NullTime) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+	return t.Time.In(time.Local)
+}
+
+// dbSlug represents a slug reserved for a post, current or previous.
+type dbSlug struct {
+	Slug      string    `db:"slug"`
+	PostId    int64     `db:"post_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
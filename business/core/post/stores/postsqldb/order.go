@@ -11,6 +11,8 @@ import (
 var orderByFields = map[string]string{
 	post.OrderByCreatedAt:  "created_at",
 	post.OrderByUpdatedAt:  "updated_at",
+	post.OrderByPublishAt:  "publish_at",
+	post.OrderByReadingTime: "reading_time",
 }
 
 func (s *Store) orderByClause(orderBy order.OrderBy, buf *bytes.Buffer) (error) {
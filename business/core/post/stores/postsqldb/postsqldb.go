@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hpetrov29/resttemplate/business/core/post"
 
@@ -56,9 +58,9 @@ type SQLstore interface {
 func (s *Store) Create(ctx context.Context, post post.Post) (error) {
 	const q = `
 	INSERT INTO posts
-		(id, user_id, title, description, content_id, created_at, updated_at)
+		(id, user_id, title, slug, description, front_image, content_id, word_count, reading_time, image_count, language, status, publish_at, created_at, updated_at)
 	VALUES
-		(:id, :user_id, :title, :description, :content_id, :created_at, :updated_at);`
+		(:id, :user_id, :title, :slug, :description, :front_image, :content_id, :word_count, :reading_time, :image_count, :language, :status, :publish_at, :created_at, :updated_at);`
 	
 	_, err := mysql.NamedExecContext(ctx, s.log, s.db, q, toDBPost(post)); 
 	
@@ -69,6 +71,40 @@ func (s *Store) Create(ctx context.Context, post post.Post) (error) {
 	return nil
 }
 
+// Update modifies the metadata of a post in the database.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - post: the post with the updated metadata.
+//
+// Returns:
+//   - error: an error if the update fails. If successful, returns nil.
+func (s *Store) Update(ctx context.Context, post post.Post) error {
+	const q = `
+	UPDATE
+		posts
+	SET
+		title = :title,
+		slug = :slug,
+		description = :description,
+		front_image = :front_image,
+		word_count = :word_count,
+		reading_time = :reading_time,
+		image_count = :image_count,
+		language = :language,
+		status = :status,
+		publish_at = :publish_at,
+		updated_at = :updated_at
+	WHERE
+		id = :id`
+
+	if _, err := mysql.NamedExecContext(ctx, s.log, s.db, q, toDBPost(post)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
 // Delete removes a post from the database based on the post's Id.
 //
 // Parameters:
@@ -97,6 +133,40 @@ func (s *Store) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// Publish moves a scheduled post to the status of post. The update only
+// applies while the post is still scheduled, so concurrent schedulers agree
+// on which of them published it.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - post: the post with its new status.
+//
+// Returns:
+//   - bool: true if this call published the post.
+//   - error: an error if the update fails.
+func (s *Store) Publish(ctx context.Context, post post.Post) (bool, error) {
+	const q = `
+	UPDATE
+		posts
+	SET
+		status = :status,
+		updated_at = :updated_at
+	WHERE
+		id = :id AND status = "scheduled"`
+
+	res, err := mysql.NamedExecContext(ctx, s.log, s.db, q, toDBPost(post))
+	if err != nil {
+		return false, fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rowsaffected: %w", err)
+	}
+
+	return rows == 1, nil
+}
+
 func (s *Store) QueryById(ctx context.Context, id int64) (post.Post, error) {
 	data := struct {
 		Id int64 `db:"id"`
@@ -104,7 +174,7 @@ func (s *Store) QueryById(ctx context.Context, id int64) (post.Post, error) {
 		Id: id,
 	}
 
-	const postQuery = `SELECT id, user_id, title, description, front_image, content_id, created_at, updated_at FROM posts WHERE id = :id;`
+	const postQuery = `SELECT id, user_id, title, slug, description, front_image, content_id, word_count, reading_time, image_count, language, status, publish_at, created_at, updated_at FROM posts WHERE id = :id;`
 	
 	var dbPost dbPost
 
@@ -120,6 +190,186 @@ func (s *Store) QueryById(ctx context.Context, id int64) (post.Post, error) {
 	return post, nil
 }
 
+// QueryIdBySlug returns the id of the post a slug was reserved for, the
+// slug being the current one of the post or one it had before.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - slug: the slug of the post.
+//
+// Returns:
+//   - int64: the id of the post.
+//   - error: post.ErrNotFound if no post has or had the slug.
+func (s *Store) QueryIdBySlug(ctx context.Context, slug string) (int64, error) {
+	data := struct {
+		Slug string `db:"slug"`
+	}{
+		Slug: slug,
+	}
+
+	const q = `SELECT slug, post_id FROM post_slugs WHERE slug = :slug;`
+
+	var dbSlug dbSlug
+
+	if err := mysql.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbSlug); err != nil {
+		if errors.Is(err, mysql.ErrDBNotFound) {
+			return 0, fmt.Errorf("namedquerystruct: %w", post.ErrNotFound)
+		}
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return dbSlug.PostId, nil
+}
+
+// QuerySlugs returns the reserved slugs equal to base or starting with base
+// and a hyphen, mapped to the id of the post they were reserved for.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - base: the slug made from the title of a post.
+//
+// Returns:
+//   - map[string]int64: the post id of every matching slug.
+//   - error: an error if the query fails.
+func (s *Store) QuerySlugs(ctx context.Context, base string) (map[string]int64, error) {
+	// Slugs only hold letters, digits and hyphens, no LIKE wildcard.
+	data := struct {
+		Slug    string `db:"slug"`
+		Pattern string `db:"pattern"`
+	}{
+		Slug:    base,
+		Pattern: base + "-%",
+	}
+
+	const q = `SELECT slug, post_id FROM post_slugs WHERE slug = :slug OR slug LIKE :pattern;`
+
+	var dbSlugs []dbSlug
+	if err := mysql.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbSlugs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	owners := make(map[string]int64, len(dbSlugs))
+	for _, row := range dbSlugs {
+		owners[row.Slug] = row.PostId
+	}
+
+	return owners, nil
+}
+
+// QueryPostSlugs returns every slug reserved for a post, its current slug
+// and the ones it had before.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - postId: the id of the post.
+//
+// Returns:
+//   - []string: the slugs of the post.
+//   - error: an error if the query fails.
+func (s *Store) QueryPostSlugs(ctx context.Context, postId int64) ([]string, error) {
+	data := struct {
+		PostId int64 `db:"post_id"`
+	}{
+		PostId: postId,
+	}
+
+	const q = `SELECT slug, post_id FROM post_slugs WHERE post_id = :post_id;`
+
+	var dbSlugs []dbSlug
+	if err := mysql.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbSlugs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	slugs := make([]string, len(dbSlugs))
+	for i, row := range dbSlugs {
+		slugs[i] = row.Slug
+	}
+
+	return slugs, nil
+}
+
+// ReserveSlug records a slug as belonging to a post for good.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - slug: the slug to reserve.
+//   - postId: the id of the post the slug leads to.
+//
+// Returns:
+//   - error: post.ErrSlugTaken if the slug is reserved already (Error 1062).
+func (s *Store) ReserveSlug(ctx context.Context, slug string, postId int64) error {
+	const q = `
+	INSERT INTO post_slugs
+		(slug, post_id, created_at)
+	VALUES
+		(:slug, :post_id, :created_at);`
+
+	data := dbSlug{
+		Slug:      slug,
+		PostId:    postId,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if _, err := mysql.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		if strings.Split(err.Error(), ":")[0] == "Error 1062 (23000)" {
+			return fmt.Errorf("namedexeccontext: %w", post.ErrSlugTaken)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseSlugs removes the slugs reserved for a deleted post, they lead
+// nowhere anymore and can be given to other posts.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - postId: the id of the deleted post.
+//
+// Returns:
+//   - error: an error if the deletion fails.
+func (s *Store) ReleaseSlugs(ctx context.Context, postId int64) error {
+	data := struct {
+		PostId int64 `db:"post_id"`
+	}{
+		PostId: postId,
+	}
+
+	const q = `
+	DELETE FROM
+		post_slugs
+	WHERE
+		post_id = :post_id`
+
+	if _, err := mysql.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByIds returns the posts among a set of ids, in no particular order.
+// Ids without a post are left out.
+func (s *Store) QueryByIds(ctx context.Context, ids []int64) ([]post.Post, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	data := map[string]interface{}{
+		"ids": ids,
+	}
+
+	const q = `SELECT id, user_id, title, slug, description, front_image, content_id, word_count, reading_time, image_count, language, status, publish_at, created_at, updated_at FROM posts WHERE id IN (:ids);`
+
+	var dbPosts []dbPost
+	if err := mysql.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbPosts); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCorePostSlice(dbPosts), nil
+}
+
 func (s *Store) Query(ctx context.Context, filter post.QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]post.Post, error) {
 	data := map[string]interface{}{
 		"offset":        (pageNumber - 1) * rowsPerPage,
@@ -134,11 +384,13 @@ func (s *Store) Query(ctx context.Context, filter post.QueryFilter, orderBy orde
 	buf := bytes.NewBufferString(q)
 
 	s.applyFilter(filter, data, buf)
-	s.orderByClause(orderBy, buf)
+	if err := s.orderByClause(orderBy, buf); err != nil {
+		return nil, err
+	}
 	buf.WriteString(" LIMIT :rows_per_page OFFSET :offset;")
 
 	var dbPosts []dbPost
-	if err := mysql.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbPosts); err != nil {
+	if err := mysql.NamedQuerySliceUsingIn(ctx, s.log, s.db, buf.String(), data, &dbPosts); err != nil {
 		return nil, fmt.Errorf("namedqueryslice: %w", err)
 	}
 
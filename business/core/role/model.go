@@ -0,0 +1,32 @@
+package role
+
+import (
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/user"
+)
+
+// Role struct contains the definition of a role and the permissions it grants.
+// Meant to be used at the service/core layer
+type Role struct {
+	Name        user.Role
+	Description string
+	Permissions []Permission
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewRole contains information required to define a new role.
+// Meant to be used at the service/core layer
+type NewRole struct {
+	Name        user.Role
+	Description string
+	Permissions []Permission
+}
+
+// UpdateRole contains information required to update a role.
+// Meant to be used at the service/core layer
+type UpdateRole struct {
+	Description *string
+	Permissions []Permission
+}
@@ -0,0 +1,98 @@
+package role
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Permission represents an action a role allows its holders to perform. The
+// names follow the resource:action[:scope] convention.
+type Permission struct {
+	name string
+}
+
+// Set of permissions known by the system.
+var (
+	PermPostCreate       = Permission{"post:create"}
+	PermPostEditAny      = Permission{"post:edit:any"}
+	PermPostDeleteAny    = Permission{"post:delete:any"}
+	PermCommentCreate    = Permission{"comment:create"}
+	PermCommentDeleteAny = Permission{"comment:delete:any"}
+	PermCommentModerate  = Permission{"comment:moderate"}
+	PermLikeCreate       = Permission{"like:create"}
+	PermUserRead         = Permission{"user:read:any"}
+	PermUserManage       = Permission{"user:manage"}
+	PermRoleManage       = Permission{"role:manage"}
+)
+
+// Set of known permissions.
+var permissions = map[string]Permission{
+	PermPostCreate.name:       PermPostCreate,
+	PermPostEditAny.name:      PermPostEditAny,
+	PermPostDeleteAny.name:    PermPostDeleteAny,
+	PermCommentCreate.name:    PermCommentCreate,
+	PermCommentDeleteAny.name: PermCommentDeleteAny,
+	PermCommentModerate.name:  PermCommentModerate,
+	PermLikeCreate.name:       PermLikeCreate,
+	PermUserRead.name:         PermUserRead,
+	PermUserManage.name:       PermUserManage,
+	PermRoleManage.name:       PermRoleManage,
+}
+
+// ParsePermission parses the string value and returns a permission if one exists.
+func ParsePermission(value string) (Permission, error) {
+	perm, exists := permissions[value]
+	if !exists {
+		return Permission{}, fmt.Errorf("invalid permission %q", value)
+	}
+
+	return perm, nil
+}
+
+// MustParsePermission parses the string value and returns a permission if one
+// exists. If an error occurs the function panics.
+func MustParsePermission(value string) Permission {
+	perm, err := ParsePermission(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return perm
+}
+
+// Permissions returns the catalogue of permissions known by the system.
+func Permissions() []Permission {
+	perms := make([]Permission, 0, len(permissions))
+	for _, perm := range permissions {
+		perms = append(perms, perm)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i].name < perms[j].name })
+
+	return perms
+}
+
+// Name returns the name of the permission.
+func (p Permission) Name() string {
+	return p.name
+}
+
+// UnmarshalText implement the unmarshal interface for JSON conversions.
+func (p *Permission) UnmarshalText(data []byte) error {
+	perm, err := ParsePermission(string(data))
+	if err != nil {
+		return err
+	}
+
+	p.name = perm.name
+	return nil
+}
+
+// MarshalText implement the marshal interface for JSON conversions.
+func (p Permission) MarshalText() ([]byte, error) {
+	return []byte(p.name), nil
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (p Permission) Equal(p2 Permission) bool {
+	return p.name == p2.name
+}
@@ -0,0 +1,241 @@
+// Package role provides the core business API for managing role definitions
+// and resolving the permissions they grant.
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound          = errors.New("role not found")
+	ErrUniqueName        = errors.New("role name is not unique")
+	ErrBuiltInRole       = errors.New("built-in roles cannot be deleted")
+	ErrBuiltInPermission = errors.New("built-in roles cannot lose the permissions the system depends on")
+	ErrRoleInUse         = errors.New("role is assigned to users")
+)
+
+// builtIn holds the roles the system depends on and which cannot be removed.
+var builtIn = map[user.Role]bool{
+	user.RoleAdmin: true,
+	user.RoleUser:  true,
+}
+
+// builtInPermissions holds the permissions built-in roles must keep. Without
+// them on ADMIN nobody could manage users or roles anymore, including giving
+// the permissions back.
+var builtInPermissions = map[user.Role][]Permission{
+	user.RoleAdmin: {PermUserManage, PermRoleManage},
+}
+
+// Storer defines the methods required for storing and retrieving data from a role specific repository.
+//
+// Implementation is found in business\core\role\stores\rolesqldb\rolesqldb.go
+type Storer interface {
+	Create(ctx context.Context, role Role) error
+	Update(ctx context.Context, role Role) error
+	Delete(ctx context.Context, role Role) error
+	Query(ctx context.Context) ([]Role, error)
+	QueryByName(ctx context.Context, name user.Role) (Role, error)
+	QueryByNames(ctx context.Context, names []user.Role) ([]Role, error)
+	CountAssignments(ctx context.Context, name user.Role) (int, error)
+}
+
+// Core manages the set of APIs for role api access
+type Core struct {
+	storer Storer
+	log    *logger.Logger
+}
+
+// NewCore constructs and returns a new Core instance for role API access.
+//
+// Parameters:
+//   - st: struct that implements the Storer interface for repository operations.
+//   - log: pointer to the logger used for logging within the core.
+func NewCore(st Storer, log *logger.Logger) *Core {
+	return &Core{
+		storer: st,
+		log:    log,
+	}
+}
+
+// Create adds a new role definition in the repository.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - nr: the details of the role to be created.
+func (c *Core) Create(ctx context.Context, nr NewRole) (Role, error) {
+	now := time.Now()
+
+	r := Role{
+		Name:        nr.Name,
+		Description: nr.Description,
+		Permissions: nr.Permissions,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := c.storer.Create(ctx, r); err != nil {
+		return Role{}, fmt.Errorf("create: %w", err)
+	}
+
+	return r, nil
+}
+
+// Update modifies the description and/or permissions of a role. Built-in
+// roles cannot lose the permissions listed in builtInPermissions.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - r: the role to be updated.
+//   - ur: the changes to be applied to the role.
+func (c *Core) Update(ctx context.Context, r Role, ur UpdateRole) (Role, error) {
+	if ur.Description != nil {
+		r.Description = *ur.Description
+	}
+
+	if ur.Permissions != nil {
+		for _, perm := range builtInPermissions[r.Name] {
+			if !hasPermission(ur.Permissions, perm) {
+				return Role{}, ErrBuiltInPermission
+			}
+		}
+		r.Permissions = ur.Permissions
+	}
+
+	r.UpdatedAt = time.Now()
+
+	if err := c.storer.Update(ctx, r); err != nil {
+		return Role{}, fmt.Errorf("update: %w", err)
+	}
+
+	return r, nil
+}
+
+// Delete removes a role definition. Built-in roles and roles that are still
+// assigned to users cannot be removed.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - r: the role to be deleted.
+func (c *Core) Delete(ctx context.Context, r Role) error {
+	if builtIn[r.Name] {
+		return ErrBuiltInRole
+	}
+
+	n, err := c.storer.CountAssignments(ctx, r.Name)
+	if err != nil {
+		return fmt.Errorf("countassignments: %w", err)
+	}
+	if n > 0 {
+		return fmt.Errorf("role[%s] users[%d]: %w", r.Name.Name(), n, ErrRoleInUse)
+	}
+
+	if err := c.storer.Delete(ctx, r); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves all role definitions.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+func (c *Core) Query(ctx context.Context) ([]Role, error) {
+	roles, err := c.storer.Query(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return roles, nil
+}
+
+// QueryByName retrieves a single role definition.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - name: the name of the role.
+func (c *Core) QueryByName(ctx context.Context, name user.Role) (Role, error) {
+	r, err := c.storer.QueryByName(ctx, name)
+	if err != nil {
+		return Role{}, fmt.Errorf("query: name[%s]: %w", name.Name(), err)
+	}
+
+	return r, nil
+}
+
+// ValidateRoles checks that every provided role is defined in the repository.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - names: the roles to be checked.
+func (c *Core) ValidateRoles(ctx context.Context, names []user.Role) error {
+	roles, err := c.storer.QueryByNames(ctx, names)
+	if err != nil {
+		return fmt.Errorf("querybynames: %w", err)
+	}
+
+	defined := make(map[user.Role]bool, len(roles))
+	for _, r := range roles {
+		defined[r.Name] = true
+	}
+
+	for _, name := range names {
+		if !defined[name] {
+			return fmt.Errorf("role[%s]: %w", name.Name(), ErrNotFound)
+		}
+	}
+
+	return nil
+}
+
+// Permissions resolves the union of the permissions granted by the provided
+// roles. Roles that are no longer defined grant nothing.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - names: the roles held by a user.
+func (c *Core) Permissions(ctx context.Context, names []user.Role) ([]Permission, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	roles, err := c.storer.QueryByNames(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("querybynames: %w", err)
+	}
+
+	set := make(map[Permission]struct{})
+	for _, r := range roles {
+		for _, perm := range r.Permissions {
+			set[perm] = struct{}{}
+		}
+	}
+
+	perms := make([]Permission, 0, len(set))
+	for perm := range set {
+		perms = append(perms, perm)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i].name < perms[j].name })
+
+	return perms, nil
+}
+
+// hasPermission reports whether perm is one of perms.
+func hasPermission(perms []Permission, perm Permission) bool {
+	for _, p := range perms {
+		if p == perm {
+			return true
+		}
+	}
+
+	return false
+}
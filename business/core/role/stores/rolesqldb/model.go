@@ -0,0 +1,87 @@
+package rolesqldb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/data/dbsql/mysql/dbjson"
+)
+
+// dbRole represents the structure used to transfer role data
+// between the application and the database.
+type dbRole struct {
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Permissions dbjson.Strings `db:"permissions"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+// toDBRole converts a role.Role instance (found in the service layer) to a dbRole struct suited for database operations.
+//
+// Parameters:
+//   - r: the role instance to be converted.
+func toDBRole(r role.Role) dbRole {
+	perms := make([]string, len(r.Permissions))
+	for i, perm := range r.Permissions {
+		perms[i] = perm.Name()
+	}
+
+	return dbRole{
+		Name:        r.Name.Name(),
+		Description: r.Description,
+		Permissions: perms,
+		CreatedAt:   r.CreatedAt.UTC(),
+		UpdatedAt:   r.UpdatedAt.UTC(),
+	}
+}
+
+// toCoreRole converts a dbRole instance (found in the repository layer) to a role.Role struct.
+// Permissions that are no longer part of the catalogue are dropped so a stale
+// definition cannot break authorization.
+//
+// Parameters:
+//   - dbR: the dbRole instance to be converted.
+func toCoreRole(dbR dbRole) (role.Role, error) {
+	name, err := user.ParseRole(dbR.Name)
+	if err != nil {
+		return role.Role{}, fmt.Errorf("parse role: %w", err)
+	}
+
+	perms := make([]role.Permission, 0, len(dbR.Permissions))
+	for _, value := range dbR.Permissions {
+		perm, err := role.ParsePermission(value)
+		if err != nil {
+			continue
+		}
+		perms = append(perms, perm)
+	}
+
+	r := role.Role{
+		Name:        name,
+		Description: dbR.Description,
+		Permissions: perms,
+		CreatedAt:   dbR.CreatedAt.In(time.Local),
+		UpdatedAt:   dbR.UpdatedAt.In(time.Local),
+	}
+
+	return r, nil
+}
+
+// toCoreRoleSlice converts a slice of dbRole instances to a slice of role.Role.
+//
+// Parameters:
+//   - dbRoles: the dbRole instances to be converted.
+func toCoreRoleSlice(dbRoles []dbRole) ([]role.Role, error) {
+	roles := make([]role.Role, len(dbRoles))
+	for i, dbR := range dbRoles {
+		var err error
+		roles[i], err = toCoreRole(dbR)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return roles, nil
+}
@@ -0,0 +1,249 @@
+package rolesqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	db "github.com/hpetrov29/resttemplate/business/data/dbsql/mysql"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for role database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the api for interacting with a relational database.
+//
+// Parameters:
+//   - log: pointer to the logger used for logging within the store.
+//   - db: pointer to the database connection used by the store.
+//
+// Returns:
+//   - *Store: a pointer to the newly created Store instance.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new role record into the database.
+//
+// Parameters:
+//   - ctx: the context for managing timeouts and cancellations.
+//   - r: the role data to be stored in the database.
+//
+// Returns:
+//   - error: an error if the insertion fails, including a specific error if the name is not unique (Error 1062).
+func (s *Store) Create(ctx context.Context, r role.Role) error {
+	const q = `
+	INSERT INTO roles
+		(name, description, permissions, created_at, updated_at)
+	VALUES
+		(:name, :description, :permissions, :created_at, :updated_at);`
+
+	if _, err := db.NamedExecContext(ctx, s.log, s.db, q, toDBRole(r)); err != nil {
+		if strings.Split(err.Error(), ":")[0] == "Error 1062 (23000)" {
+			return fmt.Errorf("namedexeccontext: %w", role.ErrUniqueName)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the description and permissions of a role record.
+//
+// Parameters:
+//   - ctx: the context for managing timeouts and cancellations.
+//   - r: the role data to be stored in the database.
+//
+// Returns:
+//   - error: an error if the update fails.
+func (s *Store) Update(ctx context.Context, r role.Role) error {
+	const q = `
+	UPDATE
+		roles
+	SET
+		description = :description,
+		permissions = :permissions,
+		updated_at = :updated_at
+	WHERE
+		name = :name`
+
+	if _, err := db.NamedExecContext(ctx, s.log, s.db, q, toDBRole(r)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a role record from the database.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - r: role data containing the name of the role to be deleted.
+//
+// Returns:
+//   - error: an error if the deletion fails. If successful, returns nil.
+func (s *Store) Delete(ctx context.Context, r role.Role) error {
+	data := struct {
+		Name string `db:"name"`
+	}{
+		Name: r.Name.Name(),
+	}
+
+	const q = `
+	DELETE FROM
+		roles
+	WHERE
+		name = :name`
+
+	if _, err := db.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves all the role records ordered by name.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//
+// Returns:
+//   - []role.Role: the defined roles.
+//   - error: an error if the query fails.
+func (s *Store) Query(ctx context.Context) ([]role.Role, error) {
+	const q = `
+	SELECT
+		name, description, permissions, created_at, updated_at
+	FROM
+		roles
+	ORDER BY
+		name`
+
+	var dbRoles []dbRole
+	if err := db.QuerySlice(ctx, s.log, s.db, q, &dbRoles); err != nil {
+		return nil, fmt.Errorf("queryslice: %w", err)
+	}
+
+	return toCoreRoleSlice(dbRoles)
+}
+
+// QueryByName retrieves a role record using its name.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - name: name of the role to query.
+//
+// Returns:
+//   - role.Role: the role associated with the given name.
+//   - error: an error if the query fails or the role is not found. Returns role.ErrNotFound if the name does not exist.
+func (s *Store) QueryByName(ctx context.Context, name user.Role) (role.Role, error) {
+	data := struct {
+		Name string `db:"name"`
+	}{
+		Name: name.Name(),
+	}
+
+	const q = `
+	SELECT
+		name, description, permissions, created_at, updated_at
+	FROM
+		roles
+	WHERE
+		name = :name`
+
+	var dbR dbRole
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbR); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return role.Role{}, fmt.Errorf("namedquerystruct: %w", role.ErrNotFound)
+		}
+		return role.Role{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreRole(dbR)
+}
+
+// QueryByNames retrieves the role records matching the provided names.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - names: names of the roles to query.
+//
+// Returns:
+//   - []role.Role: the roles found, unknown names are ignored.
+//   - error: an error if the query fails.
+func (s *Store) QueryByNames(ctx context.Context, names []user.Role) ([]role.Role, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = name.Name()
+	}
+
+	data := struct {
+		Names []string `db:"names"`
+	}{
+		Names: values,
+	}
+
+	const q = `
+	SELECT
+		name, description, permissions, created_at, updated_at
+	FROM
+		roles
+	WHERE
+		name IN (:names)`
+
+	var dbRoles []dbRole
+	if err := db.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbRoles); err != nil {
+		return nil, fmt.Errorf("namedquerysliceusingin: %w", err)
+	}
+
+	return toCoreRoleSlice(dbRoles)
+}
+
+// CountAssignments returns the number of users holding the role.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - name: name of the role.
+//
+// Returns:
+//   - int: the number of users the role is assigned to.
+//   - error: an error if the query fails.
+func (s *Store) CountAssignments(ctx context.Context, name user.Role) (int, error) {
+	data := struct {
+		Name string `db:"name"`
+	}{
+		Name: name.Name(),
+	}
+
+	const q = `
+	SELECT
+		count(1) AS count
+	FROM
+		users
+	WHERE
+		JSON_CONTAINS(roles, JSON_QUOTE(:name))`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -5,15 +5,15 @@ import (
 	"net/mail"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/hpetrov29/resttemplate/internal/validate"
 )
 
 // QueryFilter holds the available fields a query can be filtered on.
 type QueryFilter struct {
-	ID               *uuid.UUID    `validate:"omitempty"`
+	ID               *int64        `validate:"omitempty"`
 	Name             *string       `validate:"omitempty,min=3"`
 	Email            *mail.Address `validate:"omitempty"`
+	Enabled          *bool         `validate:"omitempty"`
 	StartCreatedDate *time.Time    `validate:"omitempty"`
 	EndCreatedDate   *time.Time    `validate:"omitempty"`
 }
@@ -27,7 +27,7 @@ func (qf *QueryFilter) Validate() error {
 }
 
 // WithUserID sets the ID field of the QueryFilter value.
-func (qf *QueryFilter) WithUserID(userID uuid.UUID) {
+func (qf *QueryFilter) WithUserID(userID int64) {
 	qf.ID = &userID
 }
 
@@ -41,6 +41,11 @@ func (qf *QueryFilter) WithEmail(email mail.Address) {
 	qf.Email = &email
 }
 
+// WithEnabled sets the Enabled field of the QueryFilter value.
+func (qf *QueryFilter) WithEnabled(enabled bool) {
+	qf.Enabled = &enabled
+}
+
 // WithStartDateCreated sets the DateCreated field of the QueryFilter value.
 func (qf *QueryFilter) WithStartDateCreated(startDate time.Time) {
 	d := startDate.UTC()
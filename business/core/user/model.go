@@ -13,6 +13,7 @@ type User struct {
 	Email mail.Address
 	Roles []Role
 	PasswordHash []byte
+	Enabled bool
 	CreatedAt time.Time
 }
 
@@ -36,4 +37,25 @@ type UpdateUser struct {
 	Password *string
 	PasswordConfirm *string
 	Enabled *bool
-}
\ No newline at end of file
+}
+
+// Identity links an account at an external identity provider to a user.
+// Meant to be used at the service/core layer
+type Identity struct {
+	Provider string
+	Subject string
+	UserId int64
+	Email string
+	CreatedAt time.Time
+}
+
+// ExternalIdentity contains the information an external identity provider
+// returned about the account that logged in.
+// Meant to be used at the service/core layer
+type ExternalIdentity struct {
+	Provider string
+	Subject string
+	Email mail.Address
+	EmailVerified bool
+	Name string
+}
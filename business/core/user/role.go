@@ -1,32 +1,35 @@
 package user
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
-// Role represents a role in the system.
+// Role represents a role in the system. The set of roles and the permissions
+// they grant are stored in the database and managed by the role package.
 type Role struct {
 	name string
 }
 
-// Set of possible roles for a user.
+// Set of built-in roles that always exist.
 var (
 	RoleAdmin = Role{"ADMIN"}
 	RoleUser  = Role{"USER"}
 )
 
-// Set of known roles.
-var roles = map[string]Role{
-	RoleAdmin.name: RoleAdmin,
-	RoleUser.name:  RoleUser,
-}
+// roleName describes the accepted format of a role name.
+var roleName = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,49}$`)
 
-// ParseRole parses the string value and returns a role if one exists.
+// ParseRole parses the string value and returns a role if it is well formed.
+// Names are case insensitive and stored in upper case.
 func ParseRole(value string) (Role, error) {
-	role, exists := roles[value]
-	if !exists {
+	name := strings.ToUpper(strings.TrimSpace(value))
+	if !roleName.MatchString(name) {
 		return Role{}, fmt.Errorf("invalid role %q", value)
 	}
 
-	return role, nil
+	return Role{name}, nil
 }
 
 // MustParseRole parses the string value and returns a role if one exists. If
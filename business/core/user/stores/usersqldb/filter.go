@@ -0,0 +1,47 @@
+package usersqldb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/hpetrov29/resttemplate/business/core/user"
+)
+
+func (s *Store) applyFilter(filter user.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["id"] = *filter.ID
+		wc = append(wc, "id = :id")
+	}
+
+	if filter.Name != nil {
+		data["username"] = "%" + *filter.Name + "%"
+		wc = append(wc, "username LIKE :username")
+	}
+
+	if filter.Email != nil {
+		data["email"] = filter.Email.Address
+		wc = append(wc, "email = :email")
+	}
+
+	if filter.Enabled != nil {
+		data["enabled"] = *filter.Enabled
+		wc = append(wc, "enabled = :enabled")
+	}
+
+	if filter.StartCreatedDate != nil {
+		data["start_created_at"] = *filter.StartCreatedDate
+		wc = append(wc, "created_at >= :start_created_at")
+	}
+
+	if filter.EndCreatedDate != nil {
+		data["end_created_at"] = *filter.EndCreatedDate
+		wc = append(wc, "created_at <= :end_created_at")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/hpetrov29/resttemplate/business/core/user"
-	"github.com/hpetrov29/resttemplate/business/data/dbsql/mysql/dbarray"
+	"github.com/hpetrov29/resttemplate/business/data/dbsql/mysql/dbjson"
 )
 
 // dbUser represents the structure used to transfer user data
@@ -15,8 +15,9 @@ type dbUser struct {
 	Id           	int64      		`db:"id"`
 	Username     	string      		`db:"username"`
 	Email        	string         		`db:"email"`
-	Roles        	dbarray.String 		`db:"roles"`
+	Roles        	dbjson.Strings 		`db:"roles"`
 	PasswordHash 	[]byte         		`db:"password_hash"`
+	Enabled      	bool           		`db:"enabled"`
 	CreatedAt  		time.Time      		`db:"created_at"`
 }
 
@@ -36,6 +37,7 @@ func toDBUser(usr user.User) dbUser {
 		Email:        usr.Email.Address,
 		Roles:        roles,
 		PasswordHash: usr.PasswordHash,
+		Enabled:      usr.Enabled,
 		CreatedAt: 	  usr.CreatedAt.UTC(),
 	}
 }
@@ -64,8 +66,55 @@ func toCoreUser(dbUsr dbUser) (user.User, error) {
 		Email:        addr,
 		Roles:        roles,
 		PasswordHash: dbUsr.PasswordHash,
+		Enabled:      dbUsr.Enabled,
 		CreatedAt:    dbUsr.CreatedAt.In(time.Local),
 	}
 
 	return usr, nil
-}
\ No newline at end of file
+}
+
+// toCoreUserSlice converts a slice of dbUser instances (found in the repository layer) to a slice of user.User.
+//
+// Parameters:
+//   - dbUsers: the dbUser instances to be converted.
+func toCoreUserSlice(dbUsers []dbUser) ([]user.User, error) {
+	usrs := make([]user.User, len(dbUsers))
+	for i, dbUsr := range dbUsers {
+		var err error
+		usrs[i], err = toCoreUser(dbUsr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return usrs, nil
+}
+
+// dbIdentity represents the structure used to transfer the link between a
+// user and an external account between the application and the database.
+type dbIdentity struct {
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	UserId    int64     `db:"user_id"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func toDBIdentity(i user.Identity) dbIdentity {
+	return dbIdentity{
+		Provider:  i.Provider,
+		Subject:   i.Subject,
+		UserId:    i.UserId,
+		Email:     i.Email,
+		CreatedAt: i.CreatedAt.UTC(),
+	}
+}
+
+func toCoreIdentity(dbI dbIdentity) user.Identity {
+	return user.Identity{
+		Provider:  dbI.Provider,
+		Subject:   dbI.Subject,
+		UserId:    dbI.UserId,
+		Email:     dbI.Email,
+		CreatedAt: dbI.CreatedAt.In(time.Local),
+	}
+}
@@ -0,0 +1,28 @@
+package usersqldb
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/data/order"
+)
+
+var orderByFields = map[string]string{
+	user.OrderByID:      "id",
+	user.OrderByName:    "username",
+	user.OrderByEmail:   "email",
+	user.OrderByRoles:   "roles",
+	user.OrderByEnabled: "enabled",
+}
+
+func (s *Store) orderByClause(orderBy order.OrderBy, buf *bytes.Buffer) error {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	buf.WriteString(" ORDER BY " + by + " " + orderBy.Direction)
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package usersqldb
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/hpetrov29/resttemplate/business/core/user"
 	db "github.com/hpetrov29/resttemplate/business/data/dbsql/mysql"
+	"github.com/hpetrov29/resttemplate/business/data/order"
 	"github.com/hpetrov29/resttemplate/internal/logger"
 	"github.com/jmoiron/sqlx"
 )
@@ -47,9 +49,9 @@ func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
 func (s *Store) Create(ctx context.Context, usr user.User) (sql.Result, error) {
 	const q = `
 	INSERT INTO users
-		(id, username, email, password_hash, roles, created_at)
+		(id, username, email, password_hash, roles, enabled, created_at)
 	VALUES
-		(:id, :username, :email, :password_hash, :roles, :created_at);`
+		(:id, :username, :email, :password_hash, :roles, :enabled, :created_at);`
 	
 	res, err := db.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); 
 	
@@ -63,6 +65,37 @@ func (s *Store) Create(ctx context.Context, usr user.User) (sql.Result, error) {
 	return res, nil
 }
 
+// Update replaces a user record in the database.
+//
+// Parameters:
+//   - ctx: the context for managing timeouts and cancellations.
+//   - usr: the user data to be stored in the database.
+//
+// Returns:
+//   - error: an error if the update fails, including a specific error if the email is not unique (Error 1062).
+func (s *Store) Update(ctx context.Context, usr user.User) error {
+	const q = `
+	UPDATE
+		users
+	SET
+		username = :username,
+		email = :email,
+		roles = :roles,
+		password_hash = :password_hash,
+		enabled = :enabled
+	WHERE
+		id = :id`
+
+	if _, err := db.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+		if strings.Split(err.Error(), ":")[0] == "Error 1062 (23000)" {
+			return fmt.Errorf("namedexeccontext: %w", user.ErrUniqueEmail)
+		}
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
 // Delete removes a user record from the database based on the user's ID.
 //
 // Parameters:
@@ -91,6 +124,113 @@ func (s *Store) Delete(ctx context.Context, usr user.User) error {
 	return nil
 }
 
+// Query retrieves a list of existing users from the database.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - filter: the fields the users are filtered on.
+//   - orderBy: the field and direction the users are sorted by.
+//   - pageNumber: the page to be returned.
+//   - rowsPerPage: the number of users per page.
+//
+// Returns:
+//   - []user.User: the users matching the filter.
+//   - error: an error if the query fails.
+func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]user.User, error) {
+	data := map[string]interface{}{
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		id, username, email, password_hash, roles, enabled, created_at
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	if err := s.orderByClause(orderBy, buf); err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(" LIMIT :rows_per_page OFFSET :offset;")
+
+	var dbUsrs []dbUser
+	if err := db.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreUserSlice(dbUsrs)
+}
+
+// Count returns the total number of users in the database matching the filter.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - filter: the fields the users are filtered on.
+//
+// Returns:
+//   - int: the number of matching users.
+//   - error: an error if the query fails.
+func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		count(1) AS count
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	s.applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryById retrieves a user from the database using their id.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - id: id of the user to query.
+//
+// Returns:
+//   - user.User: user associated with the given id.
+//   - error: an error if the query fails or the user is not found. Returns user.ErrNotFound if the id does not exist.
+func (s *Store) QueryById(ctx context.Context, id int64) (user.User, error) {
+	data := struct {
+		Id int64 `db:"id"`
+	}{
+		Id: id,
+	}
+
+	const q = `
+	SELECT
+		id, username, email, password_hash, roles, enabled, created_at
+	FROM
+		users
+	WHERE
+		id = :id;`
+
+	var dbUsr dbUser
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return user.User{}, fmt.Errorf("namedquerystruct: %w", user.ErrNotFound)
+		}
+		return user.User{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreUser(dbUsr)
+}
+
 // QueryByEmail retrieves a user from the database using their email address.
 //
 // Parameters:
@@ -109,7 +249,7 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User
 
 	const q = `
 	SELECT
-        id, username, email, password_hash, roles, created_at
+        id, username, email, password_hash, roles, enabled, created_at
 	FROM
 		users
 	WHERE
@@ -129,4 +269,64 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User
 	}
 
 	return usr, nil
-}
\ No newline at end of file
+}
+
+// CreateIdentity links an external account to a user.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - identity: the link to be stored in the database.
+//
+// Returns:
+//   - error: an error if the insertion fails.
+func (s *Store) CreateIdentity(ctx context.Context, identity user.Identity) error {
+	const q = `
+	INSERT INTO user_identities
+		(provider, subject, user_id, email, created_at)
+	VALUES
+		(:provider, :subject, :user_id, :email, :created_at);`
+
+	if _, err := db.NamedExecContext(ctx, s.log, s.db, q, toDBIdentity(identity)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryIdentity retrieves the link of an external account.
+//
+// Parameters:
+//   - ctx: context for managing timeouts and cancellations.
+//   - provider: the name of the identity provider.
+//   - subject: the id of the account at the identity provider.
+//
+// Returns:
+//   - user.Identity: the link of the external account.
+//   - error: an error if the query fails. Returns user.ErrNotFound if the account is not linked.
+func (s *Store) QueryIdentity(ctx context.Context, provider string, subject string) (user.Identity, error) {
+	data := struct {
+		Provider string `db:"provider"`
+		Subject  string `db:"subject"`
+	}{
+		Provider: provider,
+		Subject:  subject,
+	}
+
+	const q = `
+	SELECT
+		provider, subject, user_id, email, created_at
+	FROM
+		user_identities
+	WHERE
+		provider = :provider AND subject = :subject;`
+
+	var dbI dbIdentity
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbI); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return user.Identity{}, fmt.Errorf("namedquerystruct: %w", user.ErrNotFound)
+		}
+		return user.Identity{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toCoreIdentity(dbI), nil
+}
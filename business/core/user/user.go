@@ -4,12 +4,18 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/hpetrov29/resttemplate/business/data/order"
 	"github.com/hpetrov29/resttemplate/internal/logger"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -19,6 +25,8 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrUserDisabled          = errors.New("user is disabled")
+	ErrUnverifiedEmail       = errors.New("email address is not verified by the identity provider")
 )
 
 // =============================================================================
@@ -29,8 +37,14 @@ var (
 // Implementation is found in business\core\user\stores\usersqldb\usersqldb.go
 type Storer interface {
 	Create(ctx context.Context, user User) (sql.Result, error)
+	Update(ctx context.Context, user User) error
 	Delete(ctx context.Context, user User) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]User, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryById(ctx context.Context, id int64) (User, error)
 	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
+	CreateIdentity(ctx context.Context, identity Identity) error
+	QueryIdentity(ctx context.Context, provider string, subject string) (Identity, error)
 }
 
 type IdGenerator interface {
@@ -80,6 +94,7 @@ func (c *Core) Create(ctx context.Context, newUser NewUser) (User, error) {
 		Email:        	newUser.Email,
 		PasswordHash: 	hash,
 		Roles:        	newUser.Roles,
+		Enabled:      	true,
 		CreatedAt:  	now,
 	}
 
@@ -90,6 +105,45 @@ func (c *Core) Create(ctx context.Context, newUser NewUser) (User, error) {
 	return usr, nil
 }
 
+// Update modifies the information of a user in the repository. Only the
+// fields set in the UpdateUser value are changed.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - usr: the user to be updated.
+//   - uu: the changes to be applied to the user.
+func (c *Core) Update(ctx context.Context, usr User, uu UpdateUser) (User, error) {
+	if uu.Name != nil {
+		usr.Username = *uu.Name
+	}
+
+	if uu.Email != nil {
+		usr.Email = *uu.Email
+	}
+
+	if uu.Roles != nil {
+		usr.Roles = uu.Roles
+	}
+
+	if uu.Password != nil {
+		pw, err := bcrypt.GenerateFromPassword([]byte(*uu.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return User{}, fmt.Errorf("generatefrompassword: %w", err)
+		}
+		usr.PasswordHash = pw
+	}
+
+	if uu.Enabled != nil {
+		usr.Enabled = *uu.Enabled
+	}
+
+	if err := c.storer.Update(ctx, usr); err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	return usr, nil
+}
+
 // Delete removes a specified user from the repository.
 //
 // Parameters:
@@ -103,6 +157,46 @@ func (c *Core) Delete(ctx context.Context, usr User) error {
 	return nil
 }
 
+// Query retrieves a list of existing users from the repository.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - filter: the fields the users are filtered on.
+//   - orderBy: the field and direction the users are sorted by.
+//   - pageNumber: the page to be returned.
+//   - rowsPerPage: the number of users per page.
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.OrderBy, pageNumber int, rowsPerPage int) ([]User, error) {
+	users, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return users, nil
+}
+
+// Count returns the total number of users matching the filter.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - filter: the fields the users are filtered on.
+func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.Count(ctx, filter)
+}
+
+// QueryById retrieves a user from the repository based on their id.
+//
+// Parameters:
+//   - ctx: the context for the request, used for managing timeouts and cancellations.
+//   - id: the id of the user to be retrieved.
+func (c *Core) QueryById(ctx context.Context, id int64) (User, error) {
+	user, err := c.storer.QueryById(ctx, id)
+	if err != nil {
+		return User{}, fmt.Errorf("query: id[%d]: %w", id, err)
+	}
+
+	return user, nil
+}
+
 // QueryByEmail retrieves a user from the repository based on their email address.
 //
 // Parameters:
@@ -140,5 +234,121 @@ func (c *Core) Authenticate(ctx context.Context, email mail.Address, password st
 		return User{}, fmt.Errorf("comparehashandpassword: %w", ErrAuthenticationFailure)
 	}
 
+	if !usr.Enabled {
+		return User{}, fmt.Errorf("authenticate: email[%s]: %w", email, ErrUserDisabled)
+	}
+
+	return usr, nil
+}
+
+// LoginExternal returns the user linked to an account at an external identity
+// provider. An unlinked account is linked to the user with the same email
+// address, or a new user is created with it. Either way the provider must
+// have verified the address.
+//
+// Parameters:
+//   - ctx: the context for managing timeouts and cancellations.
+//   - ext: the account returned by the identity provider.
+//
+// Returns:
+//   - User - the user linked to the external account.
+//   - error - ErrUnverifiedEmail if the account is not linked yet and the
+//     provider did not verify its email, ErrUserDisabled if the user is
+//     disabled or an error if the lookup fails.
+func (c *Core) LoginExternal(ctx context.Context, ext ExternalIdentity) (User, error) {
+	identity, err := c.storer.QueryIdentity(ctx, ext.Provider, ext.Subject)
+	switch {
+	case err == nil:
+		usr, err := c.QueryById(ctx, identity.UserId)
+		if err != nil {
+			return User{}, err
+		}
+		if !usr.Enabled {
+			return User{}, fmt.Errorf("loginexternal: userID[%d]: %w", usr.Id, ErrUserDisabled)
+		}
+		return usr, nil
+
+	case !errors.Is(err, ErrNotFound):
+		return User{}, fmt.Errorf("query identity: provider[%s]: %w", ext.Provider, err)
+	}
+
+	// Linking on an address the provider did not verify would let anyone take
+	// over an account by registering its email at the provider. Creating a
+	// user with it would let them squat the address of its real owner.
+	if !ext.EmailVerified {
+		return User{}, fmt.Errorf("loginexternal: email[%s]: %w", ext.Email.Address, ErrUnverifiedEmail)
+	}
+
+	usr, err := c.queryByEmail(ctx, ext.Email)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		password, err := randomHex(32)
+		if err != nil {
+			return User{}, err
+		}
+
+		usr, err = c.Create(ctx, NewUser{
+			Username:        externalUsername(ext),
+			Email:           ext.Email,
+			Roles:           []Role{RoleUser},
+			Password:        password,
+			PasswordConfirm: password,
+		})
+		if err != nil {
+			return User{}, err
+		}
+
+	case err != nil:
+		return User{}, err
+	}
+
+	if !usr.Enabled {
+		return User{}, fmt.Errorf("loginexternal: userID[%d]: %w", usr.Id, ErrUserDisabled)
+	}
+
+	identity = Identity{
+		Provider:  ext.Provider,
+		Subject:   ext.Subject,
+		UserId:    usr.Id,
+		Email:     ext.Email.Address,
+		CreatedAt: time.Now(),
+	}
+
+	if err := c.storer.CreateIdentity(ctx, identity); err != nil {
+		return User{}, fmt.Errorf("create identity: %w", err)
+	}
+
 	return usr, nil
-}
\ No newline at end of file
+}
+
+var usernameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// externalUsername derives a unique username for a user created from an
+// external account from their name or the local part of their email.
+func externalUsername(ext ExternalIdentity) string {
+	base := usernameChars.ReplaceAllString(strings.ReplaceAll(ext.Name, " ", "."), "")
+	if base == "" {
+		base = usernameChars.ReplaceAllString(strings.Split(ext.Email.Address, "@")[0], "")
+	}
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	suffix, err := randomHex(3)
+	if err != nil {
+		suffix = strconv.FormatInt(time.Now().UnixNano()%1000000, 10)
+	}
+
+	return base + "-" + suffix
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating random value: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
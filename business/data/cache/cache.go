@@ -17,6 +17,16 @@ type Cache interface {
 	Close() error
 	Set(ctx context.Context, key string, value []byte) error
 	SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
 	GetNonFatal(ctx context.Context, key string) ([]byte, bool, error)
 	GetFatal(ctx context.Context, key string) ([]byte, error)
+
+	// MGet returns the values of keys in their order, nil for the keys that
+	// do not exist.
+	MGet(ctx context.Context, keys []string) ([][]byte, error)
+
+	// MSetWithTTL sets all the pairs of values with the same ttl in a single
+	// round trip. The pairs are not set atomically.
+	MSetWithTTL(ctx context.Context, values map[string][]byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
 }
\ No newline at end of file
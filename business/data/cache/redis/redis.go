@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hpetrov29/resttemplate/business/data/cache"
+	"github.com/hpetrov29/resttemplate/internal/web"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RedisClient struct {
@@ -46,6 +50,9 @@ func (rc *RedisClient) Close() error {
 }
 
 func (rc *RedisClient) Set(ctx context.Context, key string, value []byte) error {
+	ctx, span := addSpan(ctx, "set", key)
+	defer span.End()
+
 	if err := rc.c.Set(ctx, key, value, 0).Err(); err != nil {
 		return fmt.Errorf("error inserting key-value pair with key '%s' into Redis: %w", key, err)
 	}
@@ -53,6 +60,9 @@ func (rc *RedisClient) Set(ctx context.Context, key string, value []byte) error
 }
 
 func (rc *RedisClient) HSetWithTTL(ctx context.Context, key string, pairs map[string]interface{}, ttl time.Duration) error {
+	ctx, span := addSpan(ctx, "hset", key)
+	defer span.End()
+
 	if (ttl <= 0) { 
 		return errors.New("expiry must be a positive value")
 	}
@@ -71,6 +81,9 @@ func (rc *RedisClient) HSetWithTTL(ctx context.Context, key string, pairs map[st
 }
 
 func (rc *RedisClient) HVals(ctx context.Context, key string, field string) ([]string, error) {
+	ctx, span := addSpan(ctx, "hvals", key)
+	defer span.End()
+
 	values, err := rc.c.HVals(ctx, key).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get values from hash for key %s: %w", key, err)
@@ -79,6 +92,9 @@ func (rc *RedisClient) HVals(ctx context.Context, key string, field string) ([]s
 }
 
 func (rc *RedisClient) HSetField(ctx context.Context, key string, field string, value interface{}) error {
+	ctx, span := addSpan(ctx, "hset", key)
+	defer span.End()
+
 	err := rc.c.HSet(ctx, key, field, value).Err()
 	if err != nil {
 		return fmt.Errorf("failed to set field %s in hash for key %s: %w", field, key, err)
@@ -87,13 +103,32 @@ func (rc *RedisClient) HSetField(ctx context.Context, key string, field string,
 }
 
 func (rc *RedisClient) SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ctx, span := addSpan(ctx, "set", key)
+	defer span.End()
+
 	if err := rc.c.Set(ctx, key, value, ttl).Err(); err != nil {
 		return fmt.Errorf("error inserting key-value pair with key '%s' into Redis with TTL '%s': %w", key, ttl, err)
 	}
 	return nil
 }
 
+// SetNX sets the key only if it does not exist yet and reports whether it
+// was set.
+func (rc *RedisClient) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ctx, span := addSpan(ctx, "setnx", key)
+	defer span.End()
+
+	ok, err := rc.c.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("error inserting key '%s' into Redis if absent: %w", key, err)
+	}
+	return ok, nil
+}
+
 func (rc *RedisClient) GetNonFatal(ctx context.Context, key string) ([]byte, bool, error) {
+	ctx, span := addSpan(ctx, "get", key)
+	defer span.End()
+
 	value, err := rc.c.Get(ctx, key).Result()
 	if err == redis.Nil {
 		return nil, false, nil
@@ -105,6 +140,9 @@ func (rc *RedisClient) GetNonFatal(ctx context.Context, key string) ([]byte, boo
 }
 
 func (rc *RedisClient) GetFatal(ctx context.Context, key string) ([]byte, error) {
+	ctx, span := addSpan(ctx, "get", key)
+	defer span.End()
+
 	value, err := rc.c.Get(ctx, key).Result()
 	if err == redis.Nil {
 		return nil, fmt.Errorf("key '%s' not found in Redis: %w", key, err)
@@ -113,4 +151,89 @@ func (rc *RedisClient) GetFatal(ctx context.Context, key string) ([]byte, error)
 	}
 
 	return []byte(value), nil
-}
\ No newline at end of file
+}
+
+// MGet returns the values of keys in their order, nil for the keys that do
+// not exist.
+func (rc *RedisClient) MGet(ctx context.Context, keys []string) ([][]byte, error) {
+	ctx, span := addSpan(ctx, "mget", strings.Join(keys, ","))
+	defer span.End()
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	res, err := rc.c.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving keys '%v' from Redis: %w", keys, err)
+	}
+
+	values := make([][]byte, len(res))
+	for i, v := range res {
+		if s, ok := v.(string); ok {
+			values[i] = []byte(s)
+		}
+	}
+
+	return values, nil
+}
+
+// MSetWithTTL sets the values in a pipeline, MSET cannot give keys a TTL.
+func (rc *RedisClient) MSetWithTTL(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	ctx, span := addSpan(ctx, "set", strings.Join(keys, ","))
+	defer span.End()
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := rc.c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range values {
+			pipe.Set(ctx, key, value, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting keys '%v' into Redis with TTL '%s': %w", keys, ttl, err)
+	}
+
+	return nil
+}
+
+func (rc *RedisClient) Delete(ctx context.Context, key string) error {
+	ctx, span := addSpan(ctx, "del", key)
+	defer span.End()
+
+	if err := rc.c.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("error deleting key '%s' from Redis: %w", key, err)
+	}
+	return nil
+}
+
+// addSpan adds a span for a command sent to redis.
+func addSpan(ctx context.Context, command string, key string) (context.Context, trace.Span) {
+	return web.AddSpan(ctx, "business.data.cache.redis."+command,
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", command),
+		attribute.String("db.redis.key", key),
+	)
+}
+
+// RunScript runs the lua script src on the server. The script is sent by its
+// SHA1 digest and only loaded when the server does not know it yet.
+func (rc *RedisClient) RunScript(ctx context.Context, src string, keys []string, args ...any) (any, error) {
+	ctx, span := addSpan(ctx, "evalsha", strings.Join(keys, ","))
+	defer span.End()
+
+	res, err := redis.NewScript(src).Run(ctx, rc.c, keys, args...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error running script on keys '%v': %w", keys, err)
+	}
+
+	return res, nil
+}
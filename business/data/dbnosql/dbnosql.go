@@ -1,6 +1,15 @@
 package dbnosql
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// Set of errors returned by the repositories.
+var (
+	ErrNotFound     = errors.New("document not found")
+	ErrDuplicateKey = errors.New("duplicate key")
+)
 
 type Config struct {
 	User         string
@@ -10,6 +19,15 @@ type Config struct {
 	MaxOpenConns int
 }
 
+// Filter selects documents by the values of their fields.
+type Filter map[string]any
+
+// Sort orders the documents returned by a query by a field.
+type Sort struct {
+	Field      string
+	Descending bool
+}
+
 type NOSQLDB interface {
 	Open(cfg Config) error
 	StatusCheck(ctx context.Context) error
@@ -18,7 +36,21 @@ type NOSQLDB interface {
 }
 
 type NOSQLDBrepo interface {
+	// Insert returns ErrDuplicateKey when a document with the same id exists.
 	Insert(ctx context.Context, record interface{}) error
 	QueryById(ctx context.Context, id int64, data any) error
+	Replace(ctx context.Context, id int64, record interface{}) error
 	Delete(ctx context.Context, id uint64) error
-}
\ No newline at end of file
+
+	// QueryOne decodes the first document matching filter in the order of
+	// sort into data, a pointer to a struct. It returns ErrNotFound when no
+	// document matches.
+	QueryOne(ctx context.Context, filter Filter, sort Sort, data any) error
+
+	// Query decodes the documents matching filter in the order of sort into
+	// data, a pointer to a slice. A limit of zero returns all of them.
+	Query(ctx context.Context, filter Filter, sort Sort, limit int, data any) error
+
+	// DeleteMany deletes the documents matching filter.
+	DeleteMany(ctx context.Context, filter Filter) error
+}
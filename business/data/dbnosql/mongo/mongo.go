@@ -8,9 +8,13 @@ import (
 	"time"
 
 	"github.com/hpetrov29/resttemplate/business/data/dbnosql"
+	"github.com/hpetrov29/resttemplate/internal/metrics"
+	"github.com/hpetrov29/resttemplate/internal/web"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // -------------------------------------------------------------------------
@@ -41,7 +45,11 @@ func (mc *MongoClient) StatusCheck(ctx context.Context) error {
 	err := mc.c.Client().Ping(ctx, nil)
 	if err != nil {
 		if strings.Contains(err.Error(), "connection refused") {
-			time.Sleep(5 * time.Second)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+			}
 			return mc.StatusCheck(ctx)
 		}
 		return err
@@ -75,8 +83,16 @@ func (mc *MongoClient) GetRepository(collectionName string) dbnosql.NOSQLDBrepo
 
 // Insert inserts a new record into the MongoDB collection.
 func (r *MongoRepository) Insert(ctx context.Context, record interface{}) error {
+	defer metrics.ObserveDBCall("mongo", "insert", time.Now())
+
+	ctx, span := r.addSpan(ctx, "insert")
+	defer span.End()
+
     _, err := r.collection.InsertOne(ctx, record)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to insert record in mongoDB: %w: %w", dbnosql.ErrDuplicateKey, err)
+		}
         return fmt.Errorf("failed to insert record in mongoDB: %w", err)
 	}
     return nil
@@ -84,6 +100,11 @@ func (r *MongoRepository) Insert(ctx context.Context, record interface{}) error
 
 // QueryById retrieves a record with the specified id from the MongoDB collection.
 func (r *MongoRepository) QueryById(ctx context.Context, id int64, data any) error {
+	defer metrics.ObserveDBCall("mongo", "query_by_id", time.Now())
+
+	ctx, span := r.addSpan(ctx, "query_by_id")
+	defer span.End()
+
 	if data == nil {
         return errors.New("(*MongoRepository) QueryById expects data to be a non-nil pointer")
     }
@@ -96,8 +117,32 @@ func (r *MongoRepository) QueryById(ctx context.Context, id int64, data any) err
 	return err
 }
 
+// Replace replaces the record with the specified id in the MongoDB collection.
+func (r *MongoRepository) Replace(ctx context.Context, id int64, record interface{}) error {
+	defer metrics.ObserveDBCall("mongo", "replace", time.Now())
+
+	ctx, span := r.addSpan(ctx, "replace")
+	defer span.End()
+
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": id}, record)
+	if err != nil {
+		return fmt.Errorf("failed to replace record in mongoDB: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("document with id %d not found in mongoDB", id)
+	}
+
+	return nil
+}
+
 // Delete deletes a record from the MongoDB collection.
 func (r *MongoRepository) Delete(ctx context.Context, id uint64) error {
+	defer metrics.ObserveDBCall("mongo", "delete", time.Now())
+
+	ctx, span := r.addSpan(ctx, "delete")
+	defer span.End()
+
     res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	if err != nil {
 		return err 
@@ -108,4 +153,83 @@ func (r *MongoRepository) Delete(ctx context.Context, id uint64) error {
 	}
 
     return nil
-}
\ No newline at end of file
+}
+
+// QueryOne retrieves the first record matching filter from the MongoDB collection.
+func (r *MongoRepository) QueryOne(ctx context.Context, filter dbnosql.Filter, sort dbnosql.Sort, data any) error {
+	defer metrics.ObserveDBCall("mongo", "query_one", time.Now())
+
+	ctx, span := r.addSpan(ctx, "query_one")
+	defer span.End()
+
+	opts := options.FindOne()
+	if sort.Field != "" {
+		opts.SetSort(toBsonSort(sort))
+	}
+
+	err := r.collection.FindOne(ctx, bson.M(filter), opts).Decode(data)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return dbnosql.ErrNotFound
+	}
+
+	return err
+}
+
+// Query retrieves the records matching filter from the MongoDB collection.
+func (r *MongoRepository) Query(ctx context.Context, filter dbnosql.Filter, sort dbnosql.Sort, limit int, data any) error {
+	defer metrics.ObserveDBCall("mongo", "query", time.Now())
+
+	ctx, span := r.addSpan(ctx, "query")
+	defer span.End()
+
+	opts := options.Find()
+	if sort.Field != "" {
+		opts.SetSort(toBsonSort(sort))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M(filter), opts)
+	if err != nil {
+		return fmt.Errorf("failed to query records in mongoDB: %w", err)
+	}
+
+	if err := cursor.All(ctx, data); err != nil {
+		return fmt.Errorf("failed to decode records from mongoDB: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMany deletes the records matching filter from the MongoDB collection.
+func (r *MongoRepository) DeleteMany(ctx context.Context, filter dbnosql.Filter) error {
+	defer metrics.ObserveDBCall("mongo", "delete_many", time.Now())
+
+	ctx, span := r.addSpan(ctx, "delete_many")
+	defer span.End()
+
+	if _, err := r.collection.DeleteMany(ctx, bson.M(filter)); err != nil {
+		return fmt.Errorf("failed to delete records in mongoDB: %w", err)
+	}
+
+	return nil
+}
+
+// toBsonSort converts a sort to the sort document of the driver.
+func toBsonSort(sort dbnosql.Sort) bson.D {
+	direction := 1
+	if sort.Descending {
+		direction = -1
+	}
+	return bson.D{{Key: sort.Field, Value: direction}}
+}
+
+// addSpan adds a span for an operation on the collection of the repository.
+func (r *MongoRepository) addSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return web.AddSpan(ctx, "business.data.dbnosql.mongo."+operation,
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.mongodb.collection", r.collection.Name()),
+		attribute.String("db.operation", operation),
+	)
+}
@@ -0,0 +1,61 @@
+// Package dbjson provides support for storing values inside mysql JSON columns.
+package dbjson
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Strings represents a list of strings stored as a JSON array.
+type Strings []string
+
+// Scan implements the sql.Scanner interface.
+func (s *Strings) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		return s.scanBytes(src)
+	case string:
+		return s.scanBytes([]byte(src))
+	case nil:
+		*s = nil
+		return nil
+	}
+
+	return fmt.Errorf("database: cannot convert %T to Strings", src)
+}
+
+func (s *Strings) scanBytes(src []byte) error {
+	src = bytes.TrimSpace(src)
+
+	// Rows written before the column held proper JSON arrays contain an
+	// empty object, treat them as an empty list.
+	if len(src) == 0 || bytes.Equal(src, []byte("{}")) || bytes.Equal(src, []byte("null")) {
+		*s = Strings{}
+		return nil
+	}
+
+	var v []string
+	if err := json.Unmarshal(src, &v); err != nil {
+		return fmt.Errorf("database: parsing json array: %w", err)
+	}
+	*s = v
+
+	return nil
+}
+
+// Value implements the driver.Valuer interface. A nil list is stored as an
+// empty JSON array so it can be written into NOT NULL columns.
+func (s Strings) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal([]string(s))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
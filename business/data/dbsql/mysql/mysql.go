@@ -11,7 +11,10 @@ import (
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/metrics"
+	"github.com/hpetrov29/resttemplate/internal/web"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Set of error variables for CRUD operations.
@@ -73,7 +76,11 @@ func StatusCheck(ctx context.Context, db *sqlx.DB) error {
 	err:= db.QueryRowContext(ctx, q).Scan(&tmp)
 	if err != nil {
 		if strings.Contains(err.Error(), "connect: connection refused") {
-			time.Sleep(5*time.Second)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(5 * time.Second):
+			}
 			return StatusCheck(ctx, db)
 		}
 		return err
@@ -90,8 +97,13 @@ func ExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, qu
 // NamedExecContext is a helper function to execute a CUD operation with
 // logging and tracing where field replacement is necessary.
 func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (sql.Result, error) {
+	defer metrics.ObserveDBCall("sql", "exec", time.Now())
+
 	q := queryString(query, data)
 
+	ctx, span := web.AddSpan(ctx, "business.data.dbsql.NamedExecContext", attribute.String("db.statement", statement(query)))
+	defer span.End()
+
 	if _, ok := data.(struct{}); ok {
 		log.Infoc(ctx, 5, "database.NamedExecContext", "query", q)
 	} else {
@@ -115,8 +127,13 @@ func NamedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 }
 
 func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest any, withIn bool) error {
+	defer metrics.ObserveDBCall("sql", "query_struct", time.Now())
+
 	q := queryString(query, data)
 
+	ctx, span := web.AddSpan(ctx, "business.data.dbsql.NamedQueryStruct", attribute.String("db.statement", statement(query)))
+	defer span.End()
+
 	log.Infoc(ctx, 5, "database.NamedQueryStruct", "query", q)
 
 	var rows *sqlx.Rows
@@ -179,6 +196,13 @@ func queryString(query string, args any) string {
 		query = strings.Replace(query, "?", value, 1)
 	}
 
+	return statement(query)
+}
+
+// statement returns query on a single line. Spans record the query with its
+// named parameters rather than their values, values can be password hashes
+// or emails and are not sent to the tracing backend.
+func statement(query string) string {
 	query = strings.ReplaceAll(query, "\t", "")
 	query = strings.ReplaceAll(query, "\n", " ")
 
@@ -208,8 +232,13 @@ func NamedQuerySliceUsingIn[T any](ctx context.Context, log *logger.Logger, db s
 }
 
 func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest *[]T, withIn bool) error {
+	defer metrics.ObserveDBCall("sql", "query_slice", time.Now())
+
 	q := queryString(query, data)
 
+	ctx, span := web.AddSpan(ctx, "business.data.dbsql.NamedQuerySlice", attribute.String("db.statement", statement(query)))
+	defer span.End()
+
 	log.Infoc(ctx, 5, "database.NamedQuerySlice", "query", q)
 
 	var rows *sqlx.Rows
@@ -0,0 +1,66 @@
+// Package lock provides locks shared by every instance of the service, for
+// background work that must only run in one place at a time.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Store is the redis client holding the locks.
+type Store interface {
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	RunScript(ctx context.Context, src string, keys []string, args ...any) (any, error)
+}
+
+// release deletes the lock KEYS[1] only if it still holds the token ARGV[1],
+// a holder whose lock expired never releases the lock of the next holder.
+const release = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`
+
+// Redis hands out locks kept in redis. A lock expires after its ttl, work
+// holding it must finish within the ttl or tolerate running twice.
+type Redis struct {
+	store Store
+}
+
+// NewRedis constructs a redis backed locker.
+func NewRedis(store Store) *Redis {
+	return &Redis{
+		store: store,
+	}
+}
+
+// TryLock acquires the lock key without waiting. It reports whether the lock
+// was acquired and returns the function releasing it.
+func (r *Redis) TryLock(ctx context.Context, key string, ttl time.Duration) (func(context.Context) error, bool, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, false, fmt.Errorf("generating lock token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	ok, err := r.store.SetNX(ctx, "lock:"+key, []byte(token), ttl)
+	if err != nil {
+		return nil, false, fmt.Errorf("acquiring lock[%s]: %w", key, err)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	unlock := func(ctx context.Context) error {
+		if _, err := r.store.RunScript(ctx, release, []string{"lock:" + key}, token); err != nil {
+			return fmt.Errorf("releasing lock[%s]: %w", key, err)
+		}
+		return nil
+	}
+
+	return unlock, true, nil
+}
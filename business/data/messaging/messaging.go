@@ -1,5 +1,7 @@
 package messaging
 
+import "context"
+
 type Config struct {
 	User         string
 	Password     string
@@ -7,5 +9,5 @@ type Config struct {
 }
 
 type MessagingQueue interface {
-	Publish(subject string, message []byte) error
+	Publish(ctx context.Context, subject string, message []byte) error
 }
\ No newline at end of file
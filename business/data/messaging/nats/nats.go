@@ -1,12 +1,19 @@
 package nats
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/hpetrov29/resttemplate/business/data/messaging"
+	"github.com/hpetrov29/resttemplate/internal/metrics"
+	"github.com/hpetrov29/resttemplate/internal/web"
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // NATSClient is a concrete implementation of MessageQueue.
@@ -28,9 +35,64 @@ func NewNATSClient(cfg messaging.Config) (*NATSClient, error) {
     return &NATSClient{conn: nc}, nil
 }
 
-// Publish sends a message to a NATS subject.
-func (n *NATSClient) Publish(subject string, message []byte) error {
-    return n.conn.Publish(subject, message)
+// Publish sends a message to a NATS subject. The trace context held by ctx
+// is carried in the headers of the message so consumers continue the trace.
+func (n *NATSClient) Publish(ctx context.Context, subject string, message []byte) error {
+	ctx, span := web.AddSpan(ctx, "business.data.messaging.nats.publish",
+		attribute.String("messaging.system", "nats"),
+		attribute.String("messaging.destination.name", subject),
+	)
+	defer span.End()
+
+	msg := nats.NewMsg(subject)
+	msg.Data = message
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Header))
+
+	if err := n.conn.PublishMsg(msg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		metrics.AddPublishFailure(subject)
+		return err
+	}
+	return nil
+}
+
+// ContextFromMsg returns a copy of ctx holding the trace context carried in
+// the headers of msg. Consumers use it to continue the trace of the publisher.
+func ContextFromMsg(ctx context.Context, msg *nats.Msg) context.Context {
+	if msg.Header == nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier(msg.Header))
+}
+
+// headerCarrier carries the trace context in the headers of a message. Keys
+// are kept as given: the W3C headers are lower case, consumers outside Go
+// look for traceparent, not the canonical Traceparent of HTTP headers.
+type headerCarrier nats.Header
+
+var _ propagation.TextMapCarrier = headerCarrier{}
+
+// Get returns the first value of key.
+func (c headerCarrier) Get(key string) string {
+	if v := c[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Set replaces the values of key with value.
+func (c headerCarrier) Set(key string, value string) {
+	c[key] = []string{value}
+}
+
+// Keys returns the keys of the headers.
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
 }
 
 // HealthCheck verifies if the NATS connection is active.
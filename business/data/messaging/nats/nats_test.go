@@ -0,0 +1,56 @@
+package nats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func useTraceContext(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+}
+
+func TestInjectKeepsLowerCaseKeys(t *testing.T) {
+	useTraceContext(t)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	msg := nats.NewMsg("posts.published")
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Header))
+
+	if got := msg.Header["traceparent"]; len(got) != 1 || got[0] != traceparent {
+		t.Fatalf("traceparent: got %q in %v, want %q", got, msg.Header, traceparent)
+	}
+	if _, exists := msg.Header["Traceparent"]; exists {
+		t.Fatal("Traceparent: the key was canonicalized")
+	}
+}
+
+func TestContextFromMsg(t *testing.T) {
+	useTraceContext(t)
+
+	// Headers as published by a producer outside this service.
+	msg := nats.NewMsg("likes")
+	msg.Header["traceparent"] = []string{traceparent}
+
+	sc := trace.SpanContextFromContext(ContextFromMsg(context.Background(), msg))
+	if got := sc.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace id: got %q, want the one of the message", got)
+	}
+	if !sc.IsRemote() {
+		t.Fatal("span context: expected a remote parent")
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// bucket holds the state of a single token bucket.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// Memory is a Limiter keeping its buckets in the memory of the instance.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastEvict time.Time
+	now       func() time.Time
+}
+
+// NewMemory constructs an in-memory limiter.
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket identified by key.
+func (m *Memory) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	burst := float64(limit.Requests)
+	rate := limit.rate()
+
+	m.evict(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, updated: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	res := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.ResetAfter = seconds((burst - b.tokens) / rate)
+	b.full = now.Add(res.ResetAfter)
+
+	return res, nil
+}
+
+// evict drops the buckets which are full again, a new bucket starts full so
+// forgetting them changes nothing. It runs at most once per minute.
+func (m *Memory) evict(now time.Time) {
+	const interval = time.Minute
+
+	if now.Sub(m.lastEvict) < interval {
+		return
+	}
+	m.lastEvict = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// seconds converts a number of seconds into a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,102 @@
+// Package ratelimit provides token bucket rate limiting shared by every
+// instance of the service through redis, with an in-memory fallback used
+// while redis is unreachable.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/logger"
+)
+
+// Limit represents the number of requests allowed per period. Requests is
+// also the size of the bucket, so a full bucket allows a burst of Requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled reports whether the limit restricts anything.
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// rate returns the number of tokens added to the bucket per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// ParseLimit parses a limit written as "<requests>/<period>", e.g. "30/1m".
+// An empty string or "0" disables limiting.
+func ParseLimit(s string) (Limit, error) {
+	if s == "" || s == "0" {
+		return Limit{}, nil
+	}
+
+	reqs, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q: expected <requests>/<period>", s)
+	}
+
+	n, err := strconv.Atoi(reqs)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("limit %q: invalid number of requests", s)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("limit %q: invalid period", s)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Result represents the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// Limiter takes tokens from the bucket identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// =============================================================================
+
+// Shared is a Limiter keeping its buckets in redis and falling back to
+// in-memory buckets when redis fails. The fallback buckets are local to
+// the instance, so limits are looser while redis is unreachable.
+type Shared struct {
+	log      *logger.Logger
+	primary  Limiter
+	fallback Limiter
+}
+
+// New constructs a Shared limiter on top of the redis client.
+func New(log *logger.Logger, scripter Scripter) *Shared {
+	return &Shared{
+		log:      log,
+		primary:  NewRedis(scripter),
+		fallback: NewMemory(),
+	}
+}
+
+// Allow takes a token from the bucket identified by key.
+func (s *Shared) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := s.primary.Allow(ctx, key, limit)
+	if err == nil {
+		return res, nil
+	}
+
+	s.log.Info(ctx, "ratelimit", "status", "falling back to in-memory limiter", "key", key, "msg", err)
+
+	return s.fallback.Allow(ctx, key, limit)
+}
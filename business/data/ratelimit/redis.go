@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Scripter runs a lua script on the redis server.
+type Scripter interface {
+	RunScript(ctx context.Context, src string, keys []string, args ...any) (any, error)
+}
+
+// tokenBucket takes a token from the bucket stored in the hash KEYS[1]. The
+// bucket is refilled at ARGV[1] tokens per second up to ARGV[2] tokens using
+// the clock of the redis server, so every instance sees the same time. It
+// returns whether the token was taken, the tokens left and the number of
+// milliseconds until a token is available.
+const tokenBucket = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+
+return {allowed, tostring(tokens), retry}
+`
+
+// Redis is a Limiter keeping its buckets in redis.
+type Redis struct {
+	scripter Scripter
+}
+
+// NewRedis constructs a redis backed limiter.
+func NewRedis(scripter Scripter) *Redis {
+	return &Redis{
+		scripter: scripter,
+	}
+}
+
+// Allow takes a token from the bucket identified by key.
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	rate := limit.rate()
+
+	reply, err := r.scripter.RunScript(ctx, tokenBucket, []string{key}, rate, limit.Requests)
+	if err != nil {
+		return Result{}, fmt.Errorf("running token bucket script: %w", err)
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected token bucket reply %v", reply)
+	}
+
+	allowed, _ := values[0].(int64)
+	retry, _ := values[2].(int64)
+
+	str, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing remaining tokens: %w", err)
+	}
+
+	res := Result{
+		Allowed:    allowed == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(tokens),
+		RetryAfter: time.Duration(retry) * time.Millisecond,
+		ResetAfter: seconds((float64(limit.Requests) - tokens) / rate),
+	}
+
+	return res, nil
+}
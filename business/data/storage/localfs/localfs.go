@@ -0,0 +1,146 @@
+// Package localfs implements storage.BlobStore on the local filesystem, for
+// development and single instance deployments.
+package localfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/data/storage"
+	"github.com/hpetrov29/resttemplate/internal/metrics"
+	"github.com/hpetrov29/resttemplate/internal/web"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Store keeps blobs as files under a root directory. The content type of a
+// blob is derived from the extension of its key.
+type Store struct {
+	root string
+}
+
+// New constructs a Store rooted at dir, creating the directory if needed.
+func New(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, errors.New("localfs: directory not set")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("localfs: creating directory: %w", err)
+	}
+
+	return &Store{root: dir}, nil
+}
+
+// Put writes data under key. The data is written to a temporary file first
+// and renamed, readers never see a partially written blob.
+func (s *Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	_, span := addSpan(ctx, "put", key)
+	defer span.End()
+	defer metrics.ObserveDBCall("localfs", "put", time.Now())
+
+	if err := storage.CheckKey(key); err != nil {
+		return err
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("localfs: creating directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("localfs: creating file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("localfs: writing file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("localfs: closing file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("localfs: renaming file: %w", err)
+	}
+
+	return nil
+}
+
+// Get opens the blob stored under key.
+func (s *Store) Get(ctx context.Context, key string) (storage.Object, error) {
+	_, span := addSpan(ctx, "get", key)
+	defer span.End()
+	defer metrics.ObserveDBCall("localfs", "get", time.Now())
+
+	if err := storage.CheckKey(key); err != nil {
+		return storage.Object{}, err
+	}
+
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return storage.Object{}, storage.ErrNotFound
+		}
+		return storage.Object{}, fmt.Errorf("localfs: opening file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return storage.Object{}, fmt.Errorf("localfs: stat: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return storage.Object{ContentType: contentType, Size: info.Size(), Body: f}, nil
+}
+
+// Delete removes the blob stored under key. Missing blobs are not an error.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_, span := addSpan(ctx, "delete", key)
+	defer span.End()
+	defer metrics.ObserveDBCall("localfs", "delete", time.Now())
+
+	if err := storage.CheckKey(key); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("localfs: removing file: %w", err)
+	}
+
+	return nil
+}
+
+// StatusCheck verifies the root directory is still accessible.
+func (s *Store) StatusCheck(ctx context.Context) error {
+	if _, err := os.Stat(s.root); err != nil {
+		return fmt.Errorf("localfs: %w", err)
+	}
+
+	return nil
+}
+
+// path returns the file path of a validated key.
+func (s *Store) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+func addSpan(ctx context.Context, operation string, key string) (context.Context, trace.Span) {
+	return web.AddSpan(ctx, "business.data.storage.localfs."+operation,
+		attribute.String("storage.operation", operation),
+		attribute.String("storage.key", key),
+	)
+}
@@ -0,0 +1,89 @@
+package localfs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hpetrov29/resttemplate/business/data/storage"
+	"github.com/hpetrov29/resttemplate/business/data/storage/localfs"
+)
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s, err := localfs.New(dir)
+	if err != nil {
+		t.Fatalf("new: %s", err)
+	}
+
+	const key = "3f2a/original.png"
+	data := []byte("\x89PNG not really")
+
+	if err := s.Put(ctx, key, data, "image/png"); err != nil {
+		t.Fatalf("put: %s", err)
+	}
+
+	// Only the blob is left behind, the temporary file was renamed.
+	entries, err := os.ReadDir(filepath.Join(dir, "3f2a"))
+	if err != nil {
+		t.Fatalf("read dir: %s", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "original.png" {
+		t.Fatalf("files: got %v, want only original.png", entries)
+	}
+
+	obj, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	got, err := io.ReadAll(obj.Body)
+	obj.Body.Close()
+	if err != nil {
+		t.Fatalf("read: %s", err)
+	}
+
+	if string(got) != string(data) {
+		t.Errorf("data: got %q, want %q", got, data)
+	}
+	if obj.ContentType != "image/png" {
+		t.Errorf("content type: got %q, want %q", obj.ContentType, "image/png")
+	}
+	if obj.Size != int64(len(data)) {
+		t.Errorf("size: got %d, want %d", obj.Size, len(data))
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %s", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("get deleted: got %v, want %v", err, storage.ErrNotFound)
+	}
+
+	// Deleting a missing blob is not an error.
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("delete missing: %s", err)
+	}
+}
+
+func TestInvalidKey(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := localfs.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("new: %s", err)
+	}
+
+	for _, key := range []string{"../escape", "/absolute", "a/../../b", "Upper.png", ""} {
+		if err := s.Put(ctx, key, []byte("x"), "text/plain"); err == nil {
+			t.Errorf("put %q: expected the key to be refused", key)
+		}
+		if _, err := s.Get(ctx, key); err == nil || errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("get %q: got %v, want the key to be refused", key, err)
+		}
+	}
+}
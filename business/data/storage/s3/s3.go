@@ -0,0 +1,298 @@
+// Package s3 implements storage.BlobStore on top of an S3 compatible object
+// storage service. Requests are signed with AWS Signature Version 4, which
+// AWS S3 and the common self hosted stand-ins all accept.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/data/storage"
+	"github.com/hpetrov29/resttemplate/internal/metrics"
+	"github.com/hpetrov29/resttemplate/internal/web"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// emptyHash is the SHA-256 of an empty payload.
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Store keeps blobs as objects of a bucket.
+type Store struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+}
+
+// New constructs a Store for the bucket of cfg. No request is made, use
+// StatusCheck to verify the bucket is reachable.
+func New(cfg storage.Config) (*Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("s3: invalid endpoint %q", cfg.Endpoint)
+	}
+
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3: bucket not set")
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	s := Store{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+	}
+
+	return &s, nil
+}
+
+// Put uploads data as the object key.
+func (s *Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	ctx, span := addSpan(ctx, "put", key)
+	defer span.End()
+	defer metrics.ObserveDBCall("s3", "put", time.Now())
+
+	if err := storage.CheckKey(key); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+
+	resp, err := s.do(ctx, http.MethodPut, key, header, data)
+	if err != nil {
+		return err
+	}
+	defer drain(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError("put", key, resp)
+	}
+
+	return nil
+}
+
+// Get downloads the object key.
+func (s *Store) Get(ctx context.Context, key string) (storage.Object, error) {
+	ctx, span := addSpan(ctx, "get", key)
+	defer span.End()
+	defer metrics.ObserveDBCall("s3", "get", time.Now())
+
+	if err := storage.CheckKey(key); err != nil {
+		return storage.Object{}, err
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return storage.Object{}, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		drain(resp)
+		return storage.Object{}, storage.ErrNotFound
+	default:
+		defer drain(resp)
+		return storage.Object{}, responseError("get", key, resp)
+	}
+
+	obj := storage.Object{
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+		Body:        resp.Body,
+	}
+
+	return obj, nil
+}
+
+// Delete removes the object key. S3 reports success for missing objects too.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	ctx, span := addSpan(ctx, "delete", key)
+	defer span.End()
+	defer metrics.ObserveDBCall("s3", "delete", time.Now())
+
+	if err := storage.CheckKey(key); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer drain(resp)
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return responseError("delete", key, resp)
+	}
+
+	return nil
+}
+
+// StatusCheck verifies the bucket exists and the credentials can access it.
+func (s *Store) StatusCheck(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodHead, "", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer drain(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("s3: bucket %q: status %d", s.bucket, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// do sends a signed request for the object key, or for the bucket itself if
+// key is empty.
+func (s *Store) do(ctx context.Context, method string, key string, header http.Header, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if s.pathStyle {
+		u.Path += "/" + s.bucket
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	if key != "" {
+		u.Path += "/" + key
+	} else if u.Path == "" {
+		u.Path = "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("s3: creating request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		req.Body = http.NoBody
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	s.sign(req, body, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3: %s %s: %w", strings.ToLower(method), key, err)
+	}
+
+	return resp, nil
+}
+
+// sign adds the headers of a Signature Version 4 signed request. All headers
+// set on req are signed, the payload is signed by its hash.
+func (s *Store) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := emptyHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// The canonical headers are the lower cased names, sorted, with their
+	// trimmed values. Host is not part of req.Header and is added here.
+	headers := map[string]string{"host": req.URL.Host}
+	names := []string{"host"}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		headers[name] = strings.TrimSpace(strings.Join(v, ","))
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// responseError returns an error holding the status and the error code of an
+// S3 error response.
+func responseError(operation string, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	code := ""
+	if i := bytes.Index(body, []byte("<Code>")); i >= 0 {
+		rest := body[i+len("<Code>"):]
+		if j := bytes.Index(rest, []byte("</Code>")); j >= 0 {
+			code = ": " + string(rest[:j])
+		}
+	}
+
+	return fmt.Errorf("s3: %s %s: status %s%s", operation, key, strconv.Itoa(resp.StatusCode), code)
+}
+
+// drain reads the rest of the body, so the connection can be reused, and
+// closes it.
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+func addSpan(ctx context.Context, operation string, key string) (context.Context, trace.Span) {
+	return web.AddSpan(ctx, "business.data.storage.s3."+operation,
+		attribute.String("storage.operation", operation),
+		attribute.String("storage.key", key),
+	)
+}
@@ -0,0 +1,70 @@
+// Package storage defines the blob storage used for uploaded files, with a
+// local filesystem and an S3 compatible implementation.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Set of drivers a BlobStore can be backed by.
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ErrNotFound is returned when no blob is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+// Config holds the settings of both drivers, only the ones of Driver are used.
+type Config struct {
+	Driver string
+
+	// Dir is the directory the local driver stores blobs in.
+	Dir string
+
+	// Endpoint is the base URL of the S3 compatible service, e.g.
+	// https://s3.eu-central-1.amazonaws.com or http://localhost:9000 for a
+	// local stand-in. PathStyle addresses the bucket in the path instead
+	// of the host, which most stand-ins require.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+// Object is a stored blob. The caller must close Body.
+type Object struct {
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+}
+
+// BlobStore stores blobs under keys made of path segments, e.g.
+// 3f2a/original.jpg.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+	StatusCheck(ctx context.Context) error
+}
+
+// validKey matches the keys accepted by the stores, path segments of lower
+// case letters, digits, dots, dashes and underscores.
+var validKey = regexp.MustCompile(`^[a-z0-9_-][a-z0-9._-]*(/[a-z0-9_-][a-z0-9._-]*)*$`)
+
+// CheckKey validates a key. Keys never escape the root of a store, the local
+// driver uses them as file paths.
+func CheckKey(key string) error {
+	if len(key) > 512 || !validKey.MatchString(key) || strings.Contains(key, "..") {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+
+	return nil
+}
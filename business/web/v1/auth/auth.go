@@ -7,12 +7,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	"github.com/hpetrov29/resttemplate/business/core/apikey/stores/apikeysqldb"
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/role/stores/rolesqldb"
 	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/core/user/stores/usersqldb"
 	"github.com/hpetrov29/resttemplate/internal/logger"
 	"github.com/jmoiron/sqlx"
 	"github.com/open-policy-agent/opa/rego"
@@ -27,6 +33,18 @@ type Claims struct {
 	jwt.RegisteredClaims
 	Email string `json:"email"`
 	Roles []user.Role `json:"roles"`
+
+	// Scopes limits the permissions granted by the roles. It is only set for
+	// claims produced from an API key, a nil value means no limit.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// Resource describes the target of an action for ownership based rules.
+type Resource struct {
+	Type       string
+	ID         int64
+	OwnerID    int64
+	Attributes map[string]any
 }
 
 type Vault interface {
@@ -40,18 +58,31 @@ type Config struct {
 	DB        		*sqlx.DB
 	Issuer    		string
 	Vault 			Vault	
+
+	// PolicyDir is an optional directory with authentication.rego and
+	// authorization.rego files that replace the embedded policies.
+	PolicyDir 		string
+
+	// PolicyReloadInterval is how often PolicyDir is checked for changes.
+	// Policies are not reloaded when it is zero.
+	PolicyReloadInterval time.Duration
 }
 
 // Auth is used to authenticate clients. It can generate a token for a
 // set of user claims and recreate the claims by parsing the token.
 type Auth struct {
 	log       			*logger.Logger
+	user      			*user.Core
+	role      			*role.Core
+	apiKey    			*apikey.Core
 	vault 	  			Vault
 	method    			jwt.SigningMethod
 	parser    			*jwt.Parser
 	issuer    			string
 	mu        			sync.RWMutex
 	cache     			map[string]string
+	policy    			*policy
+	shutdown  			chan struct{}
 }
 
 // New creates an Auth to support authentication/authorization.
@@ -64,11 +95,37 @@ func New(cfg Config) (*Auth, error) {
 		parser:    			 jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name})),
 		issuer:				 cfg.Issuer,
 		cache:    			 make(map[string]string),
+		shutdown:  			 make(chan struct{}),
+	}
+
+	policy, err := newPolicy(context.Background(), cfg.Log, cfg.PolicyDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading policies: %w", err)
+	}
+	a.policy = policy
+
+	if cfg.PolicyDir != "" && cfg.PolicyReloadInterval > 0 {
+		go a.policy.watch(cfg.PolicyReloadInterval, a.shutdown)
+	}
+
+	// The user core is needed to verify that the token owner is still enabled,
+	// the role core to resolve the permissions granted by their roles and the
+	// api key core to authenticate machine clients. They are left out when no
+	// database is provided.
+	if cfg.DB != nil {
+		a.user = user.NewCore(usersqldb.NewStore(cfg.Log, cfg.DB), cfg.Log, nil)
+		a.role = role.NewCore(rolesqldb.NewStore(cfg.Log, cfg.DB), cfg.Log)
+		a.apiKey = apikey.NewCore(apikeysqldb.NewStore(cfg.Log, cfg.DB), cfg.Log, nil)
 	}
 
 	return &a, nil
 }
 
+// Close stops watching the policy directory for changes.
+func (a *Auth) Close() {
+	close(a.shutdown)
+}
+
 // GenerateToken generates a signed JWT token string representing the user Claims.
 func (a *Auth) GenerateToken(kid string, claims Claims) (string, error) {
 	token := jwt.NewWithClaims(a.method, claims)
@@ -129,39 +186,176 @@ func (a *Auth) Authenticate(ctx context.Context, authJWT string) (Claims, error)
 		"ISS":   a.issuer, //BE VERY CAREFUL WITH THE ISSUER NAME IF AUTH FAILS IT'S PROBABLY BECAUSE OF THIS
 	}
 
-	if err := a.opaPolicyEvaluation(ctx, opaAuthentication, RuleAuthenticate, input); err != nil {
+	if err := a.opaPolicyEvaluation(ctx, RuleAuthenticate, input); err != nil {
+		return Claims{}, fmt.Errorf("authentication failed : %w", err)
+	}
+
+	// Check the database for this user to verify they are still enabled.
+	if err := a.isUserEnabled(ctx, claims); err != nil {
 		return Claims{}, fmt.Errorf("authentication failed : %w", err)
 	}
 
 	return claims, nil
 }
 
-// Authorize attempts to authorize the user with the provided input roles, if
-// none of the input roles are within the user's claims, we return an error
-// otherwise the user is authorized.
-func (a *Auth) Authorize(ctx context.Context, claims Claims, userID uuid.UUID, rule string) error {
+// AuthenticateAPIKey validates an API key and produces the claims of the user
+// owning it. The claims carry the scopes of the key so authorization only
+// grants the permissions that are both held by the user's roles and allowed
+// by the key.
+func (a *Auth) AuthenticateAPIKey(ctx context.Context, key string) (Claims, error) {
+	if a.apiKey == nil {
+		return Claims{}, errors.New("api keys are not supported")
+	}
+
+	k, err := a.apiKey.Authenticate(ctx, key)
+	if err != nil {
+		return Claims{}, fmt.Errorf("authentication failed : %w", err)
+	}
+
+	usr, err := a.user.QueryById(ctx, k.UserId)
+	if err != nil {
+		return Claims{}, fmt.Errorf("authentication failed : query user: %w", err)
+	}
+
+	if !usr.Enabled {
+		return Claims{}, fmt.Errorf("authentication failed : %w", user.ErrUserDisabled)
+	}
+
+	scopes := make([]string, len(k.Scopes))
+	for i, perm := range k.Scopes {
+		scopes[i] = perm.Name()
+	}
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: strconv.FormatInt(usr.Id, 10),
+			Issuer:  a.issuer,
+			ID:      strconv.FormatInt(k.Id, 10),
+		},
+		Email:  usr.Email.Address,
+		Roles:  usr.Roles,
+		Scopes: scopes,
+	}
+
+	if !k.ExpiresAt.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(k.ExpiresAt)
+	}
+
+	return claims, nil
+}
+
+// Authorize attempts to authorize the user against the specified rule. The
+// roles within the claims are resolved to the permissions they grant and the
+// policy is evaluated against those permissions. A zero userID means the
+// request does not target a specific user.
+func (a *Auth) Authorize(ctx context.Context, claims Claims, userID int64, rule string) error {
+	perms, err := a.permissions(ctx, claims)
+	if err != nil {
+		return err
+	}
+
+	input := map[string]any{
+		"Permissions": perms,
+		"Subject":     claims.Subject,
+		"UserID":      strconv.FormatInt(userID, 10),
+	}
+
+	if err := a.opaPolicyEvaluation(ctx, rule, input); err != nil {
+		return fmt.Errorf("rego evaluation failed : %w", err)
+	}
+
+	return nil
+}
+
+// AuthorizeResource attempts to authorize the user to act on a resource that
+// was loaded by the caller. The owner and attributes of the resource are part
+// of the policy input so rules such as "the author or an admin may edit" can
+// be expressed in rego.
+func (a *Auth) AuthorizeResource(ctx context.Context, claims Claims, res Resource, rule string) error {
+	perms, err := a.permissions(ctx, claims)
+	if err != nil {
+		return err
+	}
+
+	attributes := res.Attributes
+	if attributes == nil {
+		attributes = map[string]any{}
+	}
+
+	input := map[string]any{
+		"Permissions": perms,
+		"Subject":     claims.Subject,
+		"Resource": map[string]any{
+			"Type":       res.Type,
+			"ID":         strconv.FormatInt(res.ID, 10),
+			"OwnerID":    strconv.FormatInt(res.OwnerID, 10),
+			"Attributes": attributes,
+		},
+	}
+
+	if err := a.opaPolicyEvaluation(ctx, rule, input); err != nil {
+		return fmt.Errorf("rego evaluation failed : %w", err)
+	}
+
+	return nil
+}
+
+// AuthorizePermission attempts to authorize the user by checking that one of
+// the user's roles grants the specified permission.
+func (a *Auth) AuthorizePermission(ctx context.Context, claims Claims, perm role.Permission) error {
+	perms, err := a.permissions(ctx, claims)
+	if err != nil {
+		return err
+	}
+
 	input := map[string]any{
-		"Roles":   claims.Roles,
-		"Subject": claims.Subject,
-		"UserID":  userID,
+		"Permissions": perms,
+		"Permission":  perm.Name(),
+		"Subject":     claims.Subject,
 	}
 
-	if err := a.opaPolicyEvaluation(ctx, opaAuthorization, rule, input); err != nil {
+	if err := a.opaPolicyEvaluation(ctx, RulePermission, input); err != nil {
 		return fmt.Errorf("rego evaluation failed : %w", err)
 	}
 
 	return nil
 }
 
-// opaPolicyEvaluation asks opa to evaulate the token against the specified token
-// policy and public key.
-func (a *Auth) opaPolicyEvaluation(ctx context.Context, opaPolicy string, rule string, input any) error {
-	query := fmt.Sprintf("x = data.%s.%s", opaPackage, rule)
+// permissions resolves the names of the permissions granted by the roles
+// within the claims, limited to the scopes of the claims when they are set.
+func (a *Auth) permissions(ctx context.Context, claims Claims) ([]string, error) {
+	if a.role == nil {
+		return []string{}, nil
+	}
 
-	q, err := rego.New(
-		rego.Query(query),
-		rego.Module("policy.rego", opaPolicy),
-	).PrepareForEval(ctx)
+	perms, err := a.role.Permissions(ctx, claims.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("resolving permissions: %w", err)
+	}
+
+	var scopes map[string]bool
+	if claims.Scopes != nil {
+		scopes = make(map[string]bool, len(claims.Scopes))
+		for _, scope := range claims.Scopes {
+			scopes[scope] = true
+		}
+	}
+
+	names := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		if scopes != nil && !scopes[perm.Name()] {
+			continue
+		}
+		names = append(names, perm.Name())
+	}
+
+	return names, nil
+}
+
+// opaPolicyEvaluation asks opa to evaulate the input against the prepared
+// query of the specified rule.
+func (a *Auth) opaPolicyEvaluation(ctx context.Context, rule string, input any) error {
+	q, err := a.policy.query(rule)
 	if err != nil {
 		return err
 	}
@@ -183,6 +377,30 @@ func (a *Auth) opaPolicyEvaluation(ctx context.Context, opaPolicy string, rule s
 	return nil
 }
 
+// isUserEnabled hits the database and checks the user is not disabled. If
+// no user core was configured, this check is skipped.
+func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
+	if a.user == nil {
+		return nil
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse user id: %w", err)
+	}
+
+	usr, err := a.user.QueryById(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("query user: %w", err)
+	}
+
+	if !usr.Enabled {
+		return user.ErrUserDisabled
+	}
+
+	return nil
+}
+
 // publicKeyLookup performs a lookup for the public pem for the specified kid.
 func (a *Auth) PublicKeyLookup(kid string) (string, error) {
 	pem, err := func() (string, error) {
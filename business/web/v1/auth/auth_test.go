@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// postEditInput is the input of an owner editing their post.
+var postEditInput = map[string]any{
+	"Permissions": []string{"post:create"},
+	"Subject":     "1",
+	"Resource": map[string]any{
+		"Type":       "post",
+		"ID":         "10",
+		"OwnerID":    "1",
+		"Attributes": map[string]any{},
+	},
+}
+
+// BenchmarkPrepareEveryRequest compiles the policy on every evaluation, as
+// authorization did before the queries were prepared once.
+func BenchmarkPrepareEveryRequest(b *testing.B) {
+	ctx := context.Background()
+	module := embeddedPolicies[rulePolicies[RulePostEdit]]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q, err := rego.New(
+			rego.Query(fmt.Sprintf("x = data.%s.%s", opaPackage, RulePostEdit)),
+			rego.Module(rulePolicies[RulePostEdit], module),
+		).PrepareForEval(ctx)
+		if err != nil {
+			b.Fatalf("prepare: %s", err)
+		}
+
+		if _, err := q.Eval(ctx, rego.EvalInput(postEditInput)); err != nil {
+			b.Fatalf("eval: %s", err)
+		}
+	}
+}
+
+// BenchmarkPreparedQuery evaluates the query prepared when Auth is created.
+func BenchmarkPreparedQuery(b *testing.B) {
+	ctx := context.Background()
+
+	log := logger.NewWithEvents(io.Discard, logger.LevelError, "AUTH", func(context.Context) string { return "" }, logger.Events{})
+	a, err := New(Config{Log: log})
+	if err != nil {
+		b.Fatalf("new: %s", err)
+	}
+	defer a.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := a.opaPolicyEvaluation(ctx, RulePostEdit, postEditInput); err != nil {
+			b.Fatalf("eval: %s", err)
+		}
+	}
+}
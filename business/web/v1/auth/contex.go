@@ -2,8 +2,6 @@ package auth
 
 import (
 	"context"
-
-	"github.com/google/uuid"
 )
 
 // ctxKey represents the type of value for the context key.
@@ -32,6 +30,15 @@ func GetClaims(ctx context.Context) Claims {
 }
 
 // SetUserID stores the user id from the request in the context.
-func SetUserID(ctx context.Context, userID uuid.UUID) context.Context {
+func SetUserID(ctx context.Context, userID int64) context.Context {
 	return context.WithValue(ctx, userKey, userID)
-}
\ No newline at end of file
+}
+
+// GetUserID returns the user id from the context.
+func GetUserID(ctx context.Context) int64 {
+	v, ok := ctx.Value(userKey).(int64)
+	if !ok {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// policy holds a prepared query for every rule so the rego modules are only
+// compiled when they are loaded, not on every evaluation.
+type policy struct {
+	log      *logger.Logger
+	dir      string
+	mu       sync.RWMutex
+	queries  map[string]rego.PreparedEvalQuery
+	modTimes map[string]time.Time
+}
+
+// newPolicy loads the policies from dir, falling back to the embedded
+// policies for any file that is missing, and prepares the query of every rule.
+// An empty dir only uses the embedded policies.
+func newPolicy(ctx context.Context, log *logger.Logger, dir string) (*policy, error) {
+	p := policy{
+		log: log,
+		dir: dir,
+	}
+
+	modules, modTimes, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+
+	queries, err := prepare(ctx, modules)
+	if err != nil {
+		return nil, err
+	}
+
+	p.queries = queries
+	p.modTimes = modTimes
+
+	return &p, nil
+}
+
+// query returns the prepared query for the specified rule.
+func (p *policy) query(rule string) (rego.PreparedEvalQuery, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	q, exists := p.queries[rule]
+	if !exists {
+		return rego.PreparedEvalQuery{}, fmt.Errorf("rule %q is not defined", rule)
+	}
+
+	return q, nil
+}
+
+// reload prepares the queries again if any of the policy files changed since
+// they were last loaded. The previous queries are kept if the new policies
+// fail to compile.
+func (p *policy) reload(ctx context.Context) error {
+	modules, modTimes, err := p.load()
+	if err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	changed := !sameModTimes(p.modTimes, modTimes)
+	p.mu.RUnlock()
+
+	if !changed {
+		return nil
+	}
+
+	queries, err := prepare(ctx, modules)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.queries = queries
+	p.modTimes = modTimes
+
+	return nil
+}
+
+// watch checks the policy directory for changes on every interval until the
+// shutdown channel is closed.
+func (p *policy) watch(interval time.Duration, shutdown <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx := context.Background()
+			if err := p.reload(ctx); err != nil {
+				p.log.Error(ctx, "auth: reloading policies", "dir", p.dir, "msg", err)
+			}
+
+		case <-shutdown:
+			return
+		}
+	}
+}
+
+// load reads every policy file from the policy directory. Files that do not
+// exist there are taken from the embedded policies and get a zero mod time.
+func (p *policy) load() (map[string]string, map[string]time.Time, error) {
+	modules := make(map[string]string, len(embeddedPolicies))
+	modTimes := make(map[string]time.Time, len(embeddedPolicies))
+
+	for name, embedded := range embeddedPolicies {
+		modules[name] = embedded
+
+		if p.dir == "" {
+			continue
+		}
+
+		path := filepath.Join(p.dir, name)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("stat policy[%s]: %w", path, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read policy[%s]: %w", path, err)
+		}
+
+		modules[name] = string(data)
+		modTimes[name] = info.ModTime()
+	}
+
+	return modules, modTimes, nil
+}
+
+// prepare compiles the query of every rule against the module it is defined in.
+func prepare(ctx context.Context, modules map[string]string) (map[string]rego.PreparedEvalQuery, error) {
+	queries := make(map[string]rego.PreparedEvalQuery, len(rulePolicies))
+
+	for rule, name := range rulePolicies {
+		q, err := rego.New(
+			rego.Query(fmt.Sprintf("x = data.%s.%s", opaPackage, rule)),
+			rego.Module(name, modules[name]),
+		).PrepareForEval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("prepare rule[%s]: %w", rule, err)
+		}
+
+		queries[rule] = q
+	}
+
+	return queries, nil
+}
+
+func sameModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, t := range a {
+		if !t.Equal(b[name]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	"github.com/open-policy-agent/opa/tester"
+)
+
+// policyTests are the rego test files run against the embedded policies.
+var policyTests = []string{"authorization_test.rego"}
+
+// TestPolicies runs the rego tests of the rego directory against the policies
+// embedded in the binary, each rego test is reported as a subtest.
+func TestPolicies(t *testing.T) {
+	modules := make(map[string]*ast.Module)
+
+	for name, policy := range embeddedPolicies {
+		m, err := ast.ParseModule(name, policy)
+		if err != nil {
+			t.Fatalf("parse %s: %s", name, err)
+		}
+		modules[name] = m
+	}
+
+	for _, name := range policyTests {
+		src, err := os.ReadFile(filepath.Join("rego", name))
+		if err != nil {
+			t.Fatalf("read %s: %s", name, err)
+		}
+
+		m, err := ast.ParseModule(name, string(src))
+		if err != nil {
+			t.Fatalf("parse %s: %s", name, err)
+		}
+		modules[name] = m
+	}
+
+	ch, err := tester.NewRunner().SetStore(inmem.New()).Run(context.Background(), modules)
+	if err != nil {
+		t.Fatalf("run: %s", err)
+	}
+
+	var n int
+	for res := range ch {
+		n++
+		t.Run(res.Name, func(t *testing.T) {
+			switch {
+			case res.Error != nil:
+				t.Fatalf("%s: %s", res.Location, res.Error)
+			case !res.Pass():
+				t.Fatalf("%s: failed at %v", res.Location, res.FailedAt)
+			}
+		})
+	}
+
+	if n == 0 {
+		t.Fatal("no rego tests found")
+	}
+}
@@ -4,36 +4,91 @@ default ruleAny = false
 default ruleAdminOnly = false
 default ruleUserOnly = false
 default ruleAdminOrSubject = false
+default rulePermission = false
+default rulePostEdit = false
+default rulePostDelete = false
+default ruleCommentDelete = false
 
-roleUser := "USER"
-roleAdmin := "ADMIN"
-roleAll := {roleAdmin, roleUser}
+# Permissions are resolved from the roles of the user before the policy is
+# evaluated, the policy never sees role names.
+permAdmin := "user:manage"
+permAuthor := "post:create"
+permCommenter := "comment:create"
+
+has_permission(perm) {
+	input.Permissions[_] == perm
+}
 
 ruleAny {
-	claim_roles := {role | role := input.Roles[_]}
-	input_roles := roleAll & claim_roles
-	count(input_roles) > 0
+	count(input.Permissions) > 0
 }
 
 ruleAdminOnly {
-	claim_roles := {role | role := input.Roles[_]}
-	input_admin := {roleAdmin} & claim_roles
-	count(input_admin) > 0
+	has_permission(permAdmin)
 }
 
 ruleUserOnly {
-	claim_roles := {role | role := input.Roles[_]}
-	input_user := {roleUser} & claim_roles
-	count(input_user) > 0
+	has_permission(permAuthor)
 }
 
 ruleAdminOrSubject {
-	claim_roles := {role | role := input.Roles[_]}
-	input_admin := {roleAdmin} & claim_roles
-    count(input_admin) > 0
+	has_permission(permAdmin)
 } else {
-    claim_roles := {role | role := input.Roles[_]}
-	input_user := {roleUser} & claim_roles
-	count(input_user) > 0
+	count(input.Permissions) > 0
 	input.UserID == input.Subject
 }
+
+rulePermission {
+	has_permission(input.Permission)
+}
+
+# =============================================================================
+# Resource rules receive the resource being acted on in input.Resource with its
+# Type, ID, OwnerID and Attributes. IDs are passed as strings so they can be
+# compared with the subject of the claims.
+#
+# Owning a resource is not enough to act on it, the owner must also hold the
+# permission to create such resources. API keys carry a subset of the
+# permissions of their owner, a key scoped to comments cannot touch posts.
+
+is_owner {
+	input.Resource.OwnerID == input.Subject
+}
+
+rulePostEdit {
+	input.Resource.Type == "post"
+	is_owner
+	has_permission(permAuthor)
+}
+
+rulePostEdit {
+	input.Resource.Type == "post"
+	has_permission("post:edit:any")
+}
+
+rulePostDelete {
+	input.Resource.Type == "post"
+	is_owner
+	has_permission(permAuthor)
+}
+
+rulePostDelete {
+	input.Resource.Type == "post"
+	has_permission("post:delete:any")
+}
+
+ruleCommentDelete {
+	input.Resource.Type == "comment"
+	is_owner
+	has_permission(permCommenter)
+}
+
+ruleCommentDelete {
+	input.Resource.Type == "comment"
+	has_permission("comment:delete:any")
+}
+
+ruleCommentDelete {
+	input.Resource.Type == "comment"
+	has_permission("comment:moderate")
+}
@@ -0,0 +1,90 @@
+package hpetrov29.rego
+
+# Run with: opa test business/web/v1/auth/rego -v
+
+post_resource := {"Type": "post", "ID": "10", "OwnerID": "1", "Attributes": {}}
+comment_resource := {"Type": "comment", "ID": "20", "OwnerID": "1", "Attributes": {"postId": "10"}}
+
+# =============================================================================
+# rulePostEdit
+
+test_post_edit_owner {
+	rulePostEdit with input as {"Subject": "1", "Permissions": ["post:create"], "Resource": post_resource}
+}
+
+test_post_edit_owner_key_without_scope_denied {
+	not rulePostEdit with input as {"Subject": "1", "Permissions": ["comment:create"], "Resource": post_resource}
+}
+
+test_post_edit_owner_without_permissions_denied {
+	not rulePostEdit with input as {"Subject": "1", "Permissions": [], "Resource": post_resource}
+}
+
+test_post_edit_other_user_denied {
+	not rulePostEdit with input as {"Subject": "2", "Permissions": ["post:create"], "Resource": post_resource}
+}
+
+test_post_edit_editor {
+	rulePostEdit with input as {"Subject": "2", "Permissions": ["post:edit:any"], "Resource": post_resource}
+}
+
+test_post_edit_wrong_type_denied {
+	not rulePostEdit with input as {"Subject": "1", "Permissions": ["post:edit:any"], "Resource": comment_resource}
+}
+
+# =============================================================================
+# rulePostDelete
+
+test_post_delete_owner {
+	rulePostDelete with input as {"Subject": "1", "Permissions": ["post:create"], "Resource": post_resource}
+}
+
+test_post_delete_owner_key_without_scope_denied {
+	not rulePostDelete with input as {"Subject": "1", "Permissions": ["comment:create"], "Resource": post_resource}
+}
+
+test_post_delete_owner_without_permissions_denied {
+	not rulePostDelete with input as {"Subject": "1", "Permissions": [], "Resource": post_resource}
+}
+
+test_post_delete_other_user_denied {
+	not rulePostDelete with input as {"Subject": "2", "Permissions": ["post:create", "post:edit:any"], "Resource": post_resource}
+}
+
+test_post_delete_editor {
+	rulePostDelete with input as {"Subject": "2", "Permissions": ["post:delete:any"], "Resource": post_resource}
+}
+
+# =============================================================================
+# ruleCommentDelete
+
+test_comment_delete_owner {
+	ruleCommentDelete with input as {"Subject": "1", "Permissions": ["comment:create"], "Resource": comment_resource}
+}
+
+test_comment_delete_owner_key_without_scope_denied {
+	not ruleCommentDelete with input as {"Subject": "1", "Permissions": ["post:create"], "Resource": comment_resource}
+}
+
+test_comment_delete_other_user_denied {
+	not ruleCommentDelete with input as {"Subject": "2", "Permissions": ["comment:create"], "Resource": comment_resource}
+}
+
+test_comment_delete_any {
+	ruleCommentDelete with input as {"Subject": "2", "Permissions": ["comment:delete:any"], "Resource": comment_resource}
+}
+
+test_comment_delete_moderator {
+	ruleCommentDelete with input as {"Subject": "2", "Permissions": ["comment:moderate"], "Resource": comment_resource}
+}
+
+# =============================================================================
+# ruleAdminOrSubject
+
+test_admin_or_subject_subject {
+	ruleAdminOrSubject with input as {"Subject": "1", "UserID": "1", "Permissions": ["post:create"]}
+}
+
+test_admin_or_subject_denied {
+	not ruleAdminOrSubject with input as {"Subject": "1", "UserID": "2", "Permissions": ["post:create"]}
+}
@@ -11,6 +11,16 @@ const (
 	RuleAdminOnly      = "ruleAdminOnly"
 	RuleUserOnly       = "ruleUserOnly"
 	RuleAdminOrSubject = "ruleAdminOrSubject"
+	RulePermission     = "rulePermission"
+	RulePostEdit       = "rulePostEdit"
+	RulePostDelete     = "rulePostDelete"
+	RuleCommentDelete  = "ruleCommentDelete"
+)
+
+// Set of resource types passed to the resource rules.
+const (
+	ResourcePost    = "post"
+	ResourceComment = "comment"
 )
 
 // Package name of our rego code.
@@ -18,6 +28,13 @@ const (
 	opaPackage string = "hpetrov29.rego"
 )
 
+// Names of the policy files, both for the embedded policies and for the
+// files looked up in an external policy directory.
+const (
+	policyAuthentication = "authentication.rego"
+	policyAuthorization  = "authorization.rego"
+)
+
 // Core OPA policies.
 var (
 	//go:embed rego/authentication.rego
@@ -26,3 +43,24 @@ var (
 	//go:embed rego/authorization.rego
 	opaAuthorization string
 )
+
+// embeddedPolicies are used whenever a policy file is not found in the
+// external policy directory.
+var embeddedPolicies = map[string]string{
+	policyAuthentication: opaAuthentication,
+	policyAuthorization:  opaAuthorization,
+}
+
+// rulePolicies maps every rule to the policy file it is defined in. A query
+// is prepared for each of these rules when the policies are loaded.
+var rulePolicies = map[string]string{
+	RuleAuthenticate:   policyAuthentication,
+	RuleAny:            policyAuthorization,
+	RuleAdminOnly:      policyAuthorization,
+	RuleUserOnly:       policyAuthorization,
+	RuleAdminOrSubject: policyAuthorization,
+	RulePermission:     policyAuthorization,
+	RulePostEdit:       policyAuthorization,
+	RulePostDelete:     policyAuthorization,
+	RuleCommentDelete:  policyAuthorization,
+}
@@ -0,0 +1,187 @@
+// Package debug provides the handlers served by the debug listener of the
+// service: the profiler, the exported runtime variables, the prometheus
+// metrics and the liveness and readiness probes.
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/data/cache"
+	"github.com/hpetrov29/resttemplate/business/data/dbnosql"
+	mysql "github.com/hpetrov29/resttemplate/business/data/dbsql/mysql"
+	"github.com/hpetrov29/resttemplate/business/data/storage"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/metrics"
+	"github.com/jmoiron/sqlx"
+)
+
+// statusChecker is implemented by the messaging client, whose status check
+// does not take a context.
+type statusChecker interface {
+	StatusCheck() error
+}
+
+// Config contains the systems checked by the readiness probe.
+type Config struct {
+	Build        string
+	Log          *logger.Logger
+	Cache        cache.Cache
+	SQLDB        *sqlx.DB
+	NOSQLDB      dbnosql.NOSQLDB
+	Messaging    statusChecker
+	Storage      storage.BlobStore
+	CheckTimeout time.Duration
+}
+
+// Mux registers the debug routes on a new mux. A dedicated mux is used
+// instead of http.DefaultServeMux so that nothing registered on the default
+// mux by a dependency is exposed.
+func Mux(cfg Config) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/metrics", metrics.Handler())
+
+	cg := checkGroup{cfg: cfg}
+	mux.HandleFunc("/debug/liveness", cg.liveness)
+	mux.HandleFunc("/debug/readiness", cg.readiness)
+
+	return mux
+}
+
+// =============================================================================
+
+// checkGroup holds the handlers of the liveness and readiness probes.
+type checkGroup struct {
+	cfg Config
+}
+
+// liveness reports that the service is running along with information about
+// the host. It does not talk to any dependency, an unreachable database must
+// not get the instance restarted.
+func (cg checkGroup) liveness(w http.ResponseWriter, r *http.Request) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unavailable"
+	}
+
+	data := struct {
+		Status     string `json:"status"`
+		Build      string `json:"build"`
+		Host       string `json:"host"`
+		GOMAXPROCS int    `json:"GOMAXPROCS"`
+	}{
+		Status:     "up",
+		Build:      cg.cfg.Build,
+		Host:       host,
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+	}
+
+	cg.respond(r.Context(), w, http.StatusOK, data)
+}
+
+// readiness runs the status check of every store concurrently and reports
+// the result of each of them. The service is ready only if all checks pass.
+func (cg checkGroup) readiness(w http.ResponseWriter, r *http.Request) {
+	timeout := cg.cfg.CheckTimeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	checks := map[string]func(ctx context.Context) error{
+		"cache": func(ctx context.Context) error {
+			return cg.cfg.Cache.StatusCheck(ctx)
+		},
+		"sqldb": func(ctx context.Context) error {
+			return mysql.StatusCheck(ctx, cg.cfg.SQLDB)
+		},
+		"nosqldb": func(ctx context.Context) error {
+			return cg.cfg.NOSQLDB.StatusCheck(ctx)
+		},
+		"messaging": func(ctx context.Context) error {
+			return cg.cfg.Messaging.StatusCheck()
+		},
+		"storage": func(ctx context.Context) error {
+			return cg.cfg.Storage.StatusCheck(ctx)
+		},
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]string, len(checks))
+		ready   = true
+	)
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+
+			status := "ok"
+			if err := runCheck(ctx, check); err != nil {
+				cg.cfg.Log.Info(ctx, "readiness failure", "check", name, "msg", err)
+				status = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = status
+			if status != "ok" {
+				ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	statusCode := http.StatusOK
+	if !ready {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	cg.respond(r.Context(), w, statusCode, results)
+}
+
+// respond writes data as the JSON body of the response.
+func (cg checkGroup) respond(ctx context.Context, w http.ResponseWriter, statusCode int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		cg.cfg.Log.Error(ctx, "debug respond", "msg", err)
+	}
+}
+
+// runCheck runs check and gives up once ctx is done. Some status checks
+// retry internally without looking at the context, they are abandoned to
+// finish in the background.
+func runCheck(ctx context.Context, check func(ctx context.Context) error) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- check(ctx)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return errors.New("status check timed out")
+	}
+}
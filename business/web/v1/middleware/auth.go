@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
-	"github.com/google/uuid"
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	"github.com/hpetrov29/resttemplate/business/core/role"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
 	"github.com/hpetrov29/resttemplate/business/web/v1/response"
 	"github.com/hpetrov29/resttemplate/internal/web"
@@ -22,7 +25,7 @@ func Authenticate(a *auth.Auth) web.Middleware {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 			claims, err := a.Authenticate(ctx, r.Header.Get("authorization"))
 			if err != nil {
-				return web.Respond(ctx, w, http.StatusUnauthorized, err)
+				return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
 			}
 
 			ctx = auth.SetClaims(ctx, claims)
@@ -36,6 +39,39 @@ func Authenticate(a *auth.Auth) web.Middleware {
 	return m
 }
 
+// AuthenticateKeyOrToken accepts either a JWT or an API key in the
+// `Authorization: Bearer` header. API keys produce claims limited to the
+// scopes of the key, so the handlers behind this middleware work the same for
+// users and machine clients.
+func AuthenticateKeyOrToken(a *auth.Auth) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			header := r.Header.Get("authorization")
+
+			bearer, found := strings.CutPrefix(header, "Bearer ")
+			if !found || !apikey.IsKey(bearer) {
+				claims, err := a.Authenticate(ctx, header)
+				if err != nil {
+					return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
+				}
+
+				return handler(auth.SetClaims(ctx, claims), w, r)
+			}
+
+			claims, err := a.AuthenticateAPIKey(ctx, bearer)
+			if err != nil {
+				return web.Respond(ctx, w, http.StatusUnauthorized, response.Unauthenticated(err))
+			}
+
+			return handler(auth.SetClaims(ctx, claims), w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
 // Authorize validates that an authenticated user has at least one role from a
 // specified list. This method constructs the actual function that is used.
 func Authorize(a *auth.Auth, rule string) web.Middleware {
@@ -47,13 +83,13 @@ func Authorize(a *auth.Auth, rule string) web.Middleware {
 			}
 
 			// I will use a zero valued user id if it doesn't exsit.
-			var userID uuid.UUID
+			var userID int64
 			id := web.Param(r, "user_id")
 			if id != "" {
 				var err error
-				userID, err = uuid.Parse(id)
+				userID, err = strconv.ParseInt(id, 10, 64)
 				if err != nil {
-					return response.NewError(ErrInvalidID, http.StatusBadRequest)
+					return response.NewError(web.NewError(response.CodeInvalidId, ErrInvalidID), http.StatusBadRequest)
 				}
 				ctx = auth.SetUserID(ctx, userID)
 			}
@@ -70,3 +106,26 @@ func Authorize(a *auth.Auth, rule string) web.Middleware {
 
 	return m
 }
+
+// RequirePermission validates that an authenticated user holds a role which
+// grants the specified permission.
+func RequirePermission(a *auth.Auth, perm role.Permission) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			claims := auth.GetClaims(ctx)
+			if claims.Subject == "" {
+				return auth.NewAuthError("authorize: you are not authorized for that action, no claims")
+			}
+
+			if err := a.AuthorizePermission(ctx, claims, perm); err != nil {
+				return auth.NewAuthError("authorize: you are not authorized for that action, claims[%v] permission[%v]: %s", claims.Roles, perm.Name(), err)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
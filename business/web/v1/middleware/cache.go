@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// CacheControl sets the Cache-Control policy of the route. The policies are
+// defined in the web package, e.g. web.CacheRevalidate.
+func CacheControl(policy string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			web.SetCacheControl(w, policy)
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/hpetrov29/resttemplate/business/core/comment"
+	"github.com/hpetrov29/resttemplate/business/core/post"
+)
+
+// ctxKey represents the type of value for the context key.
+type ctxKey int
+
+// Set of keys used to store/retrieve the resources loaded by the
+// authorization middlewares.
+const (
+	postKey ctxKey = iota + 1
+	commentKey
+)
+
+func setPost(ctx context.Context, p post.Post) context.Context {
+	return context.WithValue(ctx, postKey, p)
+}
+
+// GetPost returns the post loaded by AuthorizePost.
+func GetPost(ctx context.Context) (post.Post, bool) {
+	v, ok := ctx.Value(postKey).(post.Post)
+	return v, ok
+}
+
+func setComment(ctx context.Context, c comment.Comment) context.Context {
+	return context.WithValue(ctx, commentKey, c)
+}
+
+// GetComment returns the comment loaded by AuthorizeComment.
+func GetComment(ctx context.Context) (comment.Comment, bool) {
+	v, ok := ctx.Value(commentKey).(comment.Comment)
+	return v, ok
+}
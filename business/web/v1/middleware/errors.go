@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hpetrov29/resttemplate/business/core/apikey"
+	"github.com/hpetrov29/resttemplate/business/core/comment"
+	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/core/role"
+	"github.com/hpetrov29/resttemplate/business/core/user"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/validate"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// notFound holds the not found errors of the core packages. Handlers that
+// return one of them without responding get a 404.
+var notFound = []error{
+	user.ErrNotFound,
+	role.ErrNotFound,
+	post.ErrNotFound,
+	comment.ErrNotFound,
+	apikey.ErrNotFound,
+}
+
+// Errors handles errors coming out of the call chain. It detects normal
+// application errors which are used to respond to the client in a uniform way.
+// Unexpected errors (status >= 500) are sent with a generic message, their
+// text is only logged by the Logger middleware.
+func Errors(log *logger.Logger) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			err := handler(ctx, w, r)
+			if err == nil {
+				return nil
+			}
+
+			status, data := mapError(ctx, err)
+
+			if err := web.Respond(ctx, w, status, data); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// mapError returns the status code and the error sent to the client for an
+// error returned by a handler. web.Respond hides the text of server errors, so
+// the original error is kept to be logged.
+func mapError(ctx context.Context, err error) (int, error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, &web.Error{Code: web.CodeTimeout, Message: "request timed out", Err: err}
+
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		return web.StatusClientClosedRequest, &web.Error{Code: web.CodeRequestCancelled, Message: "request cancelled", Err: err}
+
+	case response.IsError(err):
+		reqErr := response.GetError(err)
+		return reqErr.Status, reqErr.Err
+
+	case validate.IsFieldErrors(err):
+		return http.StatusBadRequest, validate.GetFieldErrors(err)
+
+	case auth.IsAuthError(err):
+		return http.StatusForbidden, auth.ErrForbidden
+	}
+
+	for _, nf := range notFound {
+		if errors.Is(err, nf) {
+			return http.StatusNotFound, nf
+		}
+	}
+
+	return http.StatusInternalServerError, err
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/data/cache"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// Set of error variables for idempotent requests.
+var (
+	ErrIdempotencyInProgress = errors.New("a request with this Idempotency-Key is still being processed")
+	ErrIdempotencyMismatch   = errors.New("Idempotency-Key was already used with a different request")
+	ErrIdempotencyKeyLength  = errors.New("Idempotency-Key must be at most 255 characters")
+)
+
+// processingTTL bounds how long a request holds its key while running. It
+// frees the key of a request whose instance died before storing a response.
+const processingTTL = time.Minute
+
+// Set of states of an idempotency record.
+const (
+	idempotencyProcessing = "processing"
+	idempotencyCompleted  = "completed"
+)
+
+// idempotencyRecord is stored in the cache under the key of the request.
+type idempotencyRecord struct {
+	State       string      `json:"state"`
+	Fingerprint string      `json:"fingerprint"`
+	StatusCode  int         `json:"status_code,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Idempotency makes the route safe to retry when the client sends an
+// `Idempotency-Key` header. The first response is stored for window and
+// replayed to retries of the same request, while a retry arriving before the
+// first request completed gets a 409. Keys are scoped to the authenticated
+// subject, so the middleware has to run after the authentication middleware
+// of the route. Requests without the header are not affected.
+func Idempotency(store cache.Cache, window time.Duration) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			idemKey := r.Header.Get("Idempotency-Key")
+			if idemKey == "" || store == nil {
+				return handler(ctx, w, r)
+			}
+			if len(idemKey) > 255 {
+				return response.NewError(ErrIdempotencyKeyLength, http.StatusBadRequest)
+			}
+
+			fingerprint, err := fingerprintRequest(r)
+			if err != nil {
+				return response.NewError(err, http.StatusBadRequest)
+			}
+
+			key := fmt.Sprintf("idempotency:%s:%s %s:%s", auth.GetClaims(ctx).Subject, r.Method, r.URL.Path, idemKey)
+
+			lock, err := json.Marshal(idempotencyRecord{State: idempotencyProcessing, Fingerprint: fingerprint})
+			if err != nil {
+				return err
+			}
+
+			acquired, err := store.SetNX(ctx, key, lock, processingTTL)
+			if err != nil {
+				return fmt.Errorf("idempotency: %w", err)
+			}
+
+			if !acquired {
+				return replay(ctx, w, store, key, fingerprint)
+			}
+
+			rec := &recorder{ResponseWriter: w}
+			if err := handler(ctx, rec, r); err != nil {
+				store.Delete(ctx, key)
+				return err
+			}
+
+			// Server errors are not stored so the client can retry them.
+			statusCode := rec.statusCode()
+			if statusCode >= http.StatusInternalServerError {
+				store.Delete(ctx, key)
+				return nil
+			}
+
+			done, err := json.Marshal(idempotencyRecord{
+				State:       idempotencyCompleted,
+				Fingerprint: fingerprint,
+				StatusCode:  statusCode,
+				Header:      rec.Header().Clone(),
+				Body:        rec.body.Bytes(),
+			})
+			if err != nil {
+				return err
+			}
+
+			// The response was already sent, failing to store it only
+			// means a retry runs the request again.
+			store.SetWithTTL(ctx, key, done, window)
+
+			return nil
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// replay answers a retry with the stored response of the first request.
+func replay(ctx context.Context, w http.ResponseWriter, store cache.Cache, key string, fingerprint string) error {
+	data, found, err := store.GetNonFatal(ctx, key)
+	if err != nil {
+		return fmt.Errorf("idempotency: %w", err)
+	}
+
+	// The first request failed and released the key in between.
+	if !found {
+		return response.NewError(web.NewError(response.CodeIdempotencyInProgress, ErrIdempotencyInProgress), http.StatusConflict)
+	}
+
+	var rec idempotencyRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return fmt.Errorf("idempotency: unmarshalling record: %w", err)
+	}
+
+	switch {
+	case rec.Fingerprint != fingerprint:
+		return response.NewError(web.NewError(response.CodeIdempotencyMismatch, ErrIdempotencyMismatch), http.StatusUnprocessableEntity)
+	case rec.State != idempotencyCompleted:
+		return response.NewError(web.NewError(response.CodeIdempotencyInProgress, ErrIdempotencyInProgress), http.StatusConflict)
+	}
+
+	for k, v := range rec.Header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+
+	web.SetStatusCode(ctx, rec.StatusCode)
+	w.WriteHeader(rec.StatusCode)
+	if _, err := w.Write(rec.Body); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// fingerprintRequest hashes the method, path and body of the request. The
+// body is put back so the handler can still decode it.
+func fingerprintRequest(r *http.Request) (string, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return "", fmt.Errorf("reading body: %w", err)
+		}
+		r.Body.Close()
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// =============================================================================
+
+// recorder keeps a copy of the status code and body written to the client.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status code before sending it.
+func (rec *recorder) WriteHeader(statusCode int) {
+	if rec.status == 0 {
+		rec.status = statusCode
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the body before sending it.
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// statusCode returns the status code sent to the client.
+func (rec *recorder) statusCode() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
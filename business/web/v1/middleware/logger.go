@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// Logger writes information about the request to the logs.
+func Logger(log *logger.Logger) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			v := web.GetValues(ctx)
+
+			path := r.URL.Path
+			if r.URL.RawQuery != "" {
+				path = fmt.Sprintf("%s?%s", path, r.URL.RawQuery)
+			}
+
+			log.Info(ctx, "request started", "method", r.Method, "path", path, "remoteaddr", r.RemoteAddr)
+
+			err := handler(ctx, w, r)
+
+			args := []any{"method", r.Method, "path", path, "remoteaddr", r.RemoteAddr,
+				"statuscode", v.StatusCode, "since", time.Since(v.Now).String()}
+
+			// The error sent to the client may hide the original one, the
+			// logs always get the full text.
+			switch {
+			case v.Err != nil && v.StatusCode >= http.StatusInternalServerError:
+				log.Error(ctx, "request completed", append(args, "error", v.Err)...)
+			case v.Err != nil:
+				log.Info(ctx, "request completed", append(args, "error", v.Err)...)
+			default:
+				log.Info(ctx, "request completed", args...)
+			}
+
+			return err
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/metrics"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// Metrics records the count and latency of every request by route, method and
+// status code. It has to run outside of Errors so the status code set while
+// responding to an error is recorded.
+func Metrics() web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			err := handler(ctx, w, r)
+
+			v := web.GetValues(ctx)
+			metrics.ObserveRequest(v.Route, r.Method, v.StatusCode, time.Since(v.Now))
+
+			return err
+		}
+
+		return h
+	}
+
+	return m
+}
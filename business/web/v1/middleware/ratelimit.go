@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/data/ratelimit"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// ErrRateLimited is returned when a client has used up its requests.
+var ErrRateLimited = errors.New("rate limit exceeded, retry later")
+
+// RateLimit limits the requests made to the routes of group. Requests are
+// counted per authenticated subject, or per client IP for anonymous ones, so
+// it has to run after the authentication middleware of the route. A disabled
+// limit lets every request through.
+func RateLimit(limiter ratelimit.Limiter, group string, limit ratelimit.Limit) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if limiter == nil || !limit.Enabled() {
+				return handler(ctx, w, r)
+			}
+
+			res, err := limiter.Allow(ctx, rateLimitKey(ctx, r, group), limit)
+			if err != nil {
+				// Failing closed would take the writes down with the
+				// limiter, the request goes through instead.
+				return handler(ctx, w, r)
+			}
+
+			header := w.Header()
+			header.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+			header.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			header.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+			if !res.Allowed {
+				header.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				return response.NewError(ErrRateLimited, http.StatusTooManyRequests)
+			}
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// rateLimitKey returns the key of the bucket used for the request.
+func rateLimitKey(ctx context.Context, r *http.Request, group string) string {
+	if subject := auth.GetClaims(ctx).Subject; subject != "" {
+		return "ratelimit:" + group + ":user:" + subject
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return "ratelimit:" + group + ":ip:" + ip
+}
+
+// ceilSeconds rounds d up to whole seconds, as used by the rate limit headers.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
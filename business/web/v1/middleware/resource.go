@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/hpetrov29/resttemplate/business/core/comment"
+	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/response"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// AuthorizePost loads the post referenced by the id route parameter and
+// validates the authenticated user against the specified resource rule. The
+// loaded post is stored in the context so the handler does not fetch it twice.
+func AuthorizePost(a *auth.Auth, pc *post.Core, rule string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			claims := auth.GetClaims(ctx)
+			if claims.Subject == "" {
+				return auth.NewAuthError("authorize: you are not authorized for that action, no claims")
+			}
+
+			id, err := strconv.ParseInt(web.Param(r, "id"), 10, 64)
+			if err != nil {
+				return response.NewError(web.NewError(response.CodeInvalidId, ErrInvalidID), http.StatusBadRequest)
+			}
+
+			p, err := pc.QueryById(ctx, id)
+			if err != nil {
+				if errors.Is(err, post.ErrNotFound) {
+					return web.Respond(ctx, w, http.StatusNotFound, post.ErrNotFound)
+				}
+				return web.Respond(ctx, w, http.StatusInternalServerError, err)
+			}
+
+			res := auth.Resource{
+				Type:    auth.ResourcePost,
+				ID:      p.Id,
+				OwnerID: p.UserId,
+				Attributes: map[string]any{
+					"contentId": strconv.FormatInt(p.ContentId, 10),
+				},
+			}
+
+			if err := a.AuthorizeResource(ctx, claims, res, rule); err != nil {
+				return auth.NewAuthError("authorize: you are not authorized for that action, claims[%v] rule[%v] post[%d]: %s", claims.Roles, rule, p.Id, err)
+			}
+
+			ctx = setPost(ctx, p)
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// AuthorizeComment loads the comment referenced by the id route parameter and
+// validates the authenticated user against the specified resource rule. The
+// loaded comment is stored in the context so the handler does not fetch it
+// twice.
+func AuthorizeComment(a *auth.Auth, cc *comment.Core, rule string) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			claims := auth.GetClaims(ctx)
+			if claims.Subject == "" {
+				return auth.NewAuthError("authorize: you are not authorized for that action, no claims")
+			}
+
+			id, err := strconv.ParseInt(web.Param(r, "id"), 10, 64)
+			if err != nil {
+				return response.NewError(web.NewError(response.CodeInvalidId, ErrInvalidID), http.StatusBadRequest)
+			}
+
+			c, err := cc.QueryById(ctx, id)
+			if err != nil {
+				if errors.Is(err, comment.ErrNotFound) {
+					return web.Respond(ctx, w, http.StatusNotFound, comment.ErrNotFound)
+				}
+				return web.Respond(ctx, w, http.StatusInternalServerError, err)
+			}
+
+			res := auth.Resource{
+				Type:    auth.ResourceComment,
+				ID:      c.Id,
+				OwnerID: c.UserId,
+				Attributes: map[string]any{
+					"postId":   strconv.FormatInt(c.PostId, 10),
+					"parentId": strconv.FormatInt(c.ParentId, 10),
+				},
+			}
+
+			if err := a.AuthorizeResource(ctx, claims, res, rule); err != nil {
+				return auth.NewAuthError("authorize: you are not authorized for that action, claims[%v] rule[%v] comment[%d]: %s", claims.Roles, rule, c.Id, err)
+			}
+
+			ctx = setComment(ctx, c)
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
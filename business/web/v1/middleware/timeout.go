@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// Timeout sets a deadline of d on the context of the request. Store calls
+// made with the context are aborted once the deadline passes and the client
+// gets a 504. Deadlines nest, so a route level Timeout can only shorten the
+// deadline set by the application wide one.
+func Timeout(d time.Duration) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if d <= 0 {
+				return handler(ctx, w, r)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			return handler(ctx, w, r)
+		}
+
+		return h
+	}
+
+	return m
+}
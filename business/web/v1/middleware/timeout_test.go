@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/business/core/post"
+	"github.com/hpetrov29/resttemplate/business/core/post/stores/postorchestrator"
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// missCache is a post cache that never holds the post asked for.
+type missCache struct {
+	post.CacheStore
+}
+
+func (missCache) QueryPostById(context.Context, int64) (post.Post, bool, error) {
+	return post.Post{}, false, nil
+}
+
+// blockingSQL stands for a database that does not answer. It records the
+// context of the query and only returns once that context is done.
+type blockingSQL struct {
+	post.SQLstore
+	ctx chan context.Context
+}
+
+func (s blockingSQL) QueryById(ctx context.Context, id int64) (post.Post, error) {
+	s.ctx <- ctx
+	<-ctx.Done()
+	return post.Post{}, ctx.Err()
+}
+
+// newApp returns an app serving GET /v1/post through a post core reading
+// from sql, the way the posts handlers are wired.
+func newApp(sql blockingSQL, mw ...web.Middleware) *web.App {
+	log := logger.NewWithEvents(io.Discard, logger.LevelError, "TEST", func(context.Context) string { return "" }, logger.Events{})
+
+	store := postorchestrator.NewStore(log, missCache{}, sql, nil, nil)
+	core := post.NewCore(store, nil, log, nil)
+
+	app := web.NewApp(make(chan os.Signal, 1), log, nil, Errors(log))
+	app.Handle(http.MethodGet, "/post", func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		p, err := core.QueryById(ctx, 1)
+		if err != nil {
+			return err
+		}
+		return web.Respond(ctx, w, http.StatusOK, p)
+	}, mw...)
+
+	return app
+}
+
+// storeCtx returns the context the store was called with, failing the test
+// when the store was never called.
+func storeCtx(t *testing.T, sql blockingSQL) context.Context {
+	t.Helper()
+
+	select {
+	case ctx := <-sql.ctx:
+		return ctx
+	case <-time.After(time.Second):
+		t.Fatal("store: never called")
+		return nil
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	sql := blockingSQL{ctx: make(chan context.Context, 1)}
+	app := newApp(sql, Timeout(20*time.Millisecond))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/post", nil))
+
+	ctx := storeCtx(t, sql)
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("store: context has no deadline")
+	}
+	if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("store: got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status: got %d, want %d: %s", w.Code, http.StatusGatewayTimeout, w.Body)
+	}
+}
+
+func TestCancelledRequest(t *testing.T) {
+	sql := blockingSQL{ctx: make(chan context.Context, 1)}
+	app := newApp(sql, Timeout(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/post", nil).WithContext(ctx))
+
+	if err := storeCtx(t, sql).Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("store: got %v, want %v", err, context.Canceled)
+	}
+	if w.Code != web.StatusClientClosedRequest {
+		t.Errorf("status: got %d, want %d: %s", w.Code, web.StatusClientClosedRequest, w.Body)
+	}
+}
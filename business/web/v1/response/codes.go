@@ -0,0 +1,29 @@
+package response
+
+// Set of error codes sent to the client for the expected errors of the API.
+// They extend the generic codes of the web package, which are used for any
+// error sent without one of these. Codes are part of the API contract: add
+// new ones rather than renaming or reusing existing ones.
+const (
+	CodeInvalidToken          = "invalid_token"
+	CodeInvalidCredentials    = "invalid_credentials"
+	CodeInvalidId             = "invalid_id"
+	CodeInvalidBody           = "invalid_body"
+	CodeAccountDisabled       = "account_disabled"
+	CodeEmailInUse            = "email_in_use"
+	CodeUnverifiedEmail       = "unverified_email"
+	CodeSelfModification      = "self_modification"
+	CodeUnknownProvider       = "unknown_identity_provider"
+	CodeLoginExpired          = "login_expired"
+	CodeLoginRefused          = "login_refused"
+	CodeRoleNameTaken         = "role_name_taken"
+	CodeBuiltInRole           = "built_in_role"
+	CodeRoleInUse             = "role_in_use"
+	CodeIdempotencyInProgress = "idempotency_in_progress"
+	CodeIdempotencyMismatch   = "idempotency_mismatch"
+	CodeUnsupportedContent    = "unsupported_content"
+	CodeFileTooLarge          = "file_too_large"
+	CodeUnsupportedMediaType  = "unsupported_media_type"
+	CodeInvalidTransition     = "invalid_status_transition"
+	CodeConcurrentEdit        = "concurrent_edit"
+)
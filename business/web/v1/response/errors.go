@@ -0,0 +1,31 @@
+package response
+
+import (
+	"github.com/hpetrov29/resttemplate/internal/validate"
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// Set of errors sent to the client with a fixed message. The cause is kept
+// in the error for the logs, it can hold the text of a driver or a parser
+// and is never sent.
+
+// Unauthenticated returns the error of a request that failed authentication.
+func Unauthenticated(err error) error {
+	return &web.Error{Code: CodeInvalidToken, Message: "invalid credentials", Err: err}
+}
+
+// InvalidId returns the error of a path parameter that is not an id.
+func InvalidId(param string, err error) error {
+	return &web.Error{Code: CodeInvalidId, Message: param + " must be a valid id", Err: err}
+}
+
+// InvalidBody returns the error of a request body that could not be decoded.
+// Validation errors of the decoded body are returned as they are, their
+// fields are sent to the client.
+func InvalidBody(err error) error {
+	if validate.IsFieldErrors(err) {
+		return err
+	}
+
+	return &web.Error{Code: CodeInvalidBody, Message: "request body is not a valid JSON document", Err: err}
+}
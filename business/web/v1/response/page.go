@@ -0,0 +1,20 @@
+package response
+
+// PageDocument is the form used for API responses of paginated listings,
+// the total lets clients tell how many pages there are.
+type PageDocument[T any] struct {
+	Items       []T `json:"items"`
+	Total       int `json:"total"`
+	Page        int `json:"page"`
+	RowsPerPage int `json:"rowsPerPage"`
+}
+
+// NewPageDocument constructs a response value for a page of items.
+func NewPageDocument[T any](items []T, total int, page int, rowsPerPage int) PageDocument[T] {
+	return PageDocument[T]{
+		Items:       items,
+		Total:       total,
+		Page:        page,
+		RowsPerPage: rowsPerPage,
+	}
+}
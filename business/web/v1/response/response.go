@@ -1,5 +1,7 @@
 package response
 
+import "errors"
+
 // Error is used to pass an error during the request through the
 // application with web specific context.
 type Error struct {
@@ -17,4 +19,19 @@ func (re *Error) Error() string {
 // function should be used when handlers encounter expected errors.
 func NewError(err error, status int) error {
 	return &Error{err, status}
-}
\ No newline at end of file
+}
+
+// IsError checks if an error of type Error exists.
+func IsError(err error) bool {
+	var re *Error
+	return errors.As(err, &re)
+}
+
+// GetError returns a copy of the Error pointer.
+func GetError(err error) *Error {
+	var re *Error
+	if !errors.As(err, &re) {
+		return nil
+	}
+	return re
+}
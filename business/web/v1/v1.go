@@ -1,17 +1,25 @@
 package v1
 
 import (
+	"context"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/hpetrov29/resttemplate/business/data/cache"
 	"github.com/hpetrov29/resttemplate/business/data/dbnosql"
 	"github.com/hpetrov29/resttemplate/business/data/messaging"
+	"github.com/hpetrov29/resttemplate/business/data/ratelimit"
+	"github.com/hpetrov29/resttemplate/business/data/storage"
 	"github.com/hpetrov29/resttemplate/business/web/v1/auth"
+	"github.com/hpetrov29/resttemplate/business/web/v1/middleware"
 	"github.com/hpetrov29/resttemplate/internal/idgenerator"
 	"github.com/hpetrov29/resttemplate/internal/logger"
+	"github.com/hpetrov29/resttemplate/internal/oidc"
+	"github.com/hpetrov29/resttemplate/internal/openapi"
 	"github.com/hpetrov29/resttemplate/internal/web"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // APIMuxConfig contains all mandatory systems required by handlers.
@@ -25,6 +33,35 @@ type APIMuxConfig struct {
 	NOSQLDB 	dbnosql.NOSQLDB
 	Messaging 	messaging.MessagingQueue
 	IdGen 	 	*idgenerator.IdGenerator
+	OIDC 		[]*oidc.Provider
+	Tracer 		trace.Tracer
+
+	// RequestTimeout bounds the time spent handling any request. Routes
+	// can set a shorter deadline with middleware.Timeout.
+	RequestTimeout time.Duration
+
+	// RateLimiter and RateLimits throttle the write routes of each group.
+	RateLimiter ratelimit.Limiter
+	RateLimits  RateLimits
+
+	// IdempotencyWindow is how long responses to requests carrying an
+	// Idempotency-Key are kept for replay.
+	IdempotencyWindow time.Duration
+
+	// Storage keeps the uploaded media, served under MediaBaseURL. Uploads
+	// larger than MediaMaxSize bytes are refused.
+	Storage      storage.BlobStore
+	MediaBaseURL string
+	MediaMaxSize int64
+}
+
+// RateLimits holds the limits of the route groups. A zero Limit disables
+// limiting for its group.
+type RateLimits struct {
+	Posts    ratelimit.Limit
+	Comments ratelimit.Limit
+	Likes    ratelimit.Limit
+	Media    ratelimit.Limit
 }
 
 // RouteAdder defines behavior that sets the routes to bind for an instance
@@ -34,9 +71,35 @@ type RouteAdder interface {
 }
 
 func NewAPIMux(config APIMuxConfig, routeAdder RouteAdder) http.Handler {
-	app := web.NewApp(config.Shutdown,config.Log ,nil)
+	app := web.NewApp(
+		config.Shutdown,
+		config.Log,
+		config.Tracer,
+		middleware.Logger(config.Log),
+		middleware.Metrics(),
+		middleware.Errors(config.Log),
+		middleware.Panics(),
+		middleware.Timeout(config.RequestTimeout),
+	)
 	
 	// constructs the handlers and binds them to the API endpoints
 	routeAdder.Add(app, config)
+
+	info := openapi.Info{
+		Title:       "resttemplate API",
+		Description: "Posts, comments and likes with user and role management.",
+		Version:     config.Build,
+	}
+	app.Handle(http.MethodGet, "/openapi.json", openapi.Handler(app, info)).Describe(web.Doc{
+		Summary: "OpenAPI document of the API",
+		Tags:    []string{"meta"},
+	})
+
+	// Every route is expected to be documented, a missing Doc leaves the
+	// route out of the contract the clients are generated from.
+	for _, r := range openapi.Undocumented(app.Routes()) {
+		config.Log.Info(context.Background(), "openapi", "status", "undocumented route", "method", r.Method, "path", r.Path)
+	}
+
 	return app
 }
\ No newline at end of file
@@ -4,22 +4,89 @@ CREATE TABLE users (
     email VARCHAR(255) NOT NULL UNIQUE,
     roles JSON NOT NULL,
     password_hash VARCHAR(60) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE roles (
+    name VARCHAR(50) NOT NULL PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT "",
+    permissions JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+INSERT INTO roles (name, description, permissions) VALUES
+    ("ADMIN", "Full access to the system", JSON_ARRAY(
+        "post:create", "post:edit:any", "post:delete:any",
+        "comment:create", "comment:delete:any", "comment:moderate",
+        "like:create", "user:read:any", "user:manage", "role:manage")),
+    ("USER", "Regular author and reader", JSON_ARRAY(
+        "post:create", "comment:create", "like:create")),
+    ("EDITOR", "Curates content written by others", JSON_ARRAY(
+        "post:create", "post:edit:any", "post:delete:any",
+        "comment:create", "like:create")),
+    ("MODERATOR", "Keeps the discussions civil", JSON_ARRAY(
+        "post:create", "comment:create", "comment:delete:any", "comment:moderate",
+        "like:create", "user:read:any"));
+
+CREATE TABLE user_identities (
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id BIGINT NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT "",
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, subject),
+    INDEX (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE api_keys (
+    id BIGINT NOT NULL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes JSON NOT NULL,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    last_used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
 CREATE TABLE posts (
     id BIGINT NOT NULL PRIMARY KEY,
     user_id BIGINT NOT NULL,
     title VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NULL DEFAULT NULL,
     description VARCHAR(300) NOT NULL,
     front_image VARCHAR(512) NOT NULL DEFAULT "",
     content_id BIGINT NOT NULL,
+    word_count INT UNSIGNED NOT NULL DEFAULT 0,
+    reading_time SMALLINT UNSIGNED NOT NULL DEFAULT 0,
+    image_count SMALLINT UNSIGNED NOT NULL DEFAULT 0,
+    language VARCHAR(8) NOT NULL DEFAULT "",
+    status VARCHAR(20) NOT NULL DEFAULT "published",
+    publish_at TIMESTAMP NULL DEFAULT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX (user_id),
+    UNIQUE INDEX (slug),
+    INDEX (status, publish_at),
+    INDEX (reading_time),
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
+-- Every slug a post ever had. Slugs are reserved before the post is stored,
+-- there is no foreign key to posts.
+CREATE TABLE post_slugs (
+    slug VARCHAR(100) NOT NULL PRIMARY KEY,
+    post_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX (post_id)
+);
+
 CREATE TABLE comments (
     id         BIGINT PRIMARY KEY,
     user_id    BIGINT NOT NULL,
@@ -0,0 +1,116 @@
+// Package metrics provides the Prometheus collectors of the service and the
+// helpers the rest of the code uses to record into them.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the name of every metric of the service.
+const namespace = "resttemplate"
+
+// registry holds the collectors of the service next to the go runtime and
+// process collectors. A dedicated registry keeps metrics registered by
+// dependencies out of the output.
+var registry = prometheus.NewRegistry()
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of handled requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of handled requests by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	panics = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_panics_total",
+		Help:      "Number of panics recovered while handling requests.",
+	})
+
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Number of cache lookups by store and result (hit or miss).",
+	}, []string{"store", "result"})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_call_duration_seconds",
+		Help:      "Latency of database calls by database and operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"db", "operation"})
+
+	publishFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messaging_publish_failures_total",
+		Help:      "Number of messages that failed to be published by subject.",
+	}, []string{"subject"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests,
+		requestDuration,
+		panics,
+		cacheLookups,
+		dbDuration,
+		publishFailures,
+	)
+}
+
+// Handler returns the handler serving the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a handled request. Route must be the route pattern,
+// not the request path, to keep the number of series bounded.
+func ObserveRequest(route string, method string, status int, took time.Duration) {
+	s := strconv.Itoa(status)
+	requests.WithLabelValues(route, method, s).Inc()
+	requestDuration.WithLabelValues(route, method, s).Observe(took.Seconds())
+}
+
+// AddPanic records a recovered panic.
+func AddPanic() {
+	panics.Inc()
+}
+
+// CacheHit records a lookup in the specified cache store that found the value.
+func CacheHit(store string) {
+	cacheLookups.WithLabelValues(store, "hit").Inc()
+}
+
+// CacheMiss records a lookup in the specified cache store that did not find
+// the value.
+func CacheMiss(store string) {
+	cacheLookups.WithLabelValues(store, "miss").Inc()
+}
+
+// ObserveDBCall records the duration of a database call that started at the
+// specified time. It is meant to be deferred:
+//
+//	defer metrics.ObserveDBCall("sql", "exec", time.Now())
+func ObserveDBCall(db string, operation string, start time.Time) {
+	dbDuration.WithLabelValues(db, operation).Observe(time.Since(start).Seconds())
+}
+
+// AddPublishFailure records a message that could not be published.
+func AddPublishFailure(subject string) {
+	publishFailures.WithLabelValues(subject).Inc()
+}
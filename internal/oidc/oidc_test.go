@@ -0,0 +1,226 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hpetrov29/resttemplate/internal/oidc"
+	"github.com/hpetrov29/resttemplate/internal/oidc/oidctest"
+)
+
+const clientID = "article-recommender"
+
+// login runs the authorization code flow up to the token response and
+// returns the raw ID token along with the nonce the login was started with.
+func login(t *testing.T, idp *oidctest.Server, p *oidc.Provider) (string, string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		t.Fatalf("state: %s", err)
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		t.Fatalf("nonce: %s", err)
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatalf("pkce: %s", err)
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		t.Fatalf("auth code url: %s", err)
+	}
+
+	code, gotState, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %s", err)
+	}
+	if gotState != state {
+		t.Fatalf("state: got %q, want %q", gotState, state)
+	}
+
+	tkn, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("exchange: %s", err)
+	}
+
+	return tkn.IDToken, nonce
+}
+
+func newProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+
+	idp, err := oidctest.NewServer(clientID)
+	if err != nil {
+		t.Fatalf("stub provider: %s", err)
+	}
+	t.Cleanup(idp.Close)
+
+	p, err := oidc.NewProvider(oidc.Config{
+		Name:        "stub",
+		Issuer:      idp.URL,
+		ClientID:    clientID,
+		RedirectURL: "http://localhost/v1/users/oidc/stub/callback",
+	}, idp.Client())
+	if err != nil {
+		t.Fatalf("provider: %s", err)
+	}
+
+	return idp, p
+}
+
+func TestNewPKCE(t *testing.T) {
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatalf("pkce: %s", err)
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	if want := base64.RawURLEncoding.EncodeToString(sum[:]); challenge != want {
+		t.Fatalf("challenge: got %q, want %q", challenge, want)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	_, p := newProvider(t)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "the-state", "the-nonce", "the-challenge")
+	if err != nil {
+		t.Fatalf("auth code url: %s", err)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse: %s", err)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             clientID,
+		"state":                 "the-state",
+		"nonce":                 "the-nonce",
+		"code_challenge":        "the-challenge",
+		"code_challenge_method": "S256",
+		"scope":                 "openid email profile",
+	}
+	for name, v := range want {
+		if got := u.Query().Get(name); got != v {
+			t.Errorf("%s: got %q, want %q", name, got, v)
+		}
+	}
+}
+
+func TestLogin(t *testing.T) {
+	idp, p := newProvider(t)
+
+	raw, nonce := login(t, idp, p)
+
+	claims, err := p.VerifyIDToken(context.Background(), raw, nonce)
+	if err != nil {
+		t.Fatalf("verify: %s", err)
+	}
+
+	if claims.Subject != idp.User.Subject {
+		t.Errorf("subject: got %q, want %q", claims.Subject, idp.User.Subject)
+	}
+	if claims.Email != idp.User.Email || !claims.EmailVerified {
+		t.Errorf("email: got %q verified %t, want %q verified", claims.Email, claims.EmailVerified, idp.User.Email)
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	idp, p := newProvider(t)
+	ctx := context.Background()
+
+	_, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatalf("pkce: %s", err)
+	}
+	otherVerifier, _, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatalf("pkce: %s", err)
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, "state", "nonce", challenge)
+	if err != nil {
+		t.Fatalf("auth code url: %s", err)
+	}
+
+	code, _, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %s", err)
+	}
+
+	if _, err := p.Exchange(ctx, code, otherVerifier); err == nil {
+		t.Fatal("exchange: expected the provider to refuse another verifier")
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("key: %s", err)
+	}
+
+	tests := []struct {
+		name  string
+		setup func(idp *oidctest.Server)
+		nonce func(nonce string) string
+	}{
+		{
+			name:  "signature",
+			setup: func(idp *oidctest.Server) { idp.SigningKey = otherKey },
+		},
+		{
+			name: "audience",
+			setup: func(idp *oidctest.Server) {
+				idp.Claims = func(c jwt.MapClaims) { c["aud"] = "another-client" }
+			},
+		},
+		{
+			name: "issuer",
+			setup: func(idp *oidctest.Server) {
+				idp.Claims = func(c jwt.MapClaims) { c["iss"] = "https://impostor.example.com" }
+			},
+		},
+		{
+			name: "expired",
+			setup: func(idp *oidctest.Server) {
+				idp.Claims = func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }
+			},
+		},
+		{
+			name:  "nonce",
+			nonce: func(string) string { return "another-nonce" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp, p := newProvider(t)
+			if tt.setup != nil {
+				tt.setup(idp)
+			}
+
+			raw, nonce := login(t, idp, p)
+			if tt.nonce != nil {
+				nonce = tt.nonce(nonce)
+			}
+
+			if _, err := p.VerifyIDToken(context.Background(), raw, nonce); err == nil {
+				t.Fatal("verify: expected the token to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Package oidctest provides a stub OpenID Connect identity provider for
+// tests. It serves the discovery document, the signing keys and the token
+// endpoint, and checks the PKCE code verifier of every code it exchanges.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyID is the id of the key the ID tokens are signed with.
+const KeyID = "stub-key"
+
+// User is the account that logs in at the provider.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Server is a stub identity provider listening on a local address.
+type Server struct {
+	*httptest.Server
+
+	// ClientID is the only client the provider knows.
+	ClientID string
+
+	// User is the account returned for the next authorizations.
+	User User
+
+	// Claims alters the claims of the ID tokens before they are signed.
+	Claims func(jwt.MapClaims)
+
+	// SigningKey signs the ID tokens in place of the published key when set.
+	SigningKey *rsa.PrivateKey
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+// authorization is what the provider remembers between the login of the
+// user and the exchange of the code.
+type authorization struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+	user        User
+}
+
+// NewServer starts a stub provider for the client. Close must be called
+// when the test is done with it.
+func NewServer(clientID string) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+
+	s := Server{
+		ClientID: clientID,
+		User: User{
+			Subject:       "stub-subject",
+			Email:         "jane@example.com",
+			EmailVerified: true,
+			Name:          "Jane Doe",
+		},
+		key:   key,
+		codes: make(map[string]authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/keys", s.keys)
+	mux.HandleFunc("/token", s.token)
+	s.Server = httptest.NewServer(mux)
+
+	return &s, nil
+}
+
+// Authorize plays the part of the user logging in at the provider: it
+// accepts the login page URL the service redirected to and returns the code
+// and state the provider redirects back with.
+func (s *Server) Authorize(authURL string) (code string, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	q := u.Query()
+	switch {
+	case q.Get("response_type") != "code":
+		return "", "", errors.New("response_type must be code")
+	case q.Get("client_id") != s.ClientID:
+		return "", "", errors.New("unknown client")
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		return "", "", errors.New("an S256 code challenge is required")
+	case q.Get("state") == "" || q.Get("nonce") == "":
+		return "", "", errors.New("state and nonce are required")
+	}
+
+	code = randomText()
+
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		user:        s.User,
+	}
+	s.mu.Unlock()
+
+	return code, q.Get("state"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/keys",
+	})
+}
+
+func (s *Server) keys(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kid": KeyID,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+
+	// Codes are single use.
+	s.mu.Lock()
+	authz, exists := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+	switch {
+	case r.PostForm.Get("grant_type") != "authorization_code", !exists,
+		r.PostForm.Get("client_id") != s.ClientID,
+		r.PostForm.Get("redirect_uri") != authz.redirectURI,
+		base64.RawURLEncoding.EncodeToString(sum[:]) != authz.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken, err := s.idToken(authz)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomText(),
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+		"expires_in":   3600,
+	})
+}
+
+// idToken returns the signed ID token of an authorization.
+func (s *Server) idToken(authz authorization) (string, error) {
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            authz.user.Subject,
+		"aud":            s.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          authz.nonce,
+		"email":          authz.user.Email,
+		"email_verified": authz.user.EmailVerified,
+		"name":           authz.user.Name,
+	}
+	if s.Claims != nil {
+		s.Claims(claims)
+	}
+
+	key := s.key
+	if s.SigningKey != nil {
+		key = s.SigningKey
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID
+
+	return token.SignedString(key)
+}
+
+// randomText returns a random value for codes and access tokens.
+func randomText() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,217 @@
+// Package openapi generates an OpenAPI 3 document from the routes registered
+// on a web.App and the app layer models attached to them.
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hpetrov29/resttemplate/internal/web"
+)
+
+// version is the version of the OpenAPI specification produced.
+const version = "3.0.3"
+
+// pathParam matches the parameters of a chi route pattern, with an optional
+// regular expression after the name.
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Generate builds the OpenAPI document of the documented routes. Routes
+// without a Doc are left out, Undocumented lists them.
+//
+// Parameters:
+//   - info: the metadata of the API.
+//   - routes: the routes registered on the App.
+//
+// Returns:
+//   - Document: the generated document.
+func Generate(info Info, routes []web.Route) Document {
+	sch := newSchemas()
+
+	errorContent := errorContent(sch.of(web.ErrorResponse{}), sch.of(web.Problem{}))
+	messageSchema := sch.of(web.MessageResponse{})
+
+	doc := Document{
+		OpenAPI: version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				web.SecurityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				web.SecurityBasic:  {Type: "http", Scheme: "basic"},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		if route.Doc == nil {
+			continue
+		}
+		d := route.Doc
+
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+
+		op := &Operation{
+			Summary:     d.Summary,
+			Tags:        d.Tags,
+			OperationID: operationID(route.Method, path),
+			Responses:   make(map[string]Response),
+		}
+
+		for _, m := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     m[1],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+		for _, q := range d.Query {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:   q,
+				In:     "query",
+				Schema: &Schema{Type: "string"},
+			})
+		}
+
+		if d.Security != "" {
+			op.Security = []map[string][]string{{d.Security: {}}}
+		}
+
+		if d.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(sch.of(d.Request)),
+			}
+			op.Responses["400"] = Response{Description: "Validation failed", Content: errorContent}
+		}
+		if d.Upload != "" {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{"multipart/form-data": {Schema: &Schema{
+					Type:       "object",
+					Properties: map[string]*Schema{d.Upload: {Type: "string", Format: "binary"}},
+					Required:   []string{d.Upload},
+				}}},
+			}
+			op.Responses["400"] = Response{Description: "Validation failed", Content: errorContent}
+		}
+
+		status := d.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		success := Response{Description: http.StatusText(status)}
+		switch resp := d.Response.(type) {
+		case nil:
+		case string:
+			success.Content = jsonContent(messageSchema)
+		default:
+			// web.Respond wraps payloads in an envelope.
+			success.Content = jsonContent(&Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"payload": sch.of(resp)},
+				Required:   []string{"payload"},
+			})
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		op.Responses["default"] = Response{Description: "Error", Content: errorContent}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		item.set(route.Method, op)
+	}
+
+	doc.Components.Schemas = sch.components
+
+	return doc
+}
+
+// Undocumented returns the routes registered without a Doc.
+func Undocumented(routes []web.Route) []web.Route {
+	var missing []web.Route
+	for _, r := range routes {
+		if r.Doc == nil {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// Handler returns a handler serving the document of the routes registered on
+// app. The document is generated on the first request, once every route is
+// registered.
+func Handler(app *web.App, info Info) web.Handler {
+	var (
+		once sync.Once
+		doc  Document
+	)
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		once.Do(func() {
+			doc = Generate(info, app.Routes())
+		})
+
+		return web.RespondJSON(ctx, w, http.StatusOK, doc)
+	}
+
+	return h
+}
+
+// =============================================================================
+
+// set adds the operation to the item under method.
+func (item *PathItem) set(method string, op *Operation) {
+	switch method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodPatch:
+		item.Patch = op
+	}
+}
+
+// jsonContent returns the content map of a JSON body with schema.
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// errorContent describes the content of error responses, sent as problem
+// details when the client asks for them.
+func errorContent(envelope *Schema, problem *Schema) map[string]MediaType {
+	return map[string]MediaType{
+		"application/json":     {Schema: envelope},
+		web.ProblemContentType: {Schema: problem},
+	}
+}
+
+// operationID derives a unique operation id from the method and path, e.g.
+// GET /v1/post/{id} becomes get_v1_post_id.
+func operationID(method string, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+
+	for _, part := range strings.Split(path, "/") {
+		part = strings.Trim(part, "{}")
+		if part == "" {
+			continue
+		}
+		b.WriteByte('_')
+		b.WriteString(strings.ReplaceAll(part, "-", "_"))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,248 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemas builds the component schemas of the named struct types found
+// while walking the models.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// of returns the schema of the type of v. Named structs are added to the
+// components and referenced.
+func (s *schemas) of(v any) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() != reflect.Pointer && t.Implements(marshalerType):
+		// The encoding is up to the type, nothing can be said about it.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		sch := s.schema(t.Elem())
+		if sch.Ref == "" {
+			sch.Nullable = true
+		}
+		return sch
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.component(t)}
+	}
+
+	return &Schema{}
+}
+
+// component registers the schema of the named struct t and returns its name.
+// Types sharing a name across packages are told apart by the package name.
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	name := typeName(t)
+	if _, taken := s.components[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+
+	// Registered before walking the fields so recursive types terminate.
+	s.names[t] = name
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+
+	return name
+}
+
+// typeName returns the name of t usable as a component name. The type
+// arguments of generic types are appended to the name without their
+// package, PageDocument[users.AppUser] is PageDocumentAppUser.
+func typeName(t reflect.Type) string {
+	name, args, generic := strings.Cut(t.Name(), "[")
+	if !generic {
+		return name
+	}
+
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		name += arg[strings.LastIndexAny(arg, "./*")+1:]
+	}
+
+	return name
+}
+
+// object returns the schema of the fields of the struct t.
+func (s *schemas) object(t reflect.Type) *Schema {
+	obj := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.fields(t, obj)
+	return obj
+}
+
+// fields adds the exported fields of t to obj following the encoding/json
+// rules, embedded structs without a json name are flattened.
+func (s *schemas) fields(t reflect.Type, obj *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, obj)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		sch := s.schema(f.Type)
+		required := applyValidate(sch, f.Tag.Get("validate"))
+		if required && !strings.Contains(opts, "omitempty") {
+			obj.Required = append(obj.Required, name)
+		}
+
+		obj.Properties[name] = sch
+	}
+}
+
+// applyValidate translates the validate tag of a field into constraints of
+// its schema and reports whether the field is required.
+func applyValidate(sch *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	var required bool
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "dive":
+			// The remaining rules apply to the elements.
+			return required
+
+		case "required":
+			required = true
+
+		case "email":
+			sch.Format = "email"
+
+		case "url", "uri":
+			sch.Format = "uri"
+
+		case "uuid", "uuid4":
+			sch.Format = "uuid"
+
+		case "oneof":
+			sch.Enum = strings.Fields(param)
+
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			bound(sch, name, n)
+		}
+	}
+
+	return required
+}
+
+// bound sets the min, max or len constraint n on the schema matching its
+// type: a length for strings, a number of items for arrays and a value for
+// numbers.
+func bound(sch *Schema, rule string, n int) {
+	f := float64(n)
+
+	switch sch.Type {
+	case "string":
+		if rule != "max" {
+			sch.MinLength = &n
+		}
+		if rule != "min" {
+			sch.MaxLength = &n
+		}
+	case "array":
+		if rule != "max" {
+			sch.MinItems = &n
+		}
+		if rule != "min" {
+			sch.MaxItems = &n
+		}
+	case "integer", "number":
+		if rule != "max" {
+			sch.Minimum = &f
+		}
+		if rule != "min" {
+			sch.Maximum = &f
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package tracing configures the OpenTelemetry tracer provider of the service
+// and the propagation of W3C trace context.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hpetrov29/resttemplate/internal/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Set of supported exporters.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config represents the settings of the tracer provider.
+type Config struct {
+	ServiceName string
+	Build       string
+	Exporter    string
+	Host        string
+	Probability float64
+}
+
+// Init sets W3C trace context as the global propagator and constructs the
+// tracer provider for the configured exporter. The returned function flushes
+// and stops the provider and has to be called on shutdown.
+//
+// Parameters:
+//   - ctx: the context used to construct the exporter.
+//   - log: the logger of the service.
+//   - cfg: the tracing configuration.
+//
+// Returns:
+//   - trace.TracerProvider: the provider used to construct tracers.
+//   - func(ctx context.Context): the teardown function of the provider.
+//   - error: an error if the exporter could not be constructed.
+func Init(ctx context.Context, log *logger.Logger, cfg Config) (trace.TracerProvider, func(ctx context.Context), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		log.Info(ctx, "tracing", "status", "tracing disabled")
+
+		tp := noop.NewTracerProvider()
+		otel.SetTracerProvider(tp)
+		return tp, func(context.Context) {}, nil
+
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+
+	case ExporterOTLP:
+		exporter, err = otlptracegrpc.New(ctx,
+			otlptracegrpc.WithInsecure(),
+			otlptracegrpc.WithEndpoint(cfg.Host),
+		)
+
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating %s exporter: %w", cfg.Exporter, err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Probability))),
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithMaxExportBatchSize(sdktrace.DefaultMaxExportBatchSize),
+			sdktrace.WithBatchTimeout(sdktrace.DefaultScheduleDelay*time.Millisecond),
+		),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(cfg.Build),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+
+	log.Info(ctx, "tracing", "status", "tracing enabled", "exporter", cfg.Exporter, "host", cfg.Host, "probability", cfg.Probability)
+
+	teardown := func(ctx context.Context) {
+		if err := tp.Shutdown(ctx); err != nil {
+			log.Error(ctx, "tracing", "status", "shutting down tracer provider", "msg", err)
+		}
+	}
+
+	return tp, teardown, nil
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Set of Cache-Control policies shared by the routes.
+const (
+	// CacheRevalidate lets clients and shared caches store the response but
+	// requires them to revalidate it on every use, which is cheap with
+	// ETags.
+	CacheRevalidate = "public, no-cache"
+
+	// CachePrivate keeps the response out of shared caches.
+	CachePrivate = "private, no-cache"
+
+	// CacheNoStore forbids storing the response anywhere.
+	CacheNoStore = "no-store"
+
+	// CacheImmutable lets any cache reuse the response for a year without
+	// revalidation, for content addressed resources that never change.
+	CacheImmutable = "public, max-age=31536000, immutable"
+)
+
+// CachePublicFor returns a policy letting any cache reuse the response
+// without revalidation for maxAge.
+func CachePublicFor(maxAge time.Duration) string {
+	return fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+}
+
+// ETag returns a strong entity tag derived from parts, e.g. the id and the
+// update time of a resource.
+func ETag(parts ...any) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%v\x00", p)
+	}
+
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// ContentETag returns a strong entity tag derived from the JSON encoding of
+// data, the value sent to the client.
+func ContentETag(data any) (string, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("encoding etag content: %w", err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// NotModified sets the ETag and Last-Modified headers of the response and
+// reports whether the conditional headers of the request match them, in
+// which case the handler should respond with 304. Empty validators are not
+// sent. If-None-Match takes precedence over If-Modified-Since as required by
+// RFC 9110.
+//
+// Parameters:
+//   - w: the response writer the validators are written to.
+//   - r: the request holding the conditional headers.
+//   - etag: the entity tag of the representation.
+//   - lastModified: the last modification time of the representation.
+//
+// Returns:
+//   - bool: true if the client already holds the current representation.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatches(inm, etag)
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+
+		// Last-Modified only carries whole seconds.
+		return !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// SetCacheControl sets the Cache-Control header of the response.
+func SetCacheControl(w http.ResponseWriter, policy string) {
+	w.Header().Set("Cache-Control", policy)
+}
+
+// etagMatches reports whether the If-None-Match header lists etag, using
+// the weak comparison required for If-None-Match.
+func etagMatches(header string, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
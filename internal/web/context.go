@@ -3,6 +3,8 @@ package web
 import (
 	"context"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ctxKey int
@@ -14,6 +16,23 @@ type Values struct {
 	TraceId string
 	Now     time.Time
 	StatusCode int
+	Route   string
+	Tracer  trace.Tracer
+	Path    string
+	Problem bool
+	Err     error
+}
+
+// GetValues returns the values from the context.
+func GetValues(ctx context.Context) *Values {
+	v, ok := ctx.Value(key).(*Values)
+	if !ok {
+		return &Values{
+			TraceId: "00000000-0000-0000-0000-000000000000",
+			Now:     time.Now(),
+		}
+	}
+	return v
 }
 
 // GetTraceID returns the trace id from the context.
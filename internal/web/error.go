@@ -0,0 +1,98 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Set of generic error codes sent to the client. They are stable, clients can
+// rely on them where the human readable message may change. Errors without a
+// code of their own get the code matching the status of the response.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidation       = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeUnprocessable    = "unprocessable_entity"
+	CodeRateLimited      = "rate_limited"
+	CodeRequestCancelled = "request_cancelled"
+	CodeInternal         = "internal_error"
+	CodeUpstream         = "upstream_unavailable"
+	CodeUnavailable      = "service_unavailable"
+	CodeTimeout          = "timeout"
+)
+
+// Error is an error sent to the client with a stable, machine readable code.
+// Message replaces the text of Err in the response when set, it is the only
+// text shown to the client for server errors. Details holds any additional
+// data that helps the client handle the error.
+type Error struct {
+	Code    string
+	Message string
+	Details any
+	Err     error
+}
+
+// NewError wraps err with the given code.
+func NewError(code string, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+// Error implements the error interface. It uses the message of the wrapped
+// error, this is what will be shown in the services' logs.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// GetError returns the Error found in the chain of err, or nil.
+func GetError(err error) *Error {
+	var e *Error
+	if !errors.As(err, &e) {
+		return nil
+	}
+	return e
+}
+
+// codeForStatus returns the code used for errors sent with the given status that
+// do not carry a code of their own.
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case StatusClientClosedRequest:
+		return CodeRequestCancelled
+	case http.StatusBadGateway:
+		return CodeUpstream
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
@@ -3,56 +3,218 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/hpetrov29/resttemplate/internal/validate"
 )
 
+// StatusClientClosedRequest is the non standard status recorded when the
+// client went away before the response was written.
+const StatusClientClosedRequest = 499
+
+// ProblemContentType is the media type of RFC 7807 problem details. Clients
+// asking for it in the Accept header get errors in that format.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code of an error to build the type of its
+// problem details document.
+const problemTypePrefix = "urn:problem-type:"
+
+// ErrorBody describes an error sent to the client. Code is one of the stable
+// codes of the catalogue, Message is meant for humans and Fields lists the
+// fields of the request that failed validation.
+type ErrorBody struct {
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
+	Details any                  `json:"details,omitempty"`
+	TraceId string               `json:"traceId"`
+	Fields  validate.FieldErrors `json:"fields,omitempty"`
+}
+
+// ErrorResponse is the envelope of every error sent to the client.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error ErrorBody `json:"error"`
 }
 
-type MessageResponse struct {
-	Message string `json:"message"`
+// Problem is an error sent as an RFC 7807 problem details document, extended
+// with the members of ErrorBody.
+type Problem struct {
+	Type     string               `json:"type"`
+	Title    string               `json:"title"`
+	Status   int                  `json:"status"`
+	Detail   string               `json:"detail"`
+	Instance string               `json:"instance,omitempty"`
+	Code     string               `json:"code"`
+	TraceId  string               `json:"traceId"`
+	Details  any                  `json:"details,omitempty"`
+	Fields   validate.FieldErrors `json:"fields,omitempty"`
 }
 
-type ValidationErrorResponse struct {
-	Errors validate.FieldErrors `json:"errors"`
+type MessageResponse struct {
+	Message string `json:"message"`
 }
 
 type PayloadResponse struct {
-	Paylaod any `json:"payload"`
+	Payload any `json:"payload"`
 }
 
-// Respond converts a Go value to JSON and sends it to the client.
+// Respond converts a Go value to JSON and sends it to the client. Errors are
+// sent inside an ErrorResponse, or as a Problem when the client asked for one.
 func Respond(ctx context.Context, w http.ResponseWriter, statusCode int, data any, args ...any) error {
+	if statusCode >= http.StatusInternalServerError {
+		if err, ok := data.(error); ok {
+			statusCode, data = cancellationError(ctx, statusCode, err)
+		}
+	}
 
 	SetStatusCode(ctx, statusCode)
 
-	if statusCode == http.StatusNoContent {
+	// Neither response carries a body, 304 only repeats the validators
+	// already set on the header by NotModified.
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
 		w.WriteHeader(statusCode)
 		return nil
 	}
 
-	var jsonData []byte
-	var err error
-
 	switch e := data.(type) {
-	case validate.FieldErrors:
-		jsonData, err = json.Marshal(ValidationErrorResponse{Errors: e})
 	case error:
-		jsonData, err = json.Marshal(ErrorResponse{Error: e.Error()})
+		return respondError(ctx, w, statusCode, e)
 	case string:
-		jsonData, err = json.Marshal(MessageResponse{Message: e})
+		return write(w, statusCode, "application/json", MessageResponse{Message: e})
 	default:
-		jsonData, err = json.Marshal(PayloadResponse{Paylaod: e})
+		return write(w, statusCode, "application/json", PayloadResponse{Payload: e})
+	}
+}
+
+// respondError sends err to the client and records it in the request values
+// so it gets logged along with the request.
+func respondError(ctx context.Context, w http.ResponseWriter, statusCode int, err error) error {
+	v := GetValues(ctx)
+	v.Err = err
+
+	body := NewErrorBody(ctx, statusCode, err)
+
+	if !v.Problem {
+		return write(w, statusCode, "application/json", ErrorResponse{Error: body})
+	}
+
+	problem := Problem{
+		Type:     problemTypePrefix + body.Code,
+		Title:    statusText(statusCode),
+		Status:   statusCode,
+		Detail:   body.Message,
+		Instance: v.Path,
+		Code:     body.Code,
+		TraceId:  body.TraceId,
+		Details:  body.Details,
+		Fields:   body.Fields,
+	}
+
+	return write(w, statusCode, ProblemContentType, problem)
+}
+
+// NewErrorBody builds the description of err sent to the client. The text of
+// errors sent with a server error status is never shown, it may contain
+// internal details, the client gets the status text or the Message set on an
+// Error instead.
+func NewErrorBody(ctx context.Context, statusCode int, err error) ErrorBody {
+	body := ErrorBody{
+		Code:    codeForStatus(statusCode),
+		Message: err.Error(),
+		TraceId: GetTraceID(ctx),
+	}
+
+	if fields := validate.GetFieldErrors(err); fields != nil {
+		body.Code = CodeValidation
+		body.Message = "one or more fields are invalid"
+		body.Fields = fields
+	}
+
+	if statusCode >= http.StatusInternalServerError {
+		body.Message = statusText(statusCode)
+	}
+
+	if e := GetError(err); e != nil {
+		if e.Code != "" {
+			body.Code = e.Code
+		}
+		if e.Message != "" {
+			body.Message = e.Message
+		}
+		body.Details = e.Details
+	}
+
+	return body
+}
+
+// write encodes data as JSON and sends it with the given content type.
+func write(w http.ResponseWriter, statusCode int, contentType string, data any) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":{"code":"internal_error","message":"failed to encode response"}}`))
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(jsonData); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// statusText returns the text of a status code, including the non standard
+// ones used by the service.
+func statusText(statusCode int) string {
+	if statusCode == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(statusCode)
+}
+
+// cancellationError replaces server errors caused by the request being
+// cancelled, or running past its deadline, with the matching status.
+func cancellationError(ctx context.Context, statusCode int, err error) (int, any) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, &Error{Code: CodeTimeout, Message: "request timed out", Err: err}
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		return StatusClientClosedRequest, &Error{Code: CodeRequestCancelled, Message: "request cancelled", Err: err}
 	}
-	
+
+	return statusCode, err
+}
+
+// RespondContent sends data as is, for representations other than JSON such
+// as rendered documents.
+func RespondContent(ctx context.Context, w http.ResponseWriter, statusCode int, contentType string, data []byte) error {
+	SetStatusCode(ctx, statusCode)
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RespondJSON sends data encoded as JSON without the payload envelope, for
+// documents whose format is defined elsewhere, like the OpenAPI document.
+func RespondJSON(ctx context.Context, w http.ResponseWriter, statusCode int, data any) error {
+	SetStatusCode(ctx, statusCode)
+
+	jsonData, err := json.Marshal(data)
 	if err != nil {
-		w.Write([]byte(`{"error": "failed to encode error response"}`))
 		return err
 	}
-	
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
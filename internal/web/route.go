@@ -0,0 +1,48 @@
+package web
+
+// Set of security schemes a route can require in its Doc.
+const (
+	SecurityBearer = "bearer"
+	SecurityBasic  = "basic"
+)
+
+// Doc describes a route for the generated API documentation. Request and
+// Response hold a zero value of the app layer models exchanged by the route,
+// their json and validate tags make up the schemas.
+type Doc struct {
+	Summary  string
+	Tags     []string
+	Security string
+	Query    []string
+	Request  any
+	Response any
+
+	// Upload is the name of the file field of routes taking a
+	// multipart/form-data body instead of a JSON Request.
+	Upload string
+
+	// Status is the status code of a successful response, 200 by default.
+	Status int
+}
+
+// Route represents a route registered on the App.
+type Route struct {
+	Method string
+	Path   string
+	Doc    *Doc
+}
+
+// Describe attaches the documentation of the route.
+func (r *Route) Describe(doc Doc) *Route {
+	r.Doc = &doc
+	return r
+}
+
+// Routes returns the routes registered on the App in registration order.
+func (a *App) Routes() []Route {
+	routes := make([]Route, len(a.routes))
+	for i, r := range a.routes {
+		routes[i] = *r
+	}
+	return routes
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer used when a request did not go through an App.
+const tracerName = "github.com/hpetrov29/resttemplate/internal/web"
+
+// AddSpan adds an OpenTelemetry span to the trace of the request and returns
+// the context holding it. The caller has to end the span.
+//
+// Parameters:
+//   - ctx: the context of the request.
+//   - spanName: the name of the new span.
+//   - keyValues: attributes recorded on the span.
+//
+// Returns:
+//   - context.Context: the context holding the new span.
+//   - trace.Span: the new span.
+func AddSpan(ctx context.Context, spanName string, keyValues ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := otel.Tracer(tracerName)
+	if v, ok := ctx.Value(key).(*Values); ok && v.Tracer != nil {
+		tracer = v.Tracer
+	}
+
+	ctx, span := tracer.Start(ctx, spanName)
+	span.SetAttributes(keyValues...)
+
+	return ctx, span
+}
+
+// InjectTraceContext writes the trace context held by ctx into header using
+// the global propagator, so the next service can continue the trace.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractTraceContext returns a copy of ctx holding the trace context found in
+// header.
+func ExtractTraceContext(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
@@ -2,15 +2,19 @@ package web
 
 import (
 	"context"
-	"errors"
 	"net/http"
 	"os"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
 	"github.com/hpetrov29/resttemplate/internal/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // Handler is a type definition that handles a http request within the mini framework.
@@ -25,12 +29,17 @@ type App struct {
 	middlewares []Middleware
 	Log *logger.Logger
 	Version string
+	tracer trace.Tracer
+	routes []*Route
 }
 
-// NewApp creates an App instance using the chi router.
-func NewApp(shutdown chan os.Signal, log *logger.Logger, middlewares ...Middleware) *App {
+// NewApp creates an App instance using the chi router. Every request handled
+// by the App is traced with tracer, a nil tracer disables tracing.
+func NewApp(shutdown chan os.Signal, log *logger.Logger, tracer trace.Tracer, middlewares ...Middleware) *App {
+	if tracer == nil {
+		tracer = noop.NewTracerProvider().Tracer("")
+	}
 
-	// TO DO: Create an OpenTelemetry HTTP Handler which wraps our router.
 	mux := chi.NewMux();
 
 	return &App{
@@ -39,6 +48,7 @@ func NewApp(shutdown chan os.Signal, log *logger.Logger, middlewares ...Middlewa
 		middlewares: middlewares,
 		Log: log,
 		Version: "v1",
+		tracer: tracer,
 	}
 }
 
@@ -62,79 +72,100 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // 	- path: the pattern to be matched against.
 //	- handler: the handler function to be executed.
 //	- middlewares: middlewares that run before the handler gets executed.
-func (a *App) Handle(method string, path string, handler Handler, middlewares ...Middleware) {
+//
+// Returns:
+//	- *Route: the registered route, used to attach its documentation.
+func (a *App) Handle(method string, path string, handler Handler, middlewares ...Middleware) *Route {
 	handler = wrapMiddleware(middlewares, handler)
 	handler = wrapMiddleware(a.middlewares, handler)
 
 	group := a.Version;
-	a.handle(method, group, path, handler)
+	finalPath := a.handle(method, group, path, handler)
+
+	route := &Route{Method: method, Path: finalPath}
+	a.routes = append(a.routes, route)
+
+	return route
 }
 
 // =============================================================================
 
 // handle sets a handler function for a given HTTP method and path pair
-// to the application server mux.
-func (a *App) handle(method string, group string, path string, handler Handler) {
+// to the application server mux and returns the full path of the route.
+func (a *App) handle(method string, group string, path string, handler Handler) string {
+	finalPath := path
+	if group != "" {
+		finalPath = "/" + group + path
+	}
+
 	h := func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := a.startSpan(w, r, finalPath)
+		defer span.End()
+
+		// The trace id of the span is used when the request is traced,
+		// requests are still told apart in the logs otherwise.
+		traceId := span.SpanContext().TraceID()
 		vals := &Values{
-			TraceId:    uuid.New().String(),
+			TraceId:    traceId.String(),
 			Now:        time.Now(),
 			StatusCode: 0,
+			Route:      finalPath,
+			Tracer:     a.tracer,
+			Path:       r.URL.Path,
+			Problem:    acceptsProblem(r),
 		}
-		ctx := context.WithValue(context.Background(), key, vals)
+		if !traceId.IsValid() {
+			vals.TraceId = uuid.New().String()
+		}
+		ctx = context.WithValue(ctx, key, vals)
 
-		if err := handler(ctx, w, r); err != nil {
-			if validateShutdown(err) {
-				a.SignalShutdown()
-				return
-			}
+		err := handler(ctx, w, r)
+
+		span.SetAttributes(attribute.Int("http.status_code", vals.StatusCode))
+		if vals.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(vals.StatusCode))
 		}
-	}
 
-	finalPath := path
-	if group != "" {
-		finalPath = "/" + group + path
+		if err != nil {
+			span.RecordError(err)
+
+			// Errors reaching this point were not handled by an error
+			// middleware, at least leave a trace of them.
+			if a.Log != nil {
+				a.Log.Error(ctx, "unhandled error", "method", r.Method, "path", r.URL.Path, "msg", err)
+			}
+		}
 	}
 
 	a.mux.MethodFunc(method, finalPath, h)
+
+	return finalPath
 }
 
-// validateShutdown validates the error for special conditions that do not
-// warrant an actual shutdown by the system.
-func validateShutdown(err error) bool {
-
-	// Ignore syscall.EPIPE and syscall.ECONNRESET errors which occurs
-	// when a write operation happens on the http.ResponseWriter that
-	// has simultaneously been disconnected by the client (TCP
-	// connections is broken). For instance, when large amounts of
-	// data is being written or streamed to the client.
-	// https://blog.cloudflare.com/the-complete-guide-to-golang-net-http-timeouts/
-	// https://gosamples.dev/broken-pipe/
-	// https://gosamples.dev/connection-reset-by-peer/
-
-	switch {
-	case errors.Is(err, syscall.EPIPE):
-
-		// Usually, you get the broken pipe error when you write to the connection after the
-		// RST (TCP RST Flag) is sent.
-		// The broken pipe is a TCP/IP error occurring when you write to a stream where the
-		// other end (the peer) has closed the underlying connection. The first write to the
-		// closed connection causes the peer to reply with an RST packet indicating that the
-		// connection should be terminated immediately. The second write to the socket that
-		// has already received the RST causes the broken pipe error.
-		return false
-
-	case errors.Is(err, syscall.ECONNRESET):
-
-		// Usually, you get connection reset by peer error when you read from the
-		// connection after the RST (TCP RST Flag) is sent.
-		// The connection reset by peer is a TCP/IP error that occurs when the other end (peer)
-		// has unexpectedly closed the connection. It happens when you send a packet from your
-		// end, but the other end crashes and forcibly closes the connection with the RST
-		// packet instead of the TCP FIN, which is used to close a connection under normal
-		// circumstances.
-		return false
-	}
+// acceptsProblem reports whether the client asked for errors formatted as
+// RFC 7807 problem details.
+func acceptsProblem(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), ProblemContentType)
+}
 
-	return true
+// startSpan continues the trace found in the headers of the request, or
+// starts a new one, and sends the resulting trace context back in the
+// headers of the response. The context of the request is the parent of the
+// returned one, so a client disconnect or a server timeout cancels the work
+// done for the request.
+func (a *App) startSpan(w http.ResponseWriter, r *http.Request, route string) (context.Context, trace.Span) {
+	ctx := ExtractTraceContext(r.Context(), r.Header)
+
+	ctx, span := a.tracer.Start(ctx, route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.method", r.Method),
+			attribute.String("http.route", route),
+			attribute.String("http.target", r.URL.Path),
+		),
+	)
+
+	InjectTraceContext(ctx, w.Header())
+
+	return ctx, span
 }
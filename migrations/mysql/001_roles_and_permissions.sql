@@ -0,0 +1,40 @@
+-- Moves role definitions into the database and normalises the roles stored
+-- on existing users. Safe to run more than once.
+
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) NOT NULL PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT "",
+    permissions JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+INSERT IGNORE INTO roles (name, description, permissions) VALUES
+    ("ADMIN", "Full access to the system", JSON_ARRAY(
+        "post:create", "post:edit:any", "post:delete:any",
+        "comment:create", "comment:delete:any", "comment:moderate",
+        "like:create", "user:read:any", "user:manage", "role:manage")),
+    ("USER", "Regular author and reader", JSON_ARRAY(
+        "post:create", "comment:create", "like:create")),
+    ("EDITOR", "Curates content written by others", JSON_ARRAY(
+        "post:create", "post:edit:any", "post:delete:any",
+        "comment:create", "like:create")),
+    ("MODERATOR", "Keeps the discussions civil", JSON_ARRAY(
+        "post:create", "comment:create", "comment:delete:any", "comment:moderate",
+        "like:create", "user:read:any"));
+
+-- Users written before roles were stored as JSON arrays hold an empty object,
+-- they become regular users.
+UPDATE users
+SET roles = JSON_ARRAY("USER")
+WHERE JSON_TYPE(roles) <> "ARRAY" OR JSON_LENGTH(roles) = 0;
+
+-- Role names are upper case.
+UPDATE users
+SET roles = CAST(UPPER(CAST(roles AS CHAR)) AS JSON);
+
+-- Keep every role that is still assigned to a user defined, without granting
+-- it anything until an administrator decides otherwise.
+INSERT IGNORE INTO roles (name, description, permissions)
+SELECT DISTINCT jt.name, "Migrated from existing user roles", JSON_ARRAY()
+FROM users, JSON_TABLE(users.roles, "$[*]" COLUMNS (name VARCHAR(50) PATH "$")) AS jt;
@@ -0,0 +1,21 @@
+-- Adds the status lifecycle of posts: draft, scheduled, published and
+-- archived. Existing posts were public from the moment they were created,
+-- they become published as of their creation. Safe to run more than once.
+
+SET @add_status = (
+    SELECT IF(COUNT(*) = 0,
+        'ALTER TABLE posts
+            ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT "published" AFTER content_id,
+            ADD COLUMN publish_at TIMESTAMP NULL DEFAULT NULL AFTER status,
+            ADD INDEX status (status, publish_at)',
+        'DO 0')
+    FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = "posts" AND column_name = "status"
+);
+PREPARE add_status FROM @add_status;
+EXECUTE add_status;
+DEALLOCATE PREPARE add_status;
+
+UPDATE posts
+SET publish_at = created_at
+WHERE status = "published" AND publish_at IS NULL;
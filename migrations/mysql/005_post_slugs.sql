@@ -0,0 +1,24 @@
+-- Adds slugs to posts. The current slug of a post is kept on the post, every
+-- slug a post ever had is reserved in post_slugs so the previous ones keep
+-- leading to it. Existing posts get a slug the next time they are edited.
+-- Safe to run more than once.
+
+SET @add_slug = (
+    SELECT IF(COUNT(*) = 0,
+        'ALTER TABLE posts
+            ADD COLUMN slug VARCHAR(100) NULL DEFAULT NULL AFTER title,
+            ADD UNIQUE INDEX slug (slug)',
+        'DO 0')
+    FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = "posts" AND column_name = "slug"
+);
+PREPARE add_slug FROM @add_slug;
+EXECUTE add_slug;
+DEALLOCATE PREPARE add_slug;
+
+CREATE TABLE IF NOT EXISTS post_slugs (
+    slug VARCHAR(100) NOT NULL PRIMARY KEY,
+    post_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX (post_id)
+);
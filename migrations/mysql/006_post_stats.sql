@@ -0,0 +1,19 @@
+-- Adds the statistics computed from the content of posts, so listings show
+-- them without loading the content. Existing posts get them the next time
+-- they are edited. Safe to run more than once.
+
+SET @add_stats = (
+    SELECT IF(COUNT(*) = 0,
+        'ALTER TABLE posts
+            ADD COLUMN word_count INT UNSIGNED NOT NULL DEFAULT 0 AFTER content_id,
+            ADD COLUMN reading_time SMALLINT UNSIGNED NOT NULL DEFAULT 0 AFTER word_count,
+            ADD COLUMN image_count SMALLINT UNSIGNED NOT NULL DEFAULT 0 AFTER reading_time,
+            ADD COLUMN language VARCHAR(8) NOT NULL DEFAULT "" AFTER image_count,
+            ADD INDEX reading_time (reading_time)',
+        'DO 0')
+    FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = "posts" AND column_name = "reading_time"
+);
+PREPARE add_stats FROM @add_stats;
+EXECUTE add_stats;
+DEALLOCATE PREPARE add_stats;
@@ -0,0 +1,19 @@
+-- Adds the enabled state of users, read on every authenticated request.
+-- Existing users stay enabled. Safe to run more than once.
+--
+-- The column came with the admin user management API, before any of the
+-- other migrations, but only init_scripts/mysql_schema.sql created it, so
+-- logins failed on databases created earlier until this ran. It is numbered
+-- last rather than first because migrations are applied in order and
+-- databases already past 001-006 would never run one inserted before them.
+
+SET @add_enabled = (
+    SELECT IF(COUNT(*) = 0,
+        'ALTER TABLE users ADD COLUMN enabled BOOLEAN NOT NULL DEFAULT TRUE AFTER password_hash',
+        'DO 0')
+    FROM information_schema.columns
+    WHERE table_schema = DATABASE() AND table_name = "users" AND column_name = "enabled"
+);
+PREPARE add_enabled FROM @add_enabled;
+EXECUTE add_enabled;
+DEALLOCATE PREPARE add_enabled;